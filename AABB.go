@@ -0,0 +1,297 @@
+package golem
+
+import "math"
+
+type AABB3D struct {
+	Min, Max Vec3D
+}
+
+func NewAABB3D(center, halfExtents Vec3D) AABB3D {
+	return AABB3D{
+		Min: center.SubVec(halfExtents),
+		Max: center.AddVec(halfExtents),
+	}
+}
+
+func NewAABB3DFromPoints(points []Vec3D) (AABB3D, error) {
+	if len(points) == 0 {
+		return AABB3D{}, ErrInvalidLen
+	}
+
+	b := AABB3D{Min: points[0], Max: points[0]}
+	for _, p := range points[1:] {
+		b.AddPoint(p)
+	}
+
+	return b, nil
+}
+
+func (b *AABB3D) AddPoint(p Vec3D) {
+	b.Min.X = math.Min(b.Min.X, p.X)
+	b.Min.Y = math.Min(b.Min.Y, p.Y)
+	b.Min.Z = math.Min(b.Min.Z, p.Z)
+
+	b.Max.X = math.Max(b.Max.X, p.X)
+	b.Max.Y = math.Max(b.Max.Y, p.Y)
+	b.Max.Z = math.Max(b.Max.Z, p.Z)
+}
+
+func (b AABB3D) Center() Vec3D {
+	return Vec3D{
+		X: (b.Min.X + b.Max.X) / 2,
+		Y: (b.Min.Y + b.Max.Y) / 2,
+		Z: (b.Min.Z + b.Max.Z) / 2,
+	}
+}
+
+// half of the size along each axis
+func (b AABB3D) Extents() Vec3D {
+	return Vec3D{
+		X: (b.Max.X - b.Min.X) / 2,
+		Y: (b.Max.Y - b.Min.Y) / 2,
+		Z: (b.Max.Z - b.Min.Z) / 2,
+	}
+}
+
+func (b AABB3D) Size() Vec3D {
+	return b.Max.SubVec(b.Min)
+}
+
+// corner i takes the max along axis k when bit k of i is set
+func (b AABB3D) Corners() [8]Vec3D {
+	var out [8]Vec3D
+	for i := range out {
+		out[i] = b.Min
+		if i&1 != 0 {
+			out[i].X = b.Max.X
+		}
+		if i&2 != 0 {
+			out[i].Y = b.Max.Y
+		}
+		if i&4 != 0 {
+			out[i].Z = b.Max.Z
+		}
+	}
+
+	return out
+}
+
+func (b AABB3D) SurfaceArea() float64 {
+	s := b.Size()
+	return 2 * ((s.X * s.Y) + (s.Y * s.Z) + (s.Z * s.X))
+}
+
+func (b AABB3D) Volume() float64 {
+	s := b.Size()
+	return s.X * s.Y * s.Z
+}
+
+func (b AABB3D) Union(o AABB3D) AABB3D {
+	b.AddPoint(o.Min)
+	b.AddPoint(o.Max)
+
+	return b
+}
+
+// grows the box by margin on every side
+func (b AABB3D) Expand(margin float64) AABB3D {
+	m := Vec3D{X: margin, Y: margin, Z: margin}
+
+	return AABB3D{
+		Min: b.Min.SubVec(m),
+		Max: b.Max.AddVec(m),
+	}
+}
+
+func (b AABB3D) ContainsPoint(p Vec3D) bool {
+	return p.X >= b.Min.X && p.X <= b.Max.X &&
+		p.Y >= b.Min.Y && p.Y <= b.Max.Y &&
+		p.Z >= b.Min.Z && p.Z <= b.Max.Z
+}
+
+func (b AABB3D) Contains(o AABB3D) bool {
+	return b.ContainsPoint(o.Min) && b.ContainsPoint(o.Max)
+}
+
+// touching boxes intersect
+func (b AABB3D) Intersects(o AABB3D) bool {
+	return b.Min.X <= o.Max.X && b.Max.X >= o.Min.X &&
+		b.Min.Y <= o.Max.Y && b.Max.Y >= o.Min.Y &&
+		b.Min.Z <= o.Max.Z && b.Max.Z >= o.Min.Z
+}
+
+func (b AABB3D) ClosestPoint(p Vec3D) Vec3D {
+	return Vec3D{
+		X: Clamp(p.X, b.Min.X, b.Max.X),
+		Y: Clamp(p.Y, b.Min.Y, b.Max.Y),
+		Z: Clamp(p.Z, b.Min.Z, b.Max.Z),
+	}
+}
+
+func (b AABB3D) IntersectsSphere(s Sphere) bool {
+	c := b.ClosestPoint(s.Center)
+	return c.Dist(s.Center) <= s.Radius
+}
+
+// Slab method, returns the entry and exit params along the ray. The entry is
+// negative when the origin is inside the box
+func (b AABB3D) IntersectRay(r Ray3D) (float64, float64, bool) {
+	tMin := math.Inf(-1)
+	tMax := math.Inf(1)
+
+	origin := [3]float64{r.Origin.X, r.Origin.Y, r.Origin.Z}
+	dir := [3]float64{r.Dir.X, r.Dir.Y, r.Dir.Z}
+	min := [3]float64{b.Min.X, b.Min.Y, b.Min.Z}
+	max := [3]float64{b.Max.X, b.Max.Y, b.Max.Z}
+
+	for i := 0; i < 3; i++ {
+		if dir[i] == 0 {
+			if origin[i] < min[i] || origin[i] > max[i] {
+				return 0, 0, false
+			}
+			continue
+		}
+
+		inv := 1 / dir[i]
+		t0 := (min[i] - origin[i]) * inv
+		t1 := (max[i] - origin[i]) * inv
+
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+
+		tMin = math.Max(tMin, t0)
+		tMax = math.Min(tMax, t1)
+
+		if tMin > tMax {
+			return 0, 0, false
+		}
+	}
+
+	if tMax < 0 {
+		return 0, 0, false
+	}
+
+	return tMin, tMax, true
+}
+
+func (b AABB3D) Support(dir Vec3D) Vec3D {
+	out := b.Min
+
+	if dir.X > 0 {
+		out.X = b.Max.X
+	}
+	if dir.Y > 0 {
+		out.Y = b.Max.Y
+	}
+	if dir.Z > 0 {
+		out.Z = b.Max.Z
+	}
+
+	return out
+}
+
+type AABB2D struct {
+	Min, Max Vec2D
+}
+
+func NewAABB2D(center, halfExtents Vec2D) AABB2D {
+	return AABB2D{
+		Min: center.SubVec(halfExtents),
+		Max: center.AddVec(halfExtents),
+	}
+}
+
+func NewAABB2DFromPoints(points []Vec2D) (AABB2D, error) {
+	if len(points) == 0 {
+		return AABB2D{}, ErrInvalidLen
+	}
+
+	b := AABB2D{Min: points[0], Max: points[0]}
+	for _, p := range points[1:] {
+		b.AddPoint(p)
+	}
+
+	return b, nil
+}
+
+func (b *AABB2D) AddPoint(p Vec2D) {
+	b.Min.X = math.Min(b.Min.X, p.X)
+	b.Min.Y = math.Min(b.Min.Y, p.Y)
+
+	b.Max.X = math.Max(b.Max.X, p.X)
+	b.Max.Y = math.Max(b.Max.Y, p.Y)
+}
+
+func (b AABB2D) Center() Vec2D {
+	return Vec2D{
+		X: (b.Min.X + b.Max.X) / 2,
+		Y: (b.Min.Y + b.Max.Y) / 2,
+	}
+}
+
+// half of the size along each axis
+func (b AABB2D) Extents() Vec2D {
+	return Vec2D{
+		X: (b.Max.X - b.Min.X) / 2,
+		Y: (b.Max.Y - b.Min.Y) / 2,
+	}
+}
+
+func (b AABB2D) Size() Vec2D {
+	return b.Max.SubVec(b.Min)
+}
+
+func (b AABB2D) Area() float64 {
+	s := b.Size()
+	return s.X * s.Y
+}
+
+func (b AABB2D) Perimeter() float64 {
+	s := b.Size()
+	return 2 * (s.X + s.Y)
+}
+
+func (b AABB2D) Union(o AABB2D) AABB2D {
+	b.AddPoint(o.Min)
+	b.AddPoint(o.Max)
+
+	return b
+}
+
+// grows the box by margin on every side
+func (b AABB2D) Expand(margin float64) AABB2D {
+	m := Vec2D{X: margin, Y: margin}
+
+	return AABB2D{
+		Min: b.Min.SubVec(m),
+		Max: b.Max.AddVec(m),
+	}
+}
+
+func (b AABB2D) ContainsPoint(p Vec2D) bool {
+	return p.X >= b.Min.X && p.X <= b.Max.X &&
+		p.Y >= b.Min.Y && p.Y <= b.Max.Y
+}
+
+func (b AABB2D) Contains(o AABB2D) bool {
+	return b.ContainsPoint(o.Min) && b.ContainsPoint(o.Max)
+}
+
+// touching boxes intersect
+func (b AABB2D) Intersects(o AABB2D) bool {
+	return b.Min.X <= o.Max.X && b.Max.X >= o.Min.X &&
+		b.Min.Y <= o.Max.Y && b.Max.Y >= o.Min.Y
+}
+
+func (b AABB2D) ClosestPoint(p Vec2D) Vec2D {
+	return Vec2D{
+		X: Clamp(p.X, b.Min.X, b.Max.X),
+		Y: Clamp(p.Y, b.Min.Y, b.Max.Y),
+	}
+}
+
+func (b AABB2D) IntersectsCircle(c Circle) bool {
+	p := b.ClosestPoint(c.Center)
+	return p.Dist(c.Center) <= c.Radius
+}
@@ -0,0 +1,432 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// How a key blends into the next one. Slerp and Squad only differ for
+// quaternion curves, the other curves treat them as Linear and Cubic
+type Interpolation int
+
+const (
+	InterpolateStep Interpolation = iota
+	InterpolateLinear
+	InterpolateCubic
+	InterpolateSlerp
+	InterpolateSquad
+)
+
+// How a curve continues before its first and after its last key. Offset
+// loops and shifts every cycle by the change over one cycle
+type InfinityMode int
+
+const (
+	InfinityClamp InfinityMode = iota
+	InfinityLoop
+	InfinityPingPong
+	InfinityOffset
+)
+
+// Tangents are slopes in value per second. Weights are the lengths of the
+// tangent handles as a fraction of the segment, 0 is the unweighted 1/3
+type Keyframe struct {
+	Time          float64
+	Value         float64
+	InTangent     float64
+	OutTangent    float64
+	InWeight      float64
+	OutWeight     float64
+	Interpolation Interpolation
+}
+
+// Keys stay sorted by time, Evaluate remembers the last segment so
+// sampling forwards in time is cheap. Not safe for concurrent use
+type AnimationCurve struct {
+	Keys         []Keyframe
+	PreInfinity  InfinityMode
+	PostInfinity InfinityMode
+
+	last int
+}
+
+// sorts a copy of the keys, times must be distinct
+func NewAnimationCurve(keys []Keyframe) (*AnimationCurve, error) {
+	if len(keys) == 0 {
+		return nil, ErrInvalidLen
+	}
+
+	c := &AnimationCurve{Keys: append([]Keyframe(nil), keys...)}
+	sort.SliceStable(c.Keys, func(i, j int) bool {
+		return c.Keys[i].Time < c.Keys[j].Time
+	})
+
+	for i := 1; i < len(c.Keys); i++ {
+		if c.Keys[i].Time == c.Keys[i-1].Time {
+			return nil, ErrDuplicateKeyTime
+		}
+	}
+
+	return c, nil
+}
+
+func (c *AnimationCurve) keyTime(i int) float64 { return c.Keys[i].Time }
+func (c *AnimationCurve) keyCount() int         { return len(c.Keys) }
+
+func (c *AnimationCurve) Range() (float64, float64) {
+	return c.Keys[0].Time, c.Keys[len(c.Keys)-1].Time
+}
+
+func (c *AnimationCurve) Evaluate(t float64) float64 {
+	first, last := c.Keys[0], c.Keys[len(c.Keys)-1]
+	if len(c.Keys) == 1 {
+		return first.Value
+	}
+
+	t, cycle := curveWrap(t, first.Time, last.Time, c.PreInfinity, c.PostInfinity)
+	i := keySegment(c, &c.last, t)
+
+	a, b := c.Keys[i], c.Keys[i+1]
+	v := a.Value
+
+	switch a.Interpolation {
+	case InterpolateStep:
+
+	case InterpolateLinear, InterpolateSlerp:
+		v += (b.Value - a.Value) * (t - a.Time) / (b.Time - a.Time)
+
+	default:
+		dt := b.Time - a.Time
+		wo, wi := keyWeight(a.OutWeight), keyWeight(b.InWeight)
+		s := keyBezierParam(wo, wi, (t-a.Time)/dt)
+
+		v = bezier1D(a.Value, a.Value+wo*dt*a.OutTangent, b.Value-wi*dt*b.InTangent, b.Value, s)
+	}
+
+	return v + float64(cycle)*(last.Value-first.Value)
+}
+
+// Sets every tangent to the slope between the neighbouring keys, the end
+// keys take the slope of their only segment
+func (c *AnimationCurve) SmoothTangents() {
+	n := len(c.Keys)
+	for i := range c.Keys {
+		a, b := c.Keys[max(i-1, 0)], c.Keys[min(i+1, n-1)]
+		if a.Time == b.Time {
+			continue
+		}
+
+		m := (b.Value - a.Value) / (b.Time - a.Time)
+		c.Keys[i].InTangent, c.Keys[i].OutTangent = m, m
+	}
+}
+
+type Keyframe3D struct {
+	Time          float64
+	Value         Vec3D
+	InTangent     Vec3D
+	OutTangent    Vec3D
+	InWeight      float64
+	OutWeight     float64
+	Interpolation Interpolation
+}
+
+type AnimationCurve3D struct {
+	Keys         []Keyframe3D
+	PreInfinity  InfinityMode
+	PostInfinity InfinityMode
+
+	last int
+}
+
+func NewAnimationCurve3D(keys []Keyframe3D) (*AnimationCurve3D, error) {
+	if len(keys) == 0 {
+		return nil, ErrInvalidLen
+	}
+
+	c := &AnimationCurve3D{Keys: append([]Keyframe3D(nil), keys...)}
+	sort.SliceStable(c.Keys, func(i, j int) bool {
+		return c.Keys[i].Time < c.Keys[j].Time
+	})
+
+	for i := 1; i < len(c.Keys); i++ {
+		if c.Keys[i].Time == c.Keys[i-1].Time {
+			return nil, ErrDuplicateKeyTime
+		}
+	}
+
+	return c, nil
+}
+
+func (c *AnimationCurve3D) keyTime(i int) float64 { return c.Keys[i].Time }
+func (c *AnimationCurve3D) keyCount() int         { return len(c.Keys) }
+
+func (c *AnimationCurve3D) Range() (float64, float64) {
+	return c.Keys[0].Time, c.Keys[len(c.Keys)-1].Time
+}
+
+func (c *AnimationCurve3D) Evaluate(t float64) Vec3D {
+	first, last := c.Keys[0], c.Keys[len(c.Keys)-1]
+	if len(c.Keys) == 1 {
+		return first.Value
+	}
+
+	t, cycle := curveWrap(t, first.Time, last.Time, c.PreInfinity, c.PostInfinity)
+	i := keySegment(c, &c.last, t)
+
+	a, b := c.Keys[i], c.Keys[i+1]
+	v := a.Value
+
+	switch a.Interpolation {
+	case InterpolateStep:
+
+	case InterpolateLinear, InterpolateSlerp:
+		v = v.AddVec(b.Value.SubVec(a.Value).ScalerMulVec((t - a.Time) / (b.Time - a.Time)))
+
+	default:
+		dt := b.Time - a.Time
+		wo, wi := keyWeight(a.OutWeight), keyWeight(b.InWeight)
+
+		bz := CubicBezier3D{
+			P0: a.Value,
+			P1: a.Value.AddVec(a.OutTangent.ScalerMulVec(wo * dt)),
+			P2: b.Value.SubVec(b.InTangent.ScalerMulVec(wi * dt)),
+			P3: b.Value,
+		}
+		v = bz.At(keyBezierParam(wo, wi, (t-a.Time)/dt))
+	}
+
+	return v.AddVec(last.Value.SubVec(first.Value).ScalerMulVec(float64(cycle)))
+}
+
+func (c *AnimationCurve3D) SmoothTangents() {
+	n := len(c.Keys)
+	for i := range c.Keys {
+		a, b := c.Keys[max(i-1, 0)], c.Keys[min(i+1, n-1)]
+		if a.Time == b.Time {
+			continue
+		}
+
+		m := b.Value.SubVec(a.Value).ScalerMulVec(1 / (b.Time - a.Time))
+		c.Keys[i].InTangent, c.Keys[i].OutTangent = m, m
+	}
+}
+
+// Linear is normalized lerp, Cubic and Squad are Squad
+type KeyframeQt struct {
+	Time          float64
+	Value         Quaternion
+	Interpolation Interpolation
+}
+
+// Call Update after editing Keys
+type AnimationCurveQt struct {
+	Keys         []KeyframeQt
+	PreInfinity  InfinityMode
+	PostInfinity InfinityMode
+
+	last  int
+	inner []Quaternion
+}
+
+func NewAnimationCurveQt(keys []KeyframeQt) (*AnimationCurveQt, error) {
+	if len(keys) == 0 {
+		return nil, ErrInvalidLen
+	}
+
+	c := &AnimationCurveQt{Keys: append([]KeyframeQt(nil), keys...)}
+	sort.SliceStable(c.Keys, func(i, j int) bool {
+		return c.Keys[i].Time < c.Keys[j].Time
+	})
+
+	for i := range c.Keys {
+		if i > 0 && c.Keys[i].Time == c.Keys[i-1].Time {
+			return nil, ErrDuplicateKeyTime
+		}
+		if _, err := c.Keys[i].Value.Normalize(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.Update()
+	return c, nil
+}
+
+// Puts neighbouring keys on the same hemisphere and recomputes the Squad
+// control points
+func (c *AnimationCurveQt) Update() {
+	n := len(c.Keys)
+	for i := 1; i < n; i++ {
+		if c.Keys[i].Value.Dot(c.Keys[i-1].Value) < 0 {
+			c.Keys[i].Value.Negate()
+		}
+	}
+
+	c.inner = make([]Quaternion, n)
+	for i := range c.Keys {
+		q := c.Keys[i].Value
+		if i == 0 || i == n-1 {
+			c.inner[i] = q
+			continue
+		}
+
+		inv := q.ConjugateQt()
+		next := qtLog(inv.MultiplyQt(c.Keys[i+1].Value))
+		prev := qtLog(inv.MultiplyQt(c.Keys[i-1].Value))
+
+		c.inner[i] = q.MultiplyQt(qtExp(next.AddQt(prev).ScaleByQt(-0.25)))
+	}
+}
+
+func (c *AnimationCurveQt) keyTime(i int) float64 { return c.Keys[i].Time }
+func (c *AnimationCurveQt) keyCount() int         { return len(c.Keys) }
+
+func (c *AnimationCurveQt) Range() (float64, float64) {
+	return c.Keys[0].Time, c.Keys[len(c.Keys)-1].Time
+}
+
+func (c *AnimationCurveQt) Evaluate(t float64) Quaternion {
+	first, last := c.Keys[0], c.Keys[len(c.Keys)-1]
+	if len(c.Keys) == 1 {
+		return first.Value
+	}
+
+	t, cycle := curveWrap(t, first.Time, last.Time, c.PreInfinity, c.PostInfinity)
+	i := keySegment(c, &c.last, t)
+
+	a, b := c.Keys[i], c.Keys[i+1]
+	u := Clamp((t-a.Time)/(b.Time-a.Time), 0, 1)
+	q := a.Value
+
+	switch a.Interpolation {
+	case InterpolateStep:
+
+	case InterpolateLinear:
+		q, _ = a.Value.LerpQt(b.Value, u)
+
+	case InterpolateSlerp:
+		q, _ = a.Value.SlerpQt(b.Value, u)
+
+	default:
+		q = squad(a.Value, b.Value, c.inner[i], c.inner[i+1], u)
+	}
+
+	if cycle == 0 {
+		return q
+	}
+
+	// rotation over one cycle raised to the number of cycles
+	delta := last.Value.MultiplyQt(first.Value.ConjugateQt())
+	turn := qtExp(qtLog(delta).ScaleByQt(float64(cycle)))
+
+	return turn.MultiplyQt(q)
+}
+
+func squad(a, b, sa, sb Quaternion, u float64) Quaternion {
+	outer, _ := a.SlerpQt(b, u)
+	inner, _ := sa.SlerpQt(sb, u)
+
+	q, _ := outer.SlerpQt(inner, 2*u*(1-u))
+	return q
+}
+
+// log of a unit quaternion, a pure quaternion of half the rotation angle
+func qtLog(q Quaternion) Quaternion {
+	s := math.Sqrt(q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+	if s < 1e-12 {
+		return Quaternion{}
+	}
+
+	f := math.Atan2(s, q.W) / s
+	return Quaternion{X: q.X * f, Y: q.Y * f, Z: q.Z * f}
+}
+
+func qtExp(q Quaternion) Quaternion {
+	a := math.Sqrt(q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+	if a < 1e-12 {
+		return Quaternion{W: 1, X: q.X, Y: q.Y, Z: q.Z}
+	}
+
+	f := math.Sin(a) / a
+	return Quaternion{W: math.Cos(a), X: q.X * f, Y: q.Y * f, Z: q.Z * f}
+}
+
+type keyedCurve interface {
+	keyTime(i int) float64
+	keyCount() int
+}
+
+// Index i of the segment with keys i and i+1 around t. Checks the cached
+// segment and the one after it before searching
+func keySegment(c keyedCurve, last *int, t float64) int {
+	n := c.keyCount()
+
+	for i := *last; i < min(*last+2, n-1); i++ {
+		if c.keyTime(i) <= t && t < c.keyTime(i+1) {
+			*last = i
+			return i
+		}
+	}
+
+	i := sort.Search(n, func(j int) bool {
+		return c.keyTime(j) > t
+	}) - 1
+
+	i = min(max(i, 0), n-2)
+	*last = i
+
+	return i
+}
+
+// maps t into [t0, t1] and returns the number of cycles to offset by
+func curveWrap(t, t0, t1 float64, pre, post InfinityMode) (float64, int) {
+	mode := post
+	if t < t0 {
+		mode = pre
+	} else if t <= t1 {
+		return t, 0
+	}
+
+	span := t1 - t0
+	k := math.Floor((t - t0) / span)
+	local := t - t0 - k*span
+
+	switch mode {
+	case InfinityLoop:
+		return t0 + local, 0
+
+	case InfinityPingPong:
+		if int(k)%2 != 0 {
+			local = span - local
+		}
+		return t0 + local, 0
+
+	case InfinityOffset:
+		return t0 + local, int(k)
+	}
+
+	return Clamp(t, t0, t1), 0
+}
+
+func keyWeight(w float64) float64 {
+	if w <= 0 {
+		return 1.0 / 3
+	}
+
+	return math.Min(w, 1)
+}
+
+// param along the weighted segment at normalized time u, the time runs
+// linearly in u only without weights
+func keyBezierParam(wo, wi, u float64) float64 {
+	if wo == 1.0/3 && wi == 1.0/3 {
+		return u
+	}
+
+	return bezierParamAtX(CubicBezier2D{P1: Vec2D{X: wo}, P2: Vec2D{X: 1 - wi}, P3: Vec2D{X: 1}}, u)
+}
+
+func bezier1D(p0, p1, p2, p3, s float64) float64 {
+	r := 1 - s
+	return r*r*r*p0 + 3*r*r*s*p1 + 3*r*s*s*p2 + s*s*s*p3
+}
@@ -0,0 +1,185 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// Breakpoints of a curve with the arc length from the start of the domain
+// to each of them. Lookups between breakpoints integrate the speed of the
+// curve so they stay accurate for coarse tables
+type ArcLengthTable struct {
+	Params  []float64
+	Lengths []float64
+
+	speed func(float64) float64
+}
+
+const arcMaxDepth = 24
+
+// 5 point Gauss-Legendre on [-1, 1]
+var (
+	gaussNodes   = [5]float64{-0.9061798459386640, -0.5384693101056831, 0, 0.5384693101056831, 0.9061798459386640}
+	gaussWeights = [5]float64{0.2369268850561891, 0.4786286704993665, 0.5688888888888889, 0.4786286704993665, 0.2369268850561891}
+)
+
+// Splits the domain until the length of every piece agrees with the sum of
+// its halves within tolerance, a tolerance <= 0 uses 1e-9
+func NewArcLengthTable2D(c Curve2D, tolerance float64) *ArcLengthTable {
+	lo, hi := c.Domain()
+
+	return newArcLengthTable(lo, hi, tolerance, func(t float64) float64 {
+		d := c.Derivative(t)
+		return d.Length()
+	})
+}
+
+func NewArcLengthTable3D(c Curve3D, tolerance float64) *ArcLengthTable {
+	lo, hi := c.Domain()
+
+	return newArcLengthTable(lo, hi, tolerance, func(t float64) float64 {
+		d := c.Derivative(t)
+		return d.Length()
+	})
+}
+
+func newArcLengthTable(lo, hi, tol float64, speed func(float64) float64) *ArcLengthTable {
+	if tol <= 0 {
+		tol = 1e-9
+	}
+
+	a := &ArcLengthTable{
+		Params:  []float64{lo},
+		Lengths: []float64{0},
+		speed:   speed,
+	}
+
+	// unit steps keep the joins of piecewise curves on breakpoints
+	steps := max(1, int(math.Ceil(hi-lo)))
+	for i := 0; i < steps; i++ {
+		t0 := lo + (hi-lo)*float64(i)/float64(steps)
+		t1 := lo + (hi-lo)*float64(i+1)/float64(steps)
+
+		a.subdivide(t0, t1, a.integrate(t0, t1), tol/float64(steps), 0)
+	}
+
+	return a
+}
+
+func (a *ArcLengthTable) subdivide(t0, t1, whole, tol float64, depth int) {
+	mid := (t0 + t1) / 2
+	left := a.integrate(t0, mid)
+	right := a.integrate(mid, t1)
+
+	if depth >= arcMaxDepth || math.Abs(left+right-whole) <= tol {
+		last := a.Lengths[len(a.Lengths)-1]
+
+		a.Params = append(a.Params, mid, t1)
+		a.Lengths = append(a.Lengths, last+left, last+left+right)
+		return
+	}
+
+	a.subdivide(t0, mid, left, tol/2, depth+1)
+	a.subdivide(mid, t1, right, tol/2, depth+1)
+}
+
+func (a *ArcLengthTable) integrate(t0, t1 float64) float64 {
+	half := (t1 - t0) / 2
+	mid := (t0 + t1) / 2
+
+	sum := 0.0
+	for i, x := range gaussNodes {
+		sum += gaussWeights[i] * a.speed(mid+half*x)
+	}
+
+	return sum * half
+}
+
+func (a *ArcLengthTable) Length() float64 {
+	return a.Lengths[len(a.Lengths)-1]
+}
+
+// arc length from the start of the domain to t
+func (a *ArcLengthTable) LengthAt(t float64) float64 {
+	t = Clamp(t, a.Params[0], a.Params[len(a.Params)-1])
+
+	i := sort.SearchFloat64s(a.Params, t)
+	if i == 0 {
+		return 0
+	}
+
+	return a.Lengths[i-1] + a.integrate(a.Params[i-1], t)
+}
+
+// Param at arc length s from the start, s is clamped to the curve. Newton
+// steps on the integrated length starting from the linear guess
+func (a *ArcLengthTable) ParamAt(s float64) float64 {
+	n := len(a.Lengths)
+	if s <= 0 {
+		return a.Params[0]
+	}
+	if s >= a.Lengths[n-1] {
+		return a.Params[n-1]
+	}
+
+	i := sort.SearchFloat64s(a.Lengths, s)
+	if a.Lengths[i] == s {
+		return a.Params[i]
+	}
+
+	t0, t1 := a.Params[i-1], a.Params[i]
+	l0, l1 := a.Lengths[i-1], a.Lengths[i]
+
+	t := t0 + (t1-t0)*(s-l0)/(l1-l0)
+	for k := 0; k < 8; k++ {
+		v := a.speed(t)
+		if v == 0 {
+			break
+		}
+
+		next := Clamp(t-(l0+a.integrate(t0, t)-s)/v, t0, t1)
+		if math.Abs(next-t) < 1e-14 {
+			return next
+		}
+		t = next
+	}
+
+	return t
+}
+
+// count params spaced evenly along the curve including both ends
+func (a *ArcLengthTable) UniformParams(count int) []float64 {
+	if count < 2 {
+		return []float64{a.Params[0]}
+	}
+
+	out := make([]float64, count)
+	for i := range out {
+		out[i] = a.ParamAt(a.Length() * float64(i) / float64(count-1))
+	}
+
+	return out
+}
+
+// count points spaced evenly along the curve including both ends
+func ResampleCurve2D(c Curve2D, a *ArcLengthTable, count int) []Vec2D {
+	ts := a.UniformParams(count)
+
+	out := make([]Vec2D, len(ts))
+	for i, t := range ts {
+		out[i] = c.At(t)
+	}
+
+	return out
+}
+
+func ResampleCurve3D(c Curve3D, a *ArcLengthTable, count int) []Vec3D {
+	ts := a.UniformParams(count)
+
+	out := make([]Vec3D, len(ts))
+	for i, t := range ts {
+		out[i] = c.At(t)
+	}
+
+	return out
+}
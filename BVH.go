@@ -0,0 +1,683 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	bvhNull = -1
+	bvhBins = 12
+)
+
+// Dynamic AABB tree keyed by user ids, one object per leaf. Leaves store
+// the boxes fattened by Margin so small moves do not restructure the tree
+type BVH struct {
+	Margin float64
+
+	nodes  []bvhNode
+	root   int
+	free   int
+	leaves map[int]int
+}
+
+type bvhNode struct {
+	box    AABB3D
+	tight  AABB3D
+	parent int
+	left   int
+	right  int
+	height int
+	id     int
+}
+
+type BVHHit struct {
+	ID int
+	T  float64
+}
+
+// Narrow phase test for ray casts, returns the ray param of the hit.
+// A nil func uses the entry param of the object's box
+type BVHRayFunc func(id int, r Ray3D) (float64, bool)
+
+func (n *bvhNode) isLeaf() bool {
+	return n.left == bvhNull
+}
+
+func NewBVH(margin float64) *BVH {
+	return &BVH{
+		Margin: margin,
+		root:   bvhNull,
+		free:   bvhNull,
+		leaves: make(map[int]int),
+	}
+}
+
+// Top down build using the binned surface area heuristic
+func BuildBVH(ids []int, boxes []AABB3D, margin float64) (*BVH, error) {
+	if len(ids) != len(boxes) {
+		return nil, ErrInvalidLen
+	}
+
+	b := NewBVH(margin)
+
+	leaves := make([]int, len(ids))
+	for i, id := range ids {
+		if _, ok := b.leaves[id]; ok {
+			return nil, ErrDuplicateID
+		}
+
+		n := b.allocate()
+		b.nodes[n].id = id
+		b.nodes[n].tight = boxes[i]
+		b.nodes[n].box = boxes[i].Expand(margin)
+
+		b.leaves[id] = n
+		leaves[i] = n
+	}
+
+	if len(leaves) > 0 {
+		b.root = b.build(leaves)
+		b.nodes[b.root].parent = bvhNull
+	}
+
+	return b, nil
+}
+
+// rebuilds the whole tree with the surface area heuristic, useful after
+// many incremental changes
+func (b *BVH) Rebuild() {
+	leaves := make([]int, 0, len(b.leaves))
+	for _, n := range b.leaves {
+		leaves = append(leaves, n)
+	}
+
+	// map order is random, sort so the rebuilt tree is the same every time
+	sort.Slice(leaves, func(i, j int) bool {
+		return b.nodes[leaves[i]].id < b.nodes[leaves[j]].id
+	})
+
+	for i := range b.nodes {
+		if b.nodes[i].height > 0 {
+			b.release(i)
+		}
+	}
+
+	b.root = bvhNull
+	if len(leaves) > 0 {
+		b.root = b.build(leaves)
+		b.nodes[b.root].parent = bvhNull
+	}
+}
+
+func (b *BVH) build(leaves []int) int {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+
+	bounds := b.nodes[leaves[0]].box
+	cb, _ := NewAABB3DFromPoints([]Vec3D{b.nodes[leaves[0]].box.Center()})
+	for _, l := range leaves[1:] {
+		bounds = bounds.Union(b.nodes[l].box)
+		cb.AddPoint(b.nodes[l].box.Center())
+	}
+
+	size := cb.Size()
+	axis := 0
+	if size.Y > size.X && size.Y >= size.Z {
+		axis = 1
+	} else if size.Z > size.X && size.Z > size.Y {
+		axis = 2
+	}
+
+	lo := vecAxis(cb.Min, axis)
+	extent := vecAxis(cb.Max, axis) - lo
+
+	split := len(leaves) / 2
+
+	if extent > 0 {
+		var counts [bvhBins]int
+		var binBoxes [bvhBins]AABB3D
+
+		binOf := func(l int) int {
+			k := int(bvhBins * (vecAxis(b.nodes[l].box.Center(), axis) - lo) / extent)
+			if k >= bvhBins {
+				k = bvhBins - 1
+			}
+			return k
+		}
+
+		for _, l := range leaves {
+			k := binOf(l)
+			if counts[k] == 0 {
+				binBoxes[k] = b.nodes[l].box
+			} else {
+				binBoxes[k] = binBoxes[k].Union(b.nodes[l].box)
+			}
+			counts[k]++
+		}
+
+		bestCost := math.Inf(1)
+		bestBin := -1
+
+		for s := 1; s < bvhBins; s++ {
+			leftCount, rightCount := 0, 0
+			var leftBox, rightBox AABB3D
+
+			for k := 0; k < s; k++ {
+				if counts[k] == 0 {
+					continue
+				}
+				if leftCount == 0 {
+					leftBox = binBoxes[k]
+				} else {
+					leftBox = leftBox.Union(binBoxes[k])
+				}
+				leftCount += counts[k]
+			}
+
+			for k := s; k < bvhBins; k++ {
+				if counts[k] == 0 {
+					continue
+				}
+				if rightCount == 0 {
+					rightBox = binBoxes[k]
+				} else {
+					rightBox = rightBox.Union(binBoxes[k])
+				}
+				rightCount += counts[k]
+			}
+
+			if leftCount == 0 || rightCount == 0 {
+				continue
+			}
+
+			cost := (float64(leftCount) * leftBox.SurfaceArea()) + (float64(rightCount) * rightBox.SurfaceArea())
+			if cost < bestCost {
+				bestCost = cost
+				bestBin = s
+			}
+		}
+
+		if bestBin != -1 {
+			sort.SliceStable(leaves, func(i, j int) bool {
+				return binOf(leaves[i]) < binOf(leaves[j])
+			})
+
+			split = 0
+			for split < len(leaves) && binOf(leaves[split]) < bestBin {
+				split++
+			}
+		}
+	}
+
+	// all centers coincide, fall back to a median split
+	if split == 0 || split == len(leaves) {
+		split = len(leaves) / 2
+	}
+
+	left := b.build(leaves[:split])
+	right := b.build(leaves[split:])
+
+	n := b.allocate()
+	node := &b.nodes[n]
+	node.left = left
+	node.right = right
+	node.box = bounds
+	node.height = 1 + max(b.nodes[left].height, b.nodes[right].height)
+
+	b.nodes[left].parent = n
+	b.nodes[right].parent = n
+
+	return n
+}
+
+func (b *BVH) Len() int {
+	return len(b.leaves)
+}
+
+// exact box of the object as last given
+func (b *BVH) Bounds(id int) (AABB3D, bool) {
+	n, ok := b.leaves[id]
+	if !ok {
+		return AABB3D{}, false
+	}
+
+	return b.nodes[n].tight, true
+}
+
+func (b *BVH) Insert(id int, box AABB3D) error {
+	if _, ok := b.leaves[id]; ok {
+		return ErrDuplicateID
+	}
+
+	n := b.allocate()
+	b.nodes[n].id = id
+	b.nodes[n].tight = box
+	b.nodes[n].box = box.Expand(b.Margin)
+
+	b.leaves[id] = n
+	b.insertLeaf(n)
+
+	return nil
+}
+
+func (b *BVH) Remove(id int) error {
+	n, ok := b.leaves[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	b.removeLeaf(n)
+	b.release(n)
+	delete(b.leaves, id)
+
+	return nil
+}
+
+// Moves an object, the tree is only restructured when the new box leaves
+// the fattened one. Returns true if the object was reinserted
+func (b *BVH) Update(id int, box AABB3D) (bool, error) {
+	n, ok := b.leaves[id]
+	if !ok {
+		return false, ErrUnknownID
+	}
+
+	b.nodes[n].tight = box
+	if b.nodes[n].box.Contains(box) {
+		return false, nil
+	}
+
+	b.removeLeaf(n)
+	b.nodes[n].box = box.Expand(b.Margin)
+	b.insertLeaf(n)
+
+	return true, nil
+}
+
+// Changes the box of an object in place and refits its ancestors without
+// changing the structure, cheap but the tree quality degrades with large moves
+func (b *BVH) Refit(id int, box AABB3D) error {
+	n, ok := b.leaves[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	b.nodes[n].tight = box
+	b.nodes[n].box = box.Expand(b.Margin)
+
+	for p := b.nodes[n].parent; p != bvhNull; p = b.nodes[p].parent {
+		b.nodes[p].box = b.nodes[b.nodes[p].left].box.Union(b.nodes[b.nodes[p].right].box)
+	}
+
+	return nil
+}
+
+func (b *BVH) allocate() int {
+	if b.free != bvhNull {
+		n := b.free
+		b.free = b.nodes[n].parent
+		b.nodes[n] = bvhNode{parent: bvhNull, left: bvhNull, right: bvhNull}
+		return n
+	}
+
+	b.nodes = append(b.nodes, bvhNode{parent: bvhNull, left: bvhNull, right: bvhNull})
+	return len(b.nodes) - 1
+}
+
+func (b *BVH) release(n int) {
+	b.nodes[n] = bvhNode{parent: b.free, left: bvhNull, right: bvhNull, height: -1}
+	b.free = n
+}
+
+// Picks the sibling by descending towards the smallest increase in surface
+// area, as done in Box2D
+func (b *BVH) insertLeaf(leaf int) {
+	if b.root == bvhNull {
+		b.root = leaf
+		b.nodes[leaf].parent = bvhNull
+		return
+	}
+
+	box := b.nodes[leaf].box
+	idx := b.root
+
+	for !b.nodes[idx].isLeaf() {
+		node := b.nodes[idx]
+		area := node.box.SurfaceArea()
+
+		combined := node.box.Union(box).SurfaceArea()
+		cost := 2 * combined
+		inherit := 2 * (combined - area)
+
+		childCost := func(c int) float64 {
+			u := b.nodes[c].box.Union(box).SurfaceArea()
+			if b.nodes[c].isLeaf() {
+				return u + inherit
+			}
+			return (u - b.nodes[c].box.SurfaceArea()) + inherit
+		}
+
+		costLeft := childCost(node.left)
+		costRight := childCost(node.right)
+
+		if cost < costLeft && cost < costRight {
+			break
+		}
+
+		if costLeft < costRight {
+			idx = node.left
+		} else {
+			idx = node.right
+		}
+	}
+
+	sibling := idx
+	oldParent := b.nodes[sibling].parent
+
+	parent := b.allocate()
+	b.nodes[parent].parent = oldParent
+	b.nodes[parent].box = box.Union(b.nodes[sibling].box)
+	b.nodes[parent].height = b.nodes[sibling].height + 1
+	b.nodes[parent].left = sibling
+	b.nodes[parent].right = leaf
+
+	b.nodes[sibling].parent = parent
+	b.nodes[leaf].parent = parent
+
+	if oldParent == bvhNull {
+		b.root = parent
+	} else if b.nodes[oldParent].left == sibling {
+		b.nodes[oldParent].left = parent
+	} else {
+		b.nodes[oldParent].right = parent
+	}
+
+	b.fixUpwards(parent)
+}
+
+func (b *BVH) removeLeaf(leaf int) {
+	if leaf == b.root {
+		b.root = bvhNull
+		return
+	}
+
+	parent := b.nodes[leaf].parent
+	grand := b.nodes[parent].parent
+
+	sibling := b.nodes[parent].left
+	if sibling == leaf {
+		sibling = b.nodes[parent].right
+	}
+
+	b.release(parent)
+	b.nodes[leaf].parent = bvhNull
+
+	if grand == bvhNull {
+		b.root = sibling
+		b.nodes[sibling].parent = bvhNull
+		return
+	}
+
+	if b.nodes[grand].left == parent {
+		b.nodes[grand].left = sibling
+	} else {
+		b.nodes[grand].right = sibling
+	}
+	b.nodes[sibling].parent = grand
+
+	b.fixUpwards(grand)
+}
+
+func (b *BVH) fixUpwards(idx int) {
+	for idx != bvhNull {
+		idx = b.balance(idx)
+
+		node := &b.nodes[idx]
+		left := b.nodes[node.left]
+		right := b.nodes[node.right]
+
+		node.height = 1 + max(left.height, right.height)
+		node.box = left.box.Union(right.box)
+
+		idx = node.parent
+	}
+}
+
+// Tree rotation when the children heights differ by more than one,
+// returns the node now at the position of a
+func (b *BVH) balance(a int) int {
+	A := &b.nodes[a]
+	if A.isLeaf() || A.height < 2 {
+		return a
+	}
+
+	l, r := A.left, A.right
+	diff := b.nodes[r].height - b.nodes[l].height
+
+	if diff > 1 {
+		return b.rotate(a, r)
+	}
+
+	if diff < -1 {
+		return b.rotate(a, l)
+	}
+
+	return a
+}
+
+// promotes the taller child up over a
+func (b *BVH) rotate(a, up int) int {
+	U := b.nodes[up]
+	f, g := U.left, U.right
+
+	b.nodes[up].left = a
+	b.nodes[up].parent = b.nodes[a].parent
+	b.nodes[a].parent = up
+
+	if p := b.nodes[up].parent; p == bvhNull {
+		b.root = up
+	} else if b.nodes[p].left == a {
+		b.nodes[p].left = up
+	} else {
+		b.nodes[p].right = up
+	}
+
+	// keep the taller grandchild under up
+	keep, move := f, g
+	if b.nodes[g].height > b.nodes[f].height {
+		keep, move = g, f
+	}
+
+	b.nodes[up].right = keep
+
+	if b.nodes[a].left == up {
+		b.nodes[a].left = move
+	} else {
+		b.nodes[a].right = move
+	}
+	b.nodes[move].parent = a
+
+	A := &b.nodes[a]
+	A.box = b.nodes[A.left].box.Union(b.nodes[A.right].box)
+	A.height = 1 + max(b.nodes[A.left].height, b.nodes[A.right].height)
+
+	N := &b.nodes[up]
+	N.box = b.nodes[a].box.Union(b.nodes[keep].box)
+	N.height = 1 + max(b.nodes[a].height, b.nodes[keep].height)
+
+	return up
+}
+
+// ids of the objects whose boxes overlap box
+func (b *BVH) QueryAABB(box AABB3D) []int {
+	return b.query(func(n AABB3D) bool {
+		return n.Intersects(box)
+	})
+}
+
+func (b *BVH) QuerySphere(s Sphere) []int {
+	return b.query(func(n AABB3D) bool {
+		return n.IntersectsSphere(s)
+	})
+}
+
+// ids of the objects not fully behind any of the planes, the planes of a
+// frustum should point inwards
+func (b *BVH) QueryFrustum(planes []Plane) []int {
+	return b.query(func(n AABB3D) bool {
+		for _, p := range planes {
+			if p.IsAABBBehind(n) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (b *BVH) query(overlaps func(AABB3D) bool) []int {
+	out := make([]int, 0)
+	if b.root == bvhNull {
+		return out
+	}
+
+	stack := []int{b.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &b.nodes[n]
+		if !overlaps(node.box) {
+			continue
+		}
+
+		if node.isLeaf() {
+			if overlaps(node.tight) {
+				out = append(out, node.id)
+			}
+			continue
+		}
+
+		stack = append(stack, node.left, node.right)
+	}
+
+	return out
+}
+
+// Closest hit within maxT, nodes are visited front to back so far subtrees
+// are skipped once a closer hit is known
+func (b *BVH) RayCast(r Ray3D, maxT float64, hit BVHRayFunc) (BVHHit, bool) {
+	best := BVHHit{ID: -1, T: maxT}
+	found := false
+
+	if b.root == bvhNull {
+		return best, false
+	}
+
+	type entry struct {
+		node int
+		t    float64
+	}
+
+	stack := []entry{{node: b.root, t: 0}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if e.t > best.T {
+			continue
+		}
+
+		node := &b.nodes[e.node]
+		if node.isLeaf() {
+			if t, ok := b.rayLeaf(node, r, hit); ok && t <= best.T {
+				best = BVHHit{ID: node.id, T: t}
+				found = true
+			}
+			continue
+		}
+
+		tl, _, okL := b.nodes[node.left].box.IntersectRay(r)
+		tr, _, okR := b.nodes[node.right].box.IntersectRay(r)
+
+		// push the farther child first so the nearer one is popped next
+		if okL && okR && tl < tr {
+			stack = append(stack, entry{node.right, tr}, entry{node.left, tl})
+			continue
+		}
+
+		if okL {
+			stack = append(stack, entry{node.left, tl})
+		}
+		if okR {
+			stack = append(stack, entry{node.right, tr})
+		}
+	}
+
+	if !found {
+		best.ID = -1
+	}
+
+	return best, found
+}
+
+// every hit within maxT sorted by distance
+func (b *BVH) RayCastAll(r Ray3D, maxT float64, hit BVHRayFunc) []BVHHit {
+	out := make([]BVHHit, 0)
+	if b.root == bvhNull {
+		return out
+	}
+
+	stack := []int{b.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &b.nodes[n]
+		if t, _, ok := node.box.IntersectRay(r); !ok || t > maxT {
+			continue
+		}
+
+		if node.isLeaf() {
+			if t, ok := b.rayLeaf(node, r, hit); ok && t <= maxT {
+				out = append(out, BVHHit{ID: node.id, T: t})
+			}
+			continue
+		}
+
+		stack = append(stack, node.left, node.right)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].T == out[j].T {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].T < out[j].T
+	})
+
+	return out
+}
+
+func (b *BVH) rayLeaf(node *bvhNode, r Ray3D, hit BVHRayFunc) (float64, bool) {
+	t, _, ok := node.tight.IntersectRay(r)
+	if !ok {
+		return 0, false
+	}
+
+	if hit != nil {
+		return hit(node.id, r)
+	}
+
+	return math.Max(t, 0), true
+}
+
+func vecAxis(v Vec3D, axis int) float64 {
+	switch axis {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	default:
+		return v.Z
+	}
+}
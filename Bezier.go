@@ -0,0 +1,313 @@
+package golem
+
+import "math"
+
+type QuadBezier2D struct {
+	P0, P1, P2 Vec2D
+}
+
+type CubicBezier2D struct {
+	P0, P1, P2, P3 Vec2D
+}
+
+func (b QuadBezier2D) Domain() (float64, float64) {
+	return 0, 1
+}
+
+func (b QuadBezier2D) At(t float64) Vec2D {
+	s := 1 - t
+
+	p := b.P0.ScalerMulVec(s * s)
+	p = p.AddVec(b.P1.ScalerMulVec(2 * s * t))
+	p = p.AddVec(b.P2.ScalerMulVec(t * t))
+
+	return p
+}
+
+func (b QuadBezier2D) Derivative(t float64) Vec2D {
+	d0 := b.P1.SubVec(b.P0).ScalerMulVec(2 * (1 - t))
+	d1 := b.P2.SubVec(b.P1).ScalerMulVec(2 * t)
+
+	return d0.AddVec(d1)
+}
+
+func (b QuadBezier2D) SecondDerivative(t float64) Vec2D {
+	return b.P2.SubVec(b.P1.ScalerMulVec(2)).AddVec(b.P0).ScalerMulVec(2)
+}
+
+// de Casteljau, the halves cover [0, t] and [t, 1] of the original
+func (b QuadBezier2D) Split(t float64) (QuadBezier2D, QuadBezier2D) {
+	t = Clamp(t, 0, 1)
+
+	p01, _ := b.P0.LerpV(b.P1, t)
+	p12, _ := b.P1.LerpV(b.P2, t)
+	mid, _ := p01.LerpV(p12, t)
+
+	return QuadBezier2D{b.P0, p01, mid}, QuadBezier2D{mid, p12, b.P2}
+}
+
+// exact cubic form of the same curve
+func (b QuadBezier2D) ToCubic() CubicBezier2D {
+	return CubicBezier2D{
+		P0: b.P0,
+		P1: b.P0.AddVec(b.P1.SubVec(b.P0).ScalerMulVec(2.0 / 3)),
+		P2: b.P2.AddVec(b.P1.SubVec(b.P2).ScalerMulVec(2.0 / 3)),
+		P3: b.P2,
+	}
+}
+
+// tight box from the end points and the axis extrema
+func (b QuadBezier2D) Bounds() AABB2D {
+	box := AABB2D{Min: b.P0, Max: b.P0}
+	box.AddPoint(b.P2)
+
+	for a := 0; a < 2; a++ {
+		p0, p1, p2 := vecAxis2D(b.P0, a), vecAxis2D(b.P1, a), vecAxis2D(b.P2, a)
+
+		den := p0 - 2*p1 + p2
+		if den == 0 {
+			continue
+		}
+
+		if t := (p0 - p1) / den; t > 0 && t < 1 {
+			box.AddPoint(b.At(t))
+		}
+	}
+
+	return box
+}
+
+func (b CubicBezier2D) Domain() (float64, float64) {
+	return 0, 1
+}
+
+func (b CubicBezier2D) At(t float64) Vec2D {
+	s := 1 - t
+
+	p := b.P0.ScalerMulVec(s * s * s)
+	p = p.AddVec(b.P1.ScalerMulVec(3 * s * s * t))
+	p = p.AddVec(b.P2.ScalerMulVec(3 * s * t * t))
+	p = p.AddVec(b.P3.ScalerMulVec(t * t * t))
+
+	return p
+}
+
+func (b CubicBezier2D) Derivative(t float64) Vec2D {
+	s := 1 - t
+
+	d := b.P1.SubVec(b.P0).ScalerMulVec(3 * s * s)
+	d = d.AddVec(b.P2.SubVec(b.P1).ScalerMulVec(6 * s * t))
+	d = d.AddVec(b.P3.SubVec(b.P2).ScalerMulVec(3 * t * t))
+
+	return d
+}
+
+func (b CubicBezier2D) SecondDerivative(t float64) Vec2D {
+	a := b.P2.SubVec(b.P1.ScalerMulVec(2)).AddVec(b.P0)
+	c := b.P3.SubVec(b.P2.ScalerMulVec(2)).AddVec(b.P1)
+
+	return a.ScalerMulVec(6 * (1 - t)).AddVec(c.ScalerMulVec(6 * t))
+}
+
+// de Casteljau, the halves cover [0, t] and [t, 1] of the original
+func (b CubicBezier2D) Split(t float64) (CubicBezier2D, CubicBezier2D) {
+	t = Clamp(t, 0, 1)
+
+	p01, _ := b.P0.LerpV(b.P1, t)
+	p12, _ := b.P1.LerpV(b.P2, t)
+	p23, _ := b.P2.LerpV(b.P3, t)
+
+	p012, _ := p01.LerpV(p12, t)
+	p123, _ := p12.LerpV(p23, t)
+
+	mid, _ := p012.LerpV(p123, t)
+
+	return CubicBezier2D{b.P0, p01, p012, mid}, CubicBezier2D{mid, p123, p23, b.P3}
+}
+
+// tight box from the end points and the axis extrema
+func (b CubicBezier2D) Bounds() AABB2D {
+	box := AABB2D{Min: b.P0, Max: b.P0}
+	box.AddPoint(b.P3)
+
+	for a := 0; a < 2; a++ {
+		d0 := vecAxis2D(b.P1, a) - vecAxis2D(b.P0, a)
+		d1 := vecAxis2D(b.P2, a) - vecAxis2D(b.P1, a)
+		d2 := vecAxis2D(b.P3, a) - vecAxis2D(b.P2, a)
+
+		for _, t := range quadRoots01(d0-2*d1+d2, 2*(d1-d0), d0) {
+			box.AddPoint(b.At(t))
+		}
+	}
+
+	return box
+}
+
+type QuadBezier3D struct {
+	P0, P1, P2 Vec3D
+}
+
+type CubicBezier3D struct {
+	P0, P1, P2, P3 Vec3D
+}
+
+func (b QuadBezier3D) Domain() (float64, float64) {
+	return 0, 1
+}
+
+func (b QuadBezier3D) At(t float64) Vec3D {
+	s := 1 - t
+
+	p := b.P0.ScalerMulVec(s * s)
+	p = p.AddVec(b.P1.ScalerMulVec(2 * s * t))
+	p = p.AddVec(b.P2.ScalerMulVec(t * t))
+
+	return p
+}
+
+func (b QuadBezier3D) Derivative(t float64) Vec3D {
+	d0 := b.P1.SubVec(b.P0).ScalerMulVec(2 * (1 - t))
+	d1 := b.P2.SubVec(b.P1).ScalerMulVec(2 * t)
+
+	return d0.AddVec(d1)
+}
+
+func (b QuadBezier3D) SecondDerivative(t float64) Vec3D {
+	return b.P2.SubVec(b.P1.ScalerMulVec(2)).AddVec(b.P0).ScalerMulVec(2)
+}
+
+// de Casteljau, the halves cover [0, t] and [t, 1] of the original
+func (b QuadBezier3D) Split(t float64) (QuadBezier3D, QuadBezier3D) {
+	t = Clamp(t, 0, 1)
+
+	p01, _ := b.P0.LerpV(b.P1, t)
+	p12, _ := b.P1.LerpV(b.P2, t)
+	mid, _ := p01.LerpV(p12, t)
+
+	return QuadBezier3D{b.P0, p01, mid}, QuadBezier3D{mid, p12, b.P2}
+}
+
+// exact cubic form of the same curve
+func (b QuadBezier3D) ToCubic() CubicBezier3D {
+	return CubicBezier3D{
+		P0: b.P0,
+		P1: b.P0.AddVec(b.P1.SubVec(b.P0).ScalerMulVec(2.0 / 3)),
+		P2: b.P2.AddVec(b.P1.SubVec(b.P2).ScalerMulVec(2.0 / 3)),
+		P3: b.P2,
+	}
+}
+
+// tight box from the end points and the axis extrema
+func (b QuadBezier3D) Bounds() AABB3D {
+	box := AABB3D{Min: b.P0, Max: b.P0}
+	box.AddPoint(b.P2)
+
+	for a := 0; a < 3; a++ {
+		p0, p1, p2 := vecAxis(b.P0, a), vecAxis(b.P1, a), vecAxis(b.P2, a)
+
+		den := p0 - 2*p1 + p2
+		if den == 0 {
+			continue
+		}
+
+		if t := (p0 - p1) / den; t > 0 && t < 1 {
+			box.AddPoint(b.At(t))
+		}
+	}
+
+	return box
+}
+
+func (b CubicBezier3D) Domain() (float64, float64) {
+	return 0, 1
+}
+
+func (b CubicBezier3D) At(t float64) Vec3D {
+	s := 1 - t
+
+	p := b.P0.ScalerMulVec(s * s * s)
+	p = p.AddVec(b.P1.ScalerMulVec(3 * s * s * t))
+	p = p.AddVec(b.P2.ScalerMulVec(3 * s * t * t))
+	p = p.AddVec(b.P3.ScalerMulVec(t * t * t))
+
+	return p
+}
+
+func (b CubicBezier3D) Derivative(t float64) Vec3D {
+	s := 1 - t
+
+	d := b.P1.SubVec(b.P0).ScalerMulVec(3 * s * s)
+	d = d.AddVec(b.P2.SubVec(b.P1).ScalerMulVec(6 * s * t))
+	d = d.AddVec(b.P3.SubVec(b.P2).ScalerMulVec(3 * t * t))
+
+	return d
+}
+
+func (b CubicBezier3D) SecondDerivative(t float64) Vec3D {
+	a := b.P2.SubVec(b.P1.ScalerMulVec(2)).AddVec(b.P0)
+	c := b.P3.SubVec(b.P2.ScalerMulVec(2)).AddVec(b.P1)
+
+	return a.ScalerMulVec(6 * (1 - t)).AddVec(c.ScalerMulVec(6 * t))
+}
+
+// de Casteljau, the halves cover [0, t] and [t, 1] of the original
+func (b CubicBezier3D) Split(t float64) (CubicBezier3D, CubicBezier3D) {
+	t = Clamp(t, 0, 1)
+
+	p01, _ := b.P0.LerpV(b.P1, t)
+	p12, _ := b.P1.LerpV(b.P2, t)
+	p23, _ := b.P2.LerpV(b.P3, t)
+
+	p012, _ := p01.LerpV(p12, t)
+	p123, _ := p12.LerpV(p23, t)
+
+	mid, _ := p012.LerpV(p123, t)
+
+	return CubicBezier3D{b.P0, p01, p012, mid}, CubicBezier3D{mid, p123, p23, b.P3}
+}
+
+// tight box from the end points and the axis extrema
+func (b CubicBezier3D) Bounds() AABB3D {
+	box := AABB3D{Min: b.P0, Max: b.P0}
+	box.AddPoint(b.P3)
+
+	for a := 0; a < 3; a++ {
+		d0 := vecAxis(b.P1, a) - vecAxis(b.P0, a)
+		d1 := vecAxis(b.P2, a) - vecAxis(b.P1, a)
+		d2 := vecAxis(b.P3, a) - vecAxis(b.P2, a)
+
+		for _, t := range quadRoots01(d0-2*d1+d2, 2*(d1-d0), d0) {
+			box.AddPoint(b.At(t))
+		}
+	}
+
+	return box
+}
+
+// roots of a t^2 + b t + c strictly inside (0, 1)
+func quadRoots01(a, b, c float64) []float64 {
+	out := make([]float64, 0, 2)
+
+	if math.Abs(a) < 1e-12 {
+		if b != 0 {
+			if t := -c / b; t > 0 && t < 1 {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		return out
+	}
+
+	sq := math.Sqrt(disc)
+	for _, t := range [2]float64{(-b - sq) / (2 * a), (-b + sq) / (2 * a)} {
+		if t > 0 && t < 1 {
+			out = append(out, t)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,44 @@
+package golem
+
+// Points within Radius of the segment from A to B
+type Capsule struct {
+	A, B   Vec3D
+	Radius float64
+}
+
+// point of the inner segment closest to p
+func (c Capsule) SegmentPoint(p Vec3D) Vec3D {
+	ab := c.B.SubVec(c.A)
+	l := ab.Dot(ab)
+	if l == 0 {
+		return c.A
+	}
+
+	ap := p.SubVec(c.A)
+	t := Clamp(ap.Dot(ab)/l, 0, 1)
+	return c.A.AddVec(ab.ScalerMulVec(t))
+}
+
+func (c Capsule) ContainsPoint(p Vec3D) bool {
+	return p.Dist(c.SegmentPoint(p)) <= c.Radius
+}
+
+func (c Capsule) Bounds() AABB3D {
+	b := AABB3D{Min: c.A, Max: c.A}
+	b.AddPoint(c.B)
+
+	return b.Expand(c.Radius)
+}
+
+func (c Capsule) Support(dir Vec3D) Vec3D {
+	end := c.A
+	if dir.Dot(c.B) > dir.Dot(c.A) {
+		end = c.B
+	}
+
+	if _, err := dir.Normalize(); err != nil {
+		return end
+	}
+
+	return end.AddVec(dir.ScalerMulVec(c.Radius))
+}
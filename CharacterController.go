@@ -0,0 +1,276 @@
+package golem
+
+import "math"
+
+// Collision backend for a CharacterController
+type CharacterWorld interface {
+	// First hit of the capsule moved by delta, if any
+	SweepCapsule(c Capsule, delta Vec3D) (SweepHit, bool)
+}
+
+// Fraction is the part of the move made before touching, Normal points
+// out of the obstacle towards the capsule
+type SweepHit struct {
+	Fraction float64
+	Point    Vec3D
+	Normal   Vec3D
+}
+
+// Kinematic capsule that slides along what it runs into, steps over low
+// obstacles and sticks to the ground going down slopes. Position is the
+// center of the capsule
+type CharacterController struct {
+	World    CharacterWorld
+	Position Vec3D
+	// unit vector
+	Up Vec3D
+
+	Radius float64
+	// full height including the end caps
+	Height float64
+
+	// steepest walkable ground in radians from Up, anything steeper is a wall
+	MaxSlope float64
+	// tallest ledge climbed without jumping
+	StepHeight float64
+	// how far down the character is pulled to keep it on the ground
+	SnapDistance float64
+	// gap kept to obstacles so sweeps start outside them
+	SkinWidth float64
+	// sweeps per move before giving up on the rest
+	MaxSlides int
+
+	OnGround     bool
+	GroundNormal Vec3D
+}
+
+type slideResult struct {
+	pos      Vec3D
+	blocked  bool
+	grounded bool
+	ground   Vec3D
+}
+
+func NewCharacterController(world CharacterWorld, position Vec3D, radius, height float64) *CharacterController {
+	return &CharacterController{
+		World:        world,
+		Position:     position,
+		Up:           Vec3D{Y: 1},
+		Radius:       radius,
+		Height:       math.Max(height, 2*radius),
+		MaxSlope:     math.Pi / 4,
+		StepHeight:   0.3,
+		SnapDistance: 0.3,
+		SkinWidth:    0.01,
+		MaxSlides:    4,
+	}
+}
+
+// capsule at pos
+func (c *CharacterController) Capsule(pos Vec3D) Capsule {
+	half := c.Up.ScalerMulVec(c.Height/2 - c.Radius)
+	return Capsule{A: pos.SubVec(half), B: pos.AddVec(half), Radius: c.Radius}
+}
+
+// ground with the normal n can be stood on
+func (c *CharacterController) Walkable(n Vec3D) bool {
+	return n.Dot(c.Up) >= math.Cos(c.MaxSlope)-1e-9
+}
+
+// Moves the character by delta, the part along Up is gravity or a jump
+// and the rest is walking. Returns the displacement actually made
+func (c *CharacterController) Move(delta Vec3D) Vec3D {
+	start := c.Position
+	wasGrounded := c.OnGround
+	c.OnGround, c.GroundNormal = false, Vec3D{}
+
+	vertical := c.Up.ScalerMulVec(delta.Dot(c.Up))
+	horizontal := delta.SubVec(vertical)
+
+	if horizontal.Length() > 0 {
+		r := c.slide(c.Position, horizontal, true)
+
+		// something in the way, try to climb over it
+		if r.blocked && wasGrounded && c.StepHeight > 0 {
+			if p, ground, ok := c.step(c.Position, horizontal); ok && c.progress(p, horizontal) > c.progress(r.pos, horizontal)+1e-6 {
+				r.pos = p
+				c.OnGround, c.GroundNormal = true, ground
+			}
+		}
+		c.Position = r.pos
+	}
+
+	if vertical.Length() > 0 {
+		r := c.slide(c.Position, vertical, false)
+		c.Position = r.pos
+		if r.grounded {
+			c.OnGround, c.GroundNormal = true, r.ground
+		}
+	}
+
+	if wasGrounded && !c.OnGround && delta.Dot(c.Up) <= 0 {
+		c.snap()
+	}
+
+	return c.Position.SubVec(start)
+}
+
+// distance moved from the current position along dir
+func (c *CharacterController) progress(p, dir Vec3D) float64 {
+	d := p.SubVec(c.Position)
+	return d.Dot(dir) / dir.Length()
+}
+
+// Sweeps from pos stopping SkinWidth clear of a hit, returns the new
+// position and the hit. The sweep reaches a skin further than delta so
+// the character does not creep closer than that over short moves
+func (c *CharacterController) sweep(pos, delta Vec3D) (Vec3D, SweepHit, bool) {
+	l := delta.Length()
+	if l == 0 {
+		return pos, SweepHit{}, false
+	}
+
+	reach := l + c.SkinWidth
+	hit, ok := c.World.SweepCapsule(c.Capsule(pos), delta.ScalerMulVec(reach/l))
+	if !ok {
+		return pos.AddVec(delta), hit, false
+	}
+
+	// backing off further the more glancing the hit keeps the skin
+	// measured along the normal
+	back := c.SkinWidth
+	if cos := -hit.Normal.Dot(delta) / l; cos > 0 {
+		back /= cos
+	}
+
+	travel := Clamp(hit.Fraction*reach-back, 0, l)
+	return pos.AddVec(delta.ScalerMulVec(travel / l)), hit, true
+}
+
+// Collide and slide, the rest of the move after a hit is projected onto
+// the surface. Walking treats steep slopes as upright walls so the
+// character cannot climb them, falling stops on walkable ground instead
+// of sliding down it
+func (c *CharacterController) slide(pos, delta Vec3D, walking bool) slideResult {
+	r := slideResult{pos: pos}
+	planes := make([]Vec3D, 0, c.MaxSlides)
+	dir := delta
+
+	for i := 0; i < c.MaxSlides; i++ {
+		l := delta.Length()
+		if l < 1e-9 {
+			break
+		}
+
+		p, hit, ok := c.sweep(r.pos, delta)
+		moved := p.SubVec(r.pos)
+		r.pos = p
+		if !ok {
+			break
+		}
+
+		n := hit.Normal
+		if !walking && delta.Dot(c.Up) < 0 && !c.Walkable(n) {
+			if top, ok := c.ledge(hit); ok {
+				n = top
+			}
+		}
+
+		if c.Walkable(n) {
+			if delta.Dot(c.Up) < 0 {
+				r.grounded, r.ground = true, n
+			}
+			if !walking {
+				break
+			}
+		} else {
+			r.blocked = true
+			if walking {
+				n.Sub(c.Up.ScalerMulVec(n.Dot(c.Up)))
+				if _, err := n.Normalize(); err != nil {
+					break
+				}
+			}
+		}
+
+		rest := delta.SubVec(moved)
+		rest.Sub(rest.ProjectionOnto(n))
+
+		// pressed between two surfaces, run along the crease between them
+		for _, prev := range planes {
+			if rest.Dot(prev) < 0 {
+				crease := prev.CrossV(n)
+				if _, err := crease.Normalize(); err != nil {
+					rest = Vec3D{}
+				} else {
+					rest = crease.ScalerMulVec(rest.Dot(crease))
+				}
+				break
+			}
+		}
+
+		// never bounce back against the original move
+		if rest.Dot(dir) <= 0 {
+			break
+		}
+
+		planes = append(planes, n)
+		delta = rest
+	}
+
+	return r
+}
+
+// raise by StepHeight, walk, then lower back onto the top of the obstacle
+func (c *CharacterController) step(pos, horizontal Vec3D) (Vec3D, Vec3D, bool) {
+	raised, _, _ := c.sweep(pos, c.Up.ScalerMulVec(c.StepHeight))
+	rise := raised.SubVec(pos)
+
+	moved := c.slide(raised, horizontal, true)
+
+	drop := rise.Length() + 2*c.SkinWidth
+	p, hit, ok := c.sweep(moved.pos, c.Up.ScalerMulVec(-drop))
+	if !ok {
+		return pos, Vec3D{}, false
+	}
+	if c.Walkable(hit.Normal) {
+		return p, hit.Normal, true
+	}
+	if top, ok := c.ledge(hit); ok {
+		return p, top, true
+	}
+
+	return pos, Vec3D{}, false
+}
+
+// Resting on the edge of a ledge gives the steep normal of the edge, a
+// small probe dropped just past the edge finds the top face
+func (c *CharacterController) ledge(hit SweepHit) (Vec3D, bool) {
+	in := hit.Normal.SubVec(c.Up.ScalerMulVec(hit.Normal.Dot(c.Up)))
+	if _, err := in.Normalize(); err != nil {
+		return Vec3D{}, false
+	}
+
+	start := hit.Point.SubVec(in.ScalerMulVec(c.SkinWidth / 2)).AddVec(c.Up.ScalerMulVec(2 * c.SkinWidth))
+	probe, ok := c.World.SweepCapsule(Capsule{A: start, B: start, Radius: c.SkinWidth / 4}, c.Up.ScalerMulVec(-4*c.SkinWidth))
+	if !ok || !c.Walkable(probe.Normal) {
+		return Vec3D{}, false
+	}
+
+	return probe.Normal, true
+}
+
+// pulls the character down onto ground it just walked off
+func (c *CharacterController) snap() {
+	if c.SnapDistance <= 0 {
+		return
+	}
+
+	p, hit, ok := c.sweep(c.Position, c.Up.ScalerMulVec(-c.SnapDistance))
+	if !ok || !c.Walkable(hit.Normal) {
+		return
+	}
+
+	c.Position = p
+	c.OnGround, c.GroundNormal = true, hit.Normal
+}
@@ -0,0 +1,25 @@
+package golem
+
+import "math"
+
+type Circle struct {
+	Center Vec2D
+	Radius float64
+}
+
+func (c Circle) ContainsPoint(p Vec2D) bool {
+	return c.Center.Dist(p) <= c.Radius
+}
+
+// touching circles intersect
+func (c Circle) Intersects(o Circle) bool {
+	return c.Center.Dist(o.Center) <= c.Radius+o.Radius
+}
+
+func (c Circle) Bounds() AABB2D {
+	return NewAABB2D(c.Center, Vec2D{X: c.Radius, Y: c.Radius})
+}
+
+func (c Circle) Area() float64 {
+	return math.Pi * c.Radius * c.Radius
+}
@@ -0,0 +1,370 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// Faces are wound ccw when seen from outside so the right hand normal
+// points outwards. Indices refer to Points which is a copy of the input
+type ConvexHull3D struct {
+	Points    []Vec3D
+	Vertices  []int
+	Faces     [][3]int
+	Edges     [][2]int
+	Normals   []Vec3D
+	Tolerance float64
+}
+
+type hullFace struct {
+	v       [3]int
+	normal  Vec3D
+	offset  float64
+	outside []int
+	alive   bool
+}
+
+// Quickhull. Points closer than tolerance to a face are treated as lying on
+// it and are not part of the hull, a tolerance <= 0 picks one from the
+// extent of the points
+func NewConvexHull3D(points []Vec3D, tolerance float64) (*ConvexHull3D, error) {
+	if len(points) < 4 {
+		return nil, ErrInvalidLen
+	}
+
+	pts := make([]Vec3D, len(points))
+	copy(pts, points)
+
+	if tolerance <= 0 {
+		maxX, maxY, maxZ := 0.0, 0.0, 0.0
+		for _, p := range pts {
+			maxX = math.Max(maxX, math.Abs(p.X))
+			maxY = math.Max(maxY, math.Abs(p.Y))
+			maxZ = math.Max(maxZ, math.Abs(p.Z))
+		}
+		tolerance = 3 * 2.220446049250313e-16 * (maxX + maxY + maxZ)
+	}
+
+	simplex, err := hullSimplex(pts, tolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	faces := make([]hullFace, 0, 16)
+	edges := make(map[[2]int]int)
+
+	center := Vec3D{}
+	for _, i := range simplex {
+		center.Add(pts[i])
+	}
+	center.ScalerDiv(4)
+
+	addFace := func(a, b, c int) int {
+		f := hullFace{v: [3]int{a, b, c}, alive: true}
+		f.normal = pts[b].SubVec(pts[a]).CrossV(pts[c].SubVec(pts[a]))
+		f.normal.Normalize()
+		f.offset = f.normal.Dot(pts[a])
+
+		idx := len(faces)
+		faces = append(faces, f)
+
+		edges[[2]int{a, b}] = idx
+		edges[[2]int{b, c}] = idx
+		edges[[2]int{c, a}] = idx
+
+		return idx
+	}
+
+	for i := 0; i < 4; i++ {
+		a, b, c := simplex[i], simplex[(i+1)%4], simplex[(i+2)%4]
+
+		n := pts[b].SubVec(pts[a]).CrossV(pts[c].SubVec(pts[a]))
+		if n.Dot(center.SubVec(pts[a])) > 0 {
+			b, c = c, b
+		}
+		addFace(a, b, c)
+	}
+
+	used := map[int]bool{simplex[0]: true, simplex[1]: true, simplex[2]: true, simplex[3]: true}
+	all := make([]int, 0, len(pts))
+	for i := range pts {
+		if !used[i] {
+			all = append(all, i)
+		}
+	}
+	assignHullPoints(pts, faces, []int{0, 1, 2, 3}, all, tolerance)
+
+	for {
+		fi := -1
+		for i := range faces {
+			if faces[i].alive && len(faces[i].outside) > 0 {
+				fi = i
+				break
+			}
+		}
+
+		if fi == -1 {
+			break
+		}
+
+		// the farthest outside point is always on the final hull
+		eye := faces[fi].outside[0]
+		best := -math.MaxFloat64
+		for _, p := range faces[fi].outside {
+			if d := faces[fi].normal.Dot(pts[p]) - faces[fi].offset; d > best {
+				best = d
+				eye = p
+			}
+		}
+
+		visible := map[int]bool{fi: true}
+		stack := []int{fi}
+		order := []int{fi}
+
+		for len(stack) > 0 {
+			f := faces[stack[len(stack)-1]]
+			stack = stack[:len(stack)-1]
+
+			for k := 0; k < 3; k++ {
+				nb, ok := edges[[2]int{f.v[(k+1)%3], f.v[k]}]
+				if !ok || visible[nb] {
+					continue
+				}
+
+				if faces[nb].normal.Dot(pts[eye])-faces[nb].offset > tolerance {
+					visible[nb] = true
+					stack = append(stack, nb)
+					order = append(order, nb)
+				}
+			}
+		}
+
+		horizon := make([][2]int, 0)
+		orphans := make([]int, 0)
+
+		for _, f := range order {
+			face := &faces[f]
+			for k := 0; k < 3; k++ {
+				a, b := face.v[k], face.v[(k+1)%3]
+				if nb, ok := edges[[2]int{b, a}]; !ok || !visible[nb] {
+					horizon = append(horizon, [2]int{a, b})
+				}
+			}
+
+			for _, p := range face.outside {
+				if p != eye {
+					orphans = append(orphans, p)
+				}
+			}
+
+			face.alive = false
+			face.outside = nil
+		}
+
+		for _, f := range order {
+			v := faces[f].v
+			for k := 0; k < 3; k++ {
+				if edges[[2]int{v[k], v[(k+1)%3]}] == f {
+					delete(edges, [2]int{v[k], v[(k+1)%3]})
+				}
+			}
+		}
+
+		created := make([]int, 0, len(horizon))
+		for _, e := range horizon {
+			created = append(created, addFace(e[0], e[1], eye))
+		}
+
+		assignHullPoints(pts, faces, created, orphans, tolerance)
+	}
+
+	hull := &ConvexHull3D{Points: pts, Tolerance: tolerance}
+
+	verts := make(map[int]bool)
+	for _, f := range faces {
+		if !f.alive {
+			continue
+		}
+
+		hull.Faces = append(hull.Faces, f.v)
+		hull.Normals = append(hull.Normals, f.normal)
+
+		for k := 0; k < 3; k++ {
+			a, b := f.v[k], f.v[(k+1)%3]
+			verts[a] = true
+			if a < b {
+				hull.Edges = append(hull.Edges, [2]int{a, b})
+			}
+		}
+	}
+
+	for v := range verts {
+		hull.Vertices = append(hull.Vertices, v)
+	}
+	sort.Ints(hull.Vertices)
+
+	return hull, nil
+}
+
+// picks four well spread points, fails when the points are coplanar
+func hullSimplex(pts []Vec3D, tol float64) ([4]int, error) {
+	out := [4]int{}
+
+	ext := [6]int{}
+	for i, p := range pts {
+		if p.X < pts[ext[0]].X {
+			ext[0] = i
+		}
+		if p.X > pts[ext[1]].X {
+			ext[1] = i
+		}
+		if p.Y < pts[ext[2]].Y {
+			ext[2] = i
+		}
+		if p.Y > pts[ext[3]].Y {
+			ext[3] = i
+		}
+		if p.Z < pts[ext[4]].Z {
+			ext[4] = i
+		}
+		if p.Z > pts[ext[5]].Z {
+			ext[5] = i
+		}
+	}
+
+	best := -1.0
+	for i := 0; i < 6; i++ {
+		for j := i + 1; j < 6; j++ {
+			if d := pts[ext[i]].Dist(pts[ext[j]]); d > best {
+				best = d
+				out[0], out[1] = ext[i], ext[j]
+			}
+		}
+	}
+
+	if best <= tol {
+		return out, ErrDegenerateHull
+	}
+
+	line := pts[out[1]].SubVec(pts[out[0]])
+	best = -1
+	for i, p := range pts {
+		if d := line.CrossV(p.SubVec(pts[out[0]])); d.Length() > best {
+			best = d.Length()
+			out[2] = i
+		}
+	}
+
+	if best/line.Length() <= tol {
+		return out, ErrDegenerateHull
+	}
+
+	n := line.CrossV(pts[out[2]].SubVec(pts[out[0]]))
+	n.Normalize()
+
+	best = -1
+	for i, p := range pts {
+		if d := math.Abs(n.Dot(p.SubVec(pts[out[0]]))); d > best {
+			best = d
+			out[3] = i
+		}
+	}
+
+	if best <= tol {
+		return out, ErrDegenerateHull
+	}
+
+	return out, nil
+}
+
+// points above no face are inside the hull and are dropped
+func assignHullPoints(pts []Vec3D, faces []hullFace, candidates, points []int, tol float64) {
+	for _, p := range points {
+		best := -1
+		bestDist := tol
+
+		for _, f := range candidates {
+			if d := faces[f].normal.Dot(pts[p]) - faces[f].offset; d > bestDist {
+				best = f
+				bestDist = d
+			}
+		}
+
+		if best != -1 {
+			faces[best].outside = append(faces[best].outside, p)
+		}
+	}
+}
+
+func (h *ConvexHull3D) Support(dir Vec3D) Vec3D {
+	best := h.Points[h.Vertices[0]]
+	bestDot := dir.Dot(best)
+
+	for _, v := range h.Vertices[1:] {
+		if d := dir.Dot(h.Points[v]); d > bestDot {
+			best = h.Points[v]
+			bestDot = d
+		}
+	}
+
+	return best
+}
+
+// points within the tolerance of the surface count as inside
+func (h *ConvexHull3D) ContainsPoint(p Vec3D) bool {
+	for i, f := range h.Faces {
+		if h.Normals[i].Dot(p.SubVec(h.Points[f[0]])) > h.Tolerance {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clips the ray against every face plane, returns the entry distance
+// or 0 when the origin is inside
+func (h *ConvexHull3D) IntersectRay(r Ray3D) (float64, bool) {
+	tMin := 0.0
+	tMax := math.Inf(1)
+
+	for i, f := range h.Faces {
+		n := h.Normals[i]
+		den := n.Dot(r.Dir)
+		dist := n.Dot(r.Origin.SubVec(h.Points[f[0]]))
+
+		if den == 0 {
+			if dist > 0 {
+				return 0, false
+			}
+			continue
+		}
+
+		t := -dist / den
+		if den < 0 {
+			tMin = math.Max(tMin, t)
+		} else {
+			tMax = math.Min(tMax, t)
+		}
+
+		if tMin > tMax {
+			return 0, false
+		}
+	}
+
+	return tMin, true
+}
+
+func (h *ConvexHull3D) Volume() float64 {
+	vol := 0.0
+	origin := h.Points[h.Vertices[0]]
+
+	for _, f := range h.Faces {
+		a := h.Points[f[0]].SubVec(origin)
+		b := h.Points[f[1]].SubVec(origin)
+		c := h.Points[f[2]].SubVec(origin)
+
+		vol += a.Dot(b.CrossV(c))
+	}
+
+	return vol / 6
+}
@@ -0,0 +1,165 @@
+package golem
+
+import "math"
+
+// Parametric curve over the closed interval returned by Domain
+type Curve2D interface {
+	At(t float64) Vec2D
+	Derivative(t float64) Vec2D
+	SecondDerivative(t float64) Vec2D
+	Domain() (float64, float64)
+}
+
+type Curve3D interface {
+	At(t float64) Vec3D
+	Derivative(t float64) Vec3D
+	SecondDerivative(t float64) Vec3D
+	Domain() (float64, float64)
+}
+
+// samples per unit of domain used to seed closest point searches
+const curveSamples = 32
+
+func Tangent2D(c Curve2D, t float64) (Vec2D, error) {
+	d := c.Derivative(t)
+	if _, err := d.Normalize(); err != nil {
+		return Vec2D{}, err
+	}
+
+	return d, nil
+}
+
+func Tangent3D(c Curve3D, t float64) (Vec3D, error) {
+	d := c.Derivative(t)
+	if _, err := d.Normalize(); err != nil {
+		return Vec3D{}, err
+	}
+
+	return d, nil
+}
+
+// Signed, positive when the curve turns counter clockwise. Zero at
+// points where the curve stops
+func Curvature2D(c Curve2D, t float64) float64 {
+	d1 := c.Derivative(t)
+	d2 := c.SecondDerivative(t)
+
+	l := math.Hypot(d1.X, d1.Y)
+	if l == 0 {
+		return 0
+	}
+
+	return (d1.X*d2.Y - d1.Y*d2.X) / (l * l * l)
+}
+
+func Curvature3D(c Curve3D, t float64) float64 {
+	d1 := c.Derivative(t)
+	d2 := c.SecondDerivative(t)
+
+	l := d1.Length()
+	if l == 0 {
+		return 0
+	}
+
+	cross := d1.CrossV(d2)
+	return cross.Length() / (l * l * l)
+}
+
+// Samples the curve and polishes every local minimum of the distance with
+// Newton steps, returns the param and the point
+func ClosestPointOnCurve2D(c Curve2D, p Vec2D) (float64, Vec2D) {
+	lo, hi := c.Domain()
+	n := max(curveSamples, int(math.Ceil(hi-lo))*curveSamples)
+
+	dist := func(t float64) float64 {
+		return p.Dist(c.At(t))
+	}
+
+	best, bestDist := lo, dist(lo)
+
+	for _, t := range curveMinima(lo, hi, n, dist) {
+		for k := 0; k < 8; k++ {
+			q := c.At(t)
+			d1 := c.Derivative(t)
+			d2 := c.SecondDerivative(t)
+
+			diff := q.SubVec(p)
+			f := diff.Dot(d1)
+			df := d1.Dot(d1) + diff.Dot(d2)
+			if df <= 0 {
+				break
+			}
+
+			next := Clamp(t-f/df, lo, hi)
+			if math.Abs(next-t) < 1e-12 {
+				t = next
+				break
+			}
+			t = next
+		}
+
+		if d := dist(t); d < bestDist {
+			best, bestDist = t, d
+		}
+	}
+
+	return best, c.At(best)
+}
+
+func ClosestPointOnCurve3D(c Curve3D, p Vec3D) (float64, Vec3D) {
+	lo, hi := c.Domain()
+	n := max(curveSamples, int(math.Ceil(hi-lo))*curveSamples)
+
+	dist := func(t float64) float64 {
+		return p.Dist(c.At(t))
+	}
+
+	best, bestDist := lo, dist(lo)
+
+	for _, t := range curveMinima(lo, hi, n, dist) {
+		for k := 0; k < 8; k++ {
+			q := c.At(t)
+			d1 := c.Derivative(t)
+			d2 := c.SecondDerivative(t)
+
+			diff := q.SubVec(p)
+			f := diff.Dot(d1)
+			df := d1.Dot(d1) + diff.Dot(d2)
+			if df <= 0 {
+				break
+			}
+
+			next := Clamp(t-f/df, lo, hi)
+			if math.Abs(next-t) < 1e-12 {
+				t = next
+				break
+			}
+			t = next
+		}
+
+		if d := dist(t); d < bestDist {
+			best, bestDist = t, d
+		}
+	}
+
+	return best, c.At(best)
+}
+
+// params of the samples that are no farther than both neighbours
+func curveMinima(lo, hi float64, n int, dist func(float64) float64) []float64 {
+	ts := make([]float64, n+1)
+	ds := make([]float64, n+1)
+	for i := range ts {
+		ts[i] = lo + (hi-lo)*float64(i)/float64(n)
+		ds[i] = dist(ts[i])
+	}
+
+	out := make([]float64, 0)
+	for i := range ts {
+		if (i == 0 || ds[i] <= ds[i-1]) && (i == n || ds[i] <= ds[i+1]) {
+			out = append(out, ts[i])
+		}
+	}
+
+	return out
+}
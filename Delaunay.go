@@ -0,0 +1,675 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// Triangles are ccw, Neighbors[t][i] is the triangle across the edge
+// opposite to vertex i of triangle t or -1 on the hull
+type Delaunay struct {
+	Points    []Vec2D
+	Triangles [][3]int
+	Neighbors [][3]int
+
+	constrained map[[2]int]bool
+}
+
+type delaunayTri struct {
+	v     [3]int
+	n     [3]int
+	alive bool
+}
+
+type delaunayMesh struct {
+	pts  []Vec2D
+	tris []delaunayTri
+	last int
+}
+
+// Bowyer-Watson, exact duplicate points are skipped and are not part of any triangle
+func NewDelaunay(points []Vec2D) (*Delaunay, error) {
+	return NewConstrainedDelaunay(points, nil)
+}
+
+// Every constraint edge (pair of point indices) is forced into the triangulation,
+// the remaining edges are kept Delaunay. Constraints must not cross each other
+func NewConstrainedDelaunay(points []Vec2D, constraints [][2]int) (*Delaunay, error) {
+	if len(points) < 3 {
+		return nil, ErrInvalidLen
+	}
+
+	for _, c := range constraints {
+		if c[0] < 0 || c[1] < 0 || c[0] >= len(points) || c[1] >= len(points) || c[0] == c[1] {
+			return nil, ErrInvalidConstraint
+		}
+	}
+
+	mesh := newDelaunayMesh(points)
+
+	seen := make(map[Vec2D]int, len(points))
+	for i, p := range points {
+		if _, ok := seen[p]; ok {
+			continue
+		}
+
+		seen[p] = i
+		mesh.insert(i)
+	}
+
+	constrained := make(map[[2]int]bool, len(constraints))
+	for _, c := range constraints {
+		// duplicates map onto the point that was actually inserted
+		a := seen[points[c[0]]]
+		b := seen[points[c[1]]]
+		if a == b {
+			continue
+		}
+
+		if err := mesh.insertConstraint(a, b, constrained); err != nil {
+			return nil, err
+		}
+	}
+
+	mesh.removeSuper(len(points))
+	mesh.fillHull()
+	mesh.restoreDelaunay(constrained)
+
+	d := mesh.finish(len(points))
+	if len(d.Triangles) == 0 {
+		return nil, ErrTriangulation
+	}
+
+	d.constrained = constrained
+	return d, nil
+}
+
+func (d *Delaunay) IsConstrained(a, b int) bool {
+	return d.constrained[delaunayEdgeKey(a, b)]
+}
+
+// Unique edges with the smaller index first
+func (d *Delaunay) Edges() [][2]int {
+	out := make([][2]int, 0, len(d.Triangles)*3/2+1)
+
+	for t, tri := range d.Triangles {
+		for i := 0; i < 3; i++ {
+			a := tri[(i+1)%3]
+			b := tri[(i+2)%3]
+
+			// each interior edge is reported by the triangle with the smaller index
+			if nb := d.Neighbors[t][i]; nb == -1 || nb > t {
+				out = append(out, delaunayEdgeKey(a, b))
+			}
+		}
+	}
+
+	return out
+}
+
+// Adjacency list of the points, sorted by index
+func (d *Delaunay) VertexNeighbors() [][]int {
+	out := make([][]int, len(d.Points))
+
+	for _, e := range d.Edges() {
+		out[e[0]] = append(out[e[0]], e[1])
+		out[e[1]] = append(out[e[1]], e[0])
+	}
+
+	for _, n := range out {
+		sort.Ints(n)
+	}
+
+	return out
+}
+
+// Index of the triangle containing p or -1
+func (d *Delaunay) Locate(p Vec2D) int {
+	for t, tri := range d.Triangles {
+		if (Triangle2D{A: d.Points[tri[0]], B: d.Points[tri[1]], C: d.Points[tri[2]]}).ContainsPoint(p) {
+			return t
+		}
+	}
+
+	return -1
+}
+
+func newDelaunayMesh(points []Vec2D) *delaunayMesh {
+	min := points[0]
+	max := points[0]
+
+	for _, p := range points {
+		min.X = math.Min(min.X, p.X)
+		min.Y = math.Min(min.Y, p.Y)
+		max.X = math.Max(max.X, p.X)
+		max.Y = math.Max(max.Y, p.Y)
+	}
+
+	delta := math.Max(math.Max(max.X-min.X, max.Y-min.Y), 1)
+	cx := (min.X + max.X) / 2
+	cy := (min.Y + max.Y) / 2
+
+	n := len(points)
+	pts := make([]Vec2D, n, n+3)
+	copy(pts, points)
+
+	// super triangle enclosing every point
+	pts = append(pts,
+		Vec2D{X: cx - (20 * delta), Y: cy - delta},
+		Vec2D{X: cx + (20 * delta), Y: cy - delta},
+		Vec2D{X: cx, Y: cy + (20 * delta)},
+	)
+
+	return &delaunayMesh{
+		pts: pts,
+		tris: []delaunayTri{{
+			v:     [3]int{n, n + 1, n + 2},
+			n:     [3]int{-1, -1, -1},
+			alive: true,
+		}},
+	}
+}
+
+func (m *delaunayMesh) locate(p Vec2D) int {
+	t := m.last
+	if t < 0 || t >= len(m.tris) || !m.tris[t].alive {
+		t = -1
+	}
+
+	// walk towards p, bounded so degenerate cycles fall back to a scan
+	for steps := 0; t != -1 && steps < len(m.tris); steps++ {
+		tri := m.tris[t]
+		next := -1
+
+		for i := 0; i < 3; i++ {
+			a := m.pts[tri.v[(i+1)%3]]
+			b := m.pts[tri.v[(i+2)%3]]
+
+			if orient2D(a, b, p) < 0 {
+				next = tri.n[i]
+				break
+			}
+		}
+
+		if next == -1 {
+			return t
+		}
+
+		t = next
+	}
+
+	for i, tri := range m.tris {
+		if !tri.alive {
+			continue
+		}
+
+		if orient2D(m.pts[tri.v[0]], m.pts[tri.v[1]], p) >= 0 &&
+			orient2D(m.pts[tri.v[1]], m.pts[tri.v[2]], p) >= 0 &&
+			orient2D(m.pts[tri.v[2]], m.pts[tri.v[0]], p) >= 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (m *delaunayMesh) insert(pi int) {
+	p := m.pts[pi]
+
+	start := m.locate(p)
+	if start == -1 {
+		return
+	}
+
+	// flood fill the cavity of triangles whose circumcircle holds p
+	bad := map[int]bool{start: true}
+	stack := []int{start}
+	order := []int{start}
+
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, nb := range m.tris[t].n {
+			if nb == -1 || bad[nb] {
+				continue
+			}
+
+			v := m.tris[nb].v
+			if inCircumcircle(m.pts[v[0]], m.pts[v[1]], m.pts[v[2]], p) {
+				bad[nb] = true
+				stack = append(stack, nb)
+				order = append(order, nb)
+			}
+		}
+	}
+
+	byStart := make(map[int]int)
+	byEnd := make(map[int]int)
+	created := make([]int, 0)
+
+	for _, t := range order {
+		tri := m.tris[t]
+
+		for i := 0; i < 3; i++ {
+			nb := tri.n[i]
+			if nb != -1 && bad[nb] {
+				continue
+			}
+
+			a := tri.v[(i+1)%3]
+			b := tri.v[(i+2)%3]
+
+			idx := len(m.tris)
+			m.tris = append(m.tris, delaunayTri{
+				v:     [3]int{a, b, pi},
+				n:     [3]int{-1, -1, nb},
+				alive: true,
+			})
+
+			if nb != -1 {
+				m.replaceNeighbor(nb, t, idx)
+			}
+
+			byStart[a] = idx
+			byEnd[b] = idx
+			created = append(created, idx)
+		}
+	}
+
+	for _, idx := range created {
+		tri := &m.tris[idx]
+		tri.n[0] = byStart[tri.v[1]]
+		tri.n[1] = byEnd[tri.v[0]]
+	}
+
+	for _, t := range order {
+		m.tris[t].alive = false
+	}
+
+	m.last = created[len(created)-1]
+}
+
+func (m *delaunayMesh) replaceNeighbor(t, old, new int) {
+	for i := 0; i < 3; i++ {
+		if m.tris[t].n[i] == old {
+			m.tris[t].n[i] = new
+			return
+		}
+	}
+}
+
+// flips the edge opposite vertex i of t, the quad must be strictly convex
+func (m *delaunayMesh) flip(t, i int) {
+	o := m.tris[t].n[i]
+
+	j := 0
+	for m.tris[o].n[j] != t {
+		j++
+	}
+
+	tri := m.tris[t]
+	otr := m.tris[o]
+
+	p := tri.v[i]
+	q := tri.v[(i+1)%3]
+	r := tri.v[(i+2)%3]
+	s := otr.v[j]
+
+	nPQ := tri.n[(i+2)%3]
+	nRP := tri.n[(i+1)%3]
+	nQS := otr.n[(j+1)%3]
+	nSR := otr.n[(j+2)%3]
+
+	m.tris[t] = delaunayTri{v: [3]int{p, q, s}, n: [3]int{nQS, o, nPQ}, alive: true}
+	m.tris[o] = delaunayTri{v: [3]int{p, s, r}, n: [3]int{nSR, nRP, t}, alive: true}
+
+	if nQS != -1 {
+		m.replaceNeighbor(nQS, o, t)
+	}
+
+	if nRP != -1 {
+		m.replaceNeighbor(nRP, t, o)
+	}
+}
+
+func (m *delaunayMesh) findEdge(a, b int) (int, int) {
+	for t, tri := range m.tris {
+		if !tri.alive {
+			continue
+		}
+
+		for i := 0; i < 3; i++ {
+			u := tri.v[(i+1)%3]
+			v := tri.v[(i+2)%3]
+			if (u == a && v == b) || (u == b && v == a) {
+				return t, i
+			}
+		}
+	}
+
+	return -1, -1
+}
+
+func (m *delaunayMesh) crossesSegment(u, v, a, b int) bool {
+	if u == a || u == b || v == a || v == b {
+		return false
+	}
+
+	pa, pb := m.pts[a], m.pts[b]
+	pu, pv := m.pts[u], m.pts[v]
+
+	return orient2D(pa, pb, pu)*orient2D(pa, pb, pv) < 0 && orient2D(pu, pv, pa)*orient2D(pu, pv, pb) < 0
+}
+
+// Sloan's edge flipping, constraints through other points are split at them
+func (m *delaunayMesh) insertConstraint(a, b int, constrained map[[2]int]bool) error {
+	pa, pb := m.pts[a], m.pts[b]
+
+	for i := 0; i < len(m.pts)-3; i++ {
+		if i == a || i == b {
+			continue
+		}
+
+		p := m.pts[i]
+		if orient2D(pa, pb, p) != 0 {
+			continue
+		}
+
+		ab := pb.SubVec(pa)
+		ap := p.SubVec(pa)
+		if t := ap.Dot(ab) / ab.Dot(ab); t > 0 && t < 1 {
+			// skipped duplicates are not part of the mesh
+			if !m.isInserted(i) {
+				continue
+			}
+
+			if err := m.insertConstraint(a, i, constrained); err != nil {
+				return err
+			}
+			return m.insertConstraint(i, b, constrained)
+		}
+	}
+
+	if t, _ := m.findEdge(a, b); t != -1 {
+		constrained[delaunayEdgeKey(a, b)] = true
+		return nil
+	}
+
+	queue := make([][2]int, 0)
+	for _, tri := range m.tris {
+		if !tri.alive {
+			continue
+		}
+
+		for i := 0; i < 3; i++ {
+			u := tri.v[(i+1)%3]
+			v := tri.v[(i+2)%3]
+			if u < v && m.crossesSegment(u, v, a, b) {
+				if constrained[delaunayEdgeKey(u, v)] {
+					return ErrInvalidConstraint
+				}
+				queue = append(queue, [2]int{u, v})
+			}
+		}
+	}
+
+	for stall := 0; len(queue) > 0; {
+		if stall > len(queue)*len(queue)+8 {
+			return ErrTriangulation
+		}
+
+		e := queue[0]
+		queue = queue[1:]
+
+		t, i := m.findEdge(e[0], e[1])
+		if t == -1 || m.tris[t].n[i] == -1 {
+			return ErrTriangulation
+		}
+		o := m.tris[t].n[i]
+
+		tri := m.tris[t]
+		p := tri.v[i]
+		j := 0
+		for m.tris[o].n[j] != t {
+			j++
+		}
+		s := m.tris[o].v[j]
+
+		q := tri.v[(i+1)%3]
+		r := tri.v[(i+2)%3]
+
+		// p, q, s, r is the quad in ccw order
+		convex := orient2D(m.pts[p], m.pts[q], m.pts[s]) > 0 && orient2D(m.pts[s], m.pts[r], m.pts[p]) > 0
+		if !convex {
+			queue = append(queue, e)
+			stall++
+			continue
+		}
+
+		m.flip(t, i)
+		stall = 0
+
+		if m.crossesSegment(p, s, a, b) {
+			queue = append(queue, [2]int{p, s})
+		}
+	}
+
+	constrained[delaunayEdgeKey(a, b)] = true
+	return nil
+}
+
+func (m *delaunayMesh) isInserted(v int) bool {
+	for _, tri := range m.tris {
+		if tri.alive && (tri.v[0] == v || tri.v[1] == v || tri.v[2] == v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lawson flips on every non constrained edge until all are locally Delaunay
+func (m *delaunayMesh) restoreDelaunay(constrained map[[2]int]bool) {
+	for flipped := true; flipped; {
+		flipped = false
+
+		for t := range m.tris {
+			if !m.tris[t].alive {
+				continue
+			}
+
+			for i := 0; i < 3; i++ {
+				tri := m.tris[t]
+				o := tri.n[i]
+				if o == -1 {
+					continue
+				}
+
+				q := tri.v[(i+1)%3]
+				r := tri.v[(i+2)%3]
+				if constrained[delaunayEdgeKey(q, r)] {
+					continue
+				}
+
+				j := 0
+				for m.tris[o].n[j] != t {
+					j++
+				}
+				s := m.tris[o].v[j]
+				p := tri.v[i]
+
+				if !inCircumcircleStrict(m.pts[p], m.pts[q], m.pts[r], m.pts[s]) {
+					continue
+				}
+
+				if orient2D(m.pts[p], m.pts[q], m.pts[s]) > 0 && orient2D(m.pts[s], m.pts[r], m.pts[p]) > 0 {
+					m.flip(t, i)
+					flipped = true
+				}
+			}
+		}
+	}
+}
+
+func (m *delaunayMesh) removeSuper(n int) {
+	for t, tri := range m.tris {
+		if !tri.alive || (tri.v[0] < n && tri.v[1] < n && tri.v[2] < n) {
+			continue
+		}
+
+		m.tris[t].alive = false
+		for _, nb := range tri.n {
+			if nb != -1 {
+				m.replaceNeighbor(nb, t, -1)
+			}
+		}
+	}
+}
+
+// Removing the super triangle can leave concave pockets on the hull,
+// they are filled here and fixed up by the following Lawson flips
+func (m *delaunayMesh) fillHull() {
+	type boundaryEdge struct {
+		to, tri int
+	}
+
+	for filled := true; filled; {
+		filled = false
+
+		boundary := make(map[int]boundaryEdge)
+		for t, tri := range m.tris {
+			if !tri.alive {
+				continue
+			}
+
+			for i := 0; i < 3; i++ {
+				if tri.n[i] == -1 {
+					boundary[tri.v[(i+1)%3]] = boundaryEdge{to: tri.v[(i+2)%3], tri: t}
+				}
+			}
+		}
+
+		starts := make([]int, 0, len(boundary))
+		for a := range boundary {
+			starts = append(starts, a)
+		}
+		sort.Ints(starts)
+
+		for _, a := range starts {
+			ab, ok := boundary[a]
+			if !ok {
+				continue
+			}
+
+			bc, ok := boundary[ab.to]
+			if !ok || bc.to == a {
+				continue
+			}
+
+			b, c := ab.to, bc.to
+			if orient2D(m.pts[a], m.pts[b], m.pts[c]) >= 0 {
+				continue
+			}
+
+			idx := len(m.tris)
+			m.tris = append(m.tris, delaunayTri{
+				v:     [3]int{a, c, b},
+				n:     [3]int{bc.tri, ab.tri, -1},
+				alive: true,
+			})
+
+			m.setNeighborOnEdge(ab.tri, a, b, idx)
+			m.setNeighborOnEdge(bc.tri, b, c, idx)
+
+			delete(boundary, a)
+			delete(boundary, b)
+			filled = true
+		}
+	}
+}
+
+func (m *delaunayMesh) setNeighborOnEdge(t, a, b, nb int) {
+	tri := &m.tris[t]
+	for i := 0; i < 3; i++ {
+		if tri.v[(i+1)%3] == a && tri.v[(i+2)%3] == b {
+			tri.n[i] = nb
+			return
+		}
+	}
+}
+
+// compacts the alive triangles
+func (m *delaunayMesh) finish(n int) *Delaunay {
+	remap := make([]int, len(m.tris))
+	d := &Delaunay{Points: m.pts[:n:n]}
+
+	for t, tri := range m.tris {
+		remap[t] = -1
+		if !tri.alive || tri.v[0] >= n || tri.v[1] >= n || tri.v[2] >= n {
+			continue
+		}
+
+		remap[t] = len(d.Triangles)
+		d.Triangles = append(d.Triangles, tri.v)
+	}
+
+	for t, tri := range m.tris {
+		if remap[t] == -1 {
+			continue
+		}
+
+		nb := [3]int{-1, -1, -1}
+		for i := 0; i < 3; i++ {
+			if tri.n[i] != -1 {
+				nb[i] = remap[tri.n[i]]
+			}
+		}
+
+		d.Neighbors = append(d.Neighbors, nb)
+	}
+
+	return d
+}
+
+// a, b, c must be ccw
+func inCircumcircle(a, b, c, d Vec2D) bool {
+	det, _ := inCircleDet(a, b, c, d)
+	return det > 0
+}
+
+// same as inCircumcircle but cocircular points within rounding error are
+// treated as outside, so flipping cannot cycle on them
+func inCircumcircleStrict(a, b, c, d Vec2D) bool {
+	det, bound := inCircleDet(a, b, c, d)
+	return det > bound*1e-12
+}
+
+// returns the determinant and the permanent bounding its rounding error
+func inCircleDet(a, b, c, d Vec2D) (float64, float64) {
+	adx, ady := a.X-d.X, a.Y-d.Y
+	bdx, bdy := b.X-d.X, b.Y-d.Y
+	cdx, cdy := c.X-d.X, c.Y-d.Y
+
+	ad := (adx * adx) + (ady * ady)
+	bd := (bdx * bdx) + (bdy * bdy)
+	cd := (cdx * cdx) + (cdy * cdy)
+
+	det := (adx * ((bdy * cd) - (bd * cdy))) -
+		(ady * ((bdx * cd) - (bd * cdx))) +
+		(ad * ((bdx * cdy) - (bdy * cdx)))
+
+	perm := (math.Abs(adx) * (math.Abs(bdy*cd) + math.Abs(bd*cdy))) +
+		(math.Abs(ady) * (math.Abs(bdx*cd) + math.Abs(bd*cdx))) +
+		(ad * (math.Abs(bdx*cdy) + math.Abs(bdy*cdx)))
+
+	return det, perm
+}
+
+func delaunayEdgeKey(a, b int) [2]int {
+	if a > b {
+		return [2]int{b, a}
+	}
+
+	return [2]int{a, b}
+}
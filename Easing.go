@@ -0,0 +1,230 @@
+package golem
+
+import "math"
+
+// Maps progress in [0, 1] to eased progress, f(0) = 0 and f(1) = 1. Back
+// and elastic easing overshoot the range in between
+type EasingFunc func(t float64) float64
+
+const (
+	easeBack    = 1.70158
+	easeBackIO  = easeBack * 1.525
+	easeElastic = 2 * math.Pi / 3
+	easeElastIO = 2 * math.Pi / 4.5
+)
+
+func Linear(t float64) float64 {
+	return t
+}
+
+func EaseInQuad(t float64) float64 {
+	return t * t
+}
+
+func EaseOutQuad(t float64) float64 {
+	return easeOut(EaseInQuad, t)
+}
+
+func EaseInOutQuad(t float64) float64 {
+	return easeInOut(EaseInQuad, t)
+}
+
+func EaseInCubic(t float64) float64 {
+	return t * t * t
+}
+
+func EaseOutCubic(t float64) float64 {
+	return easeOut(EaseInCubic, t)
+}
+
+func EaseInOutCubic(t float64) float64 {
+	return easeInOut(EaseInCubic, t)
+}
+
+func EaseInQuart(t float64) float64 {
+	return t * t * t * t
+}
+
+func EaseOutQuart(t float64) float64 {
+	return easeOut(EaseInQuart, t)
+}
+
+func EaseInOutQuart(t float64) float64 {
+	return easeInOut(EaseInQuart, t)
+}
+
+func EaseInQuint(t float64) float64 {
+	return t * t * t * t * t
+}
+
+func EaseOutQuint(t float64) float64 {
+	return easeOut(EaseInQuint, t)
+}
+
+func EaseInOutQuint(t float64) float64 {
+	return easeInOut(EaseInQuint, t)
+}
+
+func EaseInSine(t float64) float64 {
+	return 1 - math.Cos(t*math.Pi/2)
+}
+
+func EaseOutSine(t float64) float64 {
+	return math.Sin(t * math.Pi / 2)
+}
+
+func EaseInOutSine(t float64) float64 {
+	return (1 - math.Cos(t*math.Pi)) / 2
+}
+
+func EaseInExpo(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+
+	return math.Pow(2, 10*t-10)
+}
+
+func EaseOutExpo(t float64) float64 {
+	return easeOut(EaseInExpo, t)
+}
+
+func EaseInOutExpo(t float64) float64 {
+	return easeInOut(EaseInExpo, t)
+}
+
+func EaseInCirc(t float64) float64 {
+	return 1 - math.Sqrt(1-Clamp(t*t, 0, 1))
+}
+
+func EaseOutCirc(t float64) float64 {
+	return easeOut(EaseInCirc, t)
+}
+
+func EaseInOutCirc(t float64) float64 {
+	return easeInOut(EaseInCirc, t)
+}
+
+// pulls back by about 10% before moving forward
+func EaseInBack(t float64) float64 {
+	return t * t * ((easeBack+1)*t - easeBack)
+}
+
+func EaseOutBack(t float64) float64 {
+	return easeOut(EaseInBack, t)
+}
+
+// uses a stronger overshoot so each half still pulls back by about 10%
+func EaseInOutBack(t float64) float64 {
+	return easeInOut(func(t float64) float64 {
+		return t * t * ((easeBackIO+1)*t - easeBackIO)
+	}, t)
+}
+
+func EaseInElastic(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return Clamp(t, 0, 1)
+	}
+
+	return -math.Pow(2, 10*t-10) * math.Sin((10*t-10.75)*easeElastic)
+}
+
+func EaseOutElastic(t float64) float64 {
+	return easeOut(EaseInElastic, t)
+}
+
+func EaseInOutElastic(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return Clamp(t, 0, 1)
+	}
+
+	s := math.Sin((20*t - 11.125) * easeElastIO)
+	if t < 0.5 {
+		return -math.Pow(2, 20*t-10) * s / 2
+	}
+
+	return math.Pow(2, -20*t+10)*s/2 + 1
+}
+
+func EaseInBounce(t float64) float64 {
+	return easeOut(EaseOutBounce, t)
+}
+
+func EaseOutBounce(t float64) float64 {
+	const n, d = 7.5625, 2.75
+
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+func EaseInOutBounce(t float64) float64 {
+	return easeInOut(EaseInBounce, t)
+}
+
+// Timing function like CSS cubic-bezier(x1, y1, x2, y2). x1 and x2 must be
+// in [0, 1] so the curve is a function of time
+func CubicBezierEasing(x1, y1, x2, y2 float64) (EasingFunc, error) {
+	if x1 < 0 || x1 > 1 || x2 < 0 || x2 > 1 {
+		return nil, ErrInvalidInterPolParam
+	}
+
+	c := CubicBezier2D{P1: Vec2D{X: x1, Y: y1}, P2: Vec2D{X: x2, Y: y2}, P3: Vec2D{X: 1, Y: 1}}
+
+	return func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return Clamp(t, 0, 1)
+		}
+
+		return c.At(bezierParamAtX(c, t)).Y
+	}, nil
+}
+
+// Param where a Bezier whose x rises from 0 to 1 reaches x. Newton with a
+// bisection fallback keeps it bracketed where the slope vanishes
+func bezierParamAtX(c CubicBezier2D, x float64) float64 {
+	lo, hi, s := 0.0, 1.0, Clamp(x, 0, 1)
+
+	for k := 0; k < 32; k++ {
+		f := c.At(s).X - x
+		if math.Abs(f) < 1e-12 {
+			break
+		}
+
+		if f > 0 {
+			hi = s
+		} else {
+			lo = s
+		}
+
+		next := s - f/c.Derivative(s).X
+		if !(next > lo && next < hi) {
+			next = (lo + hi) / 2
+		}
+		s = next
+	}
+
+	return s
+}
+
+func easeOut(in EasingFunc, t float64) float64 {
+	return 1 - in(1-t)
+}
+
+func easeInOut(in EasingFunc, t float64) float64 {
+	if t < 0.5 {
+		return in(2*t) / 2
+	}
+
+	return 1 - in(2-2*t)/2
+}
@@ -0,0 +1,132 @@
+package golem
+
+import "math"
+
+// Orthonormal frame on a curve with Binormal = Tangent x Normal
+type Frame3D struct {
+	Position Vec3D
+	Tangent  Vec3D
+	Normal   Vec3D
+	Binormal Vec3D
+}
+
+// Rotation taking local -Z to the tangent, +Y to the normal and +X to the
+// binormal, the same axes a camera from NewFrustum uses
+func (f Frame3D) RotMat3D() RotMat3D {
+	r := RotMat3D{Order: QtSet}
+
+	cols := [3]Vec3D{f.Binormal, f.Normal, f.Tangent.ScalerMulVec(-1)}
+	for j, c := range cols {
+		r.Mat3D[0][j] = c.X
+		r.Mat3D[1][j] = c.Y
+		r.Mat3D[2][j] = c.Z
+	}
+
+	return r
+}
+
+func (f Frame3D) Quaternion() Quaternion {
+	return f.RotMat3D().ToQuaternion()
+}
+
+// Normal points to the center of curvature, so the frame flips at
+// inflections and is undefined where the curve is straight
+func FrenetFrame3D(c Curve3D, t float64) (Frame3D, error) {
+	tan, err := Tangent3D(c, t)
+	if err != nil {
+		return Frame3D{}, err
+	}
+
+	d2 := c.SecondDerivative(t)
+	n := d2.SubVec(tan.ScalerMulVec(tan.Dot(d2)))
+
+	if l := n.Length(); l <= 1e-12*(1+d2.Length()) {
+		return Frame3D{}, ErrUndefinedFrame
+	}
+	n.Normalize()
+
+	return Frame3D{
+		Position: c.At(t),
+		Tangent:  tan,
+		Normal:   n,
+		Binormal: tan.CrossV(n),
+	}, nil
+}
+
+// Rotation minimizing frames at the given params by the double reflection
+// method. The first normal is up made perpendicular to the first tangent,
+// any perpendicular is used if they are parallel
+func ParallelTransportFrames3D(c Curve3D, params []float64, up Vec3D) ([]Frame3D, error) {
+	out := make([]Frame3D, len(params))
+
+	for i, t := range params {
+		tan, err := Tangent3D(c, t)
+		if err != nil {
+			return nil, err
+		}
+
+		out[i] = Frame3D{Position: c.At(t), Tangent: tan}
+	}
+
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	t0 := out[0].Tangent
+	n := up.SubVec(t0.ScalerMulVec(t0.Dot(up)))
+	if n.Length() < 1e-6*(1+up.Length()) {
+		n = anyPerpendicular(t0)
+	} else {
+		n.Normalize()
+	}
+	out[0].Normal = n
+
+	for i := 1; i < len(out); i++ {
+		prev := out[i-1]
+		r := prev.Normal
+		tl := prev.Tangent
+
+		// reflect across the bisector of the two positions
+		v1 := out[i].Position.SubVec(prev.Position)
+		if c1 := v1.Dot(v1); c1 > 0 {
+			r = r.SubVec(v1.ScalerMulVec(2 / c1 * v1.Dot(r)))
+			tl = tl.SubVec(v1.ScalerMulVec(2 / c1 * v1.Dot(tl)))
+		}
+
+		// then across the bisector of the reflected and the new tangent
+		v2 := out[i].Tangent.SubVec(tl)
+		if c2 := v2.Dot(v2); c2 > 0 {
+			r = r.SubVec(v2.ScalerMulVec(2 / c2 * v2.Dot(r)))
+		}
+
+		// drop the rounding drift
+		tan := out[i].Tangent
+		r = r.SubVec(tan.ScalerMulVec(tan.Dot(r)))
+		if _, err := r.Normalize(); err != nil {
+			r = anyPerpendicular(tan)
+		}
+		out[i].Normal = r
+	}
+
+	for i := range out {
+		out[i].Binormal = out[i].Tangent.CrossV(out[i].Normal)
+	}
+
+	return out, nil
+}
+
+// unit vector perpendicular to the unit vector v
+func anyPerpendicular(v Vec3D) Vec3D {
+	axis := Vec3D{X: 1}
+	if math.Abs(v.X) > math.Abs(v.Y) || math.Abs(v.X) > math.Abs(v.Z) {
+		axis = Vec3D{Y: 1}
+		if math.Abs(v.Y) > math.Abs(v.Z) {
+			axis = Vec3D{Z: 1}
+		}
+	}
+
+	p := v.CrossV(axis)
+	p.Normalize()
+
+	return p
+}
@@ -0,0 +1,207 @@
+package golem
+
+import "math"
+
+const (
+	FrustumLeft = iota
+	FrustumRight
+	FrustumBottom
+	FrustumTop
+	FrustumNear
+	FrustumFar
+)
+
+type CullResult int
+
+const (
+	CullOutside CullResult = iota
+	CullIntersect
+	CullInside
+)
+
+// Six planes with unit normals pointing inwards, indexed by FrustumLeft
+// through FrustumFar. Planes[:] can be passed to BVH.QueryFrustum
+type Frustum struct {
+	Planes [6]Plane
+}
+
+// Perspective camera at pos looking down its local -Z with +Y up. fovY is
+// the full vertical angle in radians and aspect is width over height
+func NewFrustum(pos Vec3D, rot Quaternion, fovY, aspect, near, far float64) (Frustum, error) {
+	if fovY <= 0 || fovY >= math.Pi || aspect <= 0 || near <= 0 || far <= near {
+		return Frustum{}, ErrInvalidProjection
+	}
+
+	if _, err := rot.Normalize(); err != nil {
+		return Frustum{}, err
+	}
+	r := rot.ToRotMat3D()
+
+	right := r.RotateVec3D(Vec3D{X: 1})
+	up := r.RotateVec3D(Vec3D{Y: 1})
+	forward := r.RotateVec3D(Vec3D{Z: -1})
+
+	tanY := math.Tan(fovY / 2)
+	tanX := tanY * aspect
+
+	f := Frustum{}
+	normals := [4]Vec3D{
+		right.AddVec(forward.ScalerMulVec(tanX)),
+		right.ScalerMulVec(-1).AddVec(forward.ScalerMulVec(tanX)),
+		up.AddVec(forward.ScalerMulVec(tanY)),
+		up.ScalerMulVec(-1).AddVec(forward.ScalerMulVec(tanY)),
+	}
+
+	for i, n := range normals {
+		p, err := NewPlane(n, pos)
+		if err != nil {
+			return Frustum{}, err
+		}
+		f.Planes[i] = p
+	}
+
+	back := forward.ScalerMulVec(-1)
+	f.Planes[FrustumNear], _ = NewPlane(forward, pos.AddVec(forward.ScalerMulVec(near)))
+	f.Planes[FrustumFar], _ = NewPlane(back, pos.AddVec(forward.ScalerMulVec(far)))
+
+	return f, nil
+}
+
+// Gribb-Hartmann extraction from a projection or view-projection matrix
+// with OpenGL clip space, -w <= x, y, z <= w
+func NewFrustumFromMat4D(m Mat4D) (Frustum, error) {
+	f := Frustum{}
+
+	for i := 0; i < 3; i++ {
+		for k, sign := range [2]float64{1, -1} {
+			p := Plane{
+				Normal: Vec3D{
+					X: m[3][0] + sign*m[i][0],
+					Y: m[3][1] + sign*m[i][1],
+					Z: m[3][2] + sign*m[i][2],
+				},
+				D: m[3][3] + sign*m[i][3],
+			}
+
+			if err := p.Normalize(); err != nil {
+				return Frustum{}, ErrInvalidProjection
+			}
+			f.Planes[2*i+k] = p
+		}
+	}
+
+	return f, nil
+}
+
+func (f Frustum) ClassifyPoint(p Vec3D) CullResult {
+	out := CullInside
+	for _, pl := range f.Planes {
+		d := pl.SignedDistance(p)
+		if d < 0 {
+			return CullOutside
+		}
+		if d == 0 {
+			out = CullIntersect
+		}
+	}
+
+	return out
+}
+
+func (f Frustum) ClassifySphere(s Sphere) CullResult {
+	out := CullInside
+	for _, pl := range f.Planes {
+		d := pl.SignedDistance(s.Center)
+		if d < -s.Radius {
+			return CullOutside
+		}
+		if d < s.Radius {
+			out = CullIntersect
+		}
+	}
+
+	return out
+}
+
+// Boxes near the edges of the frustum may be reported as intersecting
+// while lying just outside, as usual for plane tests
+func (f Frustum) ClassifyAABB(b AABB3D) CullResult {
+	c := b.Center()
+	e := b.Extents()
+
+	out := CullInside
+	for _, pl := range f.Planes {
+		n := pl.Normal
+		r := math.Abs(n.X)*e.X + math.Abs(n.Y)*e.Y + math.Abs(n.Z)*e.Z
+		d := pl.SignedDistance(c)
+
+		if d < -r {
+			return CullOutside
+		}
+		if d < r {
+			out = CullIntersect
+		}
+	}
+
+	return out
+}
+
+func (f Frustum) ClassifyOBB(o OBB) CullResult {
+	out := CullInside
+	for _, pl := range f.Planes {
+		r := o.ProjectedRadius(pl.Normal)
+		d := pl.SignedDistance(o.Center)
+
+		if d < -r {
+			return CullOutside
+		}
+		if d < r {
+			out = CullIntersect
+		}
+	}
+
+	return out
+}
+
+// Near corners then far corners, each as bottom left, bottom right,
+// top right, top left seen from the camera
+func (f Frustum) Corners() [8]Vec3D {
+	var out [8]Vec3D
+
+	sides := [4][2]int{
+		{FrustumLeft, FrustumBottom},
+		{FrustumRight, FrustumBottom},
+		{FrustumRight, FrustumTop},
+		{FrustumLeft, FrustumTop},
+	}
+
+	for i, s := range sides {
+		out[i] = intersectPlanes(f.Planes[s[0]], f.Planes[s[1]], f.Planes[FrustumNear])
+		out[i+4] = intersectPlanes(f.Planes[s[0]], f.Planes[s[1]], f.Planes[FrustumFar])
+	}
+
+	return out
+}
+
+// box around the corners, handy for fitting shadow map projections
+func (f Frustum) Bounds() AABB3D {
+	c := f.Corners()
+	b, _ := NewAABB3DFromPoints(c[:])
+
+	return b
+}
+
+// point shared by three planes, the planes must not share a direction
+func intersectPlanes(a, b, c Plane) Vec3D {
+	bc := b.Normal.CrossV(c.Normal)
+	ca := c.Normal.CrossV(a.Normal)
+	ab := a.Normal.CrossV(b.Normal)
+
+	den := a.Normal.Dot(bc)
+
+	p := bc.ScalerMulVec(-a.D)
+	p.Add(ca.ScalerMulVec(-b.D))
+	p.Add(ab.ScalerMulVec(-c.D))
+
+	return p.ScalerMulVec(1 / den)
+}
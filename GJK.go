@@ -0,0 +1,146 @@
+package golem
+
+// Any convex volume that can report its farthest point along a direction
+type ConvexShape3D interface {
+	Support(dir Vec3D) Vec3D
+}
+
+const gjkMaxIterations = 64
+
+func (t Triangle3D) Support(dir Vec3D) Vec3D {
+	best := t.A
+	bestDot := dir.Dot(t.A)
+
+	if d := dir.Dot(t.B); d > bestDot {
+		best = t.B
+		bestDot = d
+	}
+
+	if d := dir.Dot(t.C); d > bestDot {
+		best = t.C
+	}
+
+	return best
+}
+
+// Gilbert-Johnson-Keerthi on the Minkowski difference a - b.
+// Touching shapes count as intersecting
+func GJKIntersect(a, b ConvexShape3D) bool {
+	dir := Vec3D{X: 1, Y: 0, Z: 0}
+
+	simplex := make([]Vec3D, 0, 4)
+	simplex = append(simplex, minkowskiSupport(a, b, dir))
+
+	dir = simplex[0]
+	dir.Reverse()
+
+	for i := 0; i < gjkMaxIterations; i++ {
+		if dir.Dot(dir) < 1e-24 {
+			return true
+		}
+
+		p := minkowskiSupport(a, b, dir)
+		if p.Dot(dir) < 0 {
+			return false
+		}
+
+		simplex = append(simplex, p)
+
+		var hit bool
+		simplex, dir, hit = gjkSimplex(simplex)
+		if hit {
+			return true
+		}
+	}
+
+	return false
+}
+
+func minkowskiSupport(a, b ConvexShape3D, dir Vec3D) Vec3D {
+	neg := dir
+	neg.Reverse()
+
+	return a.Support(dir).SubVec(b.Support(neg))
+}
+
+// reduces the simplex to the feature closest to the origin, the newest
+// point is always last. Returns the new search direction
+func gjkSimplex(s []Vec3D) ([]Vec3D, Vec3D, bool) {
+	switch len(s) {
+	case 2:
+		return gjkLine(s[1], s[0])
+
+	case 3:
+		return gjkTriangle(s[2], s[1], s[0])
+
+	default:
+		return gjkTetrahedron(s[3], s[2], s[1], s[0])
+	}
+}
+
+func gjkLine(a, b Vec3D) ([]Vec3D, Vec3D, bool) {
+	ab := b.SubVec(a)
+	ao := a
+	ao.Reverse()
+
+	if ab.Dot(ao) > 0 {
+		return []Vec3D{b, a}, ab.CrossV(ao).CrossV(ab), false
+	}
+
+	return []Vec3D{a}, ao, false
+}
+
+func gjkTriangle(a, b, c Vec3D) ([]Vec3D, Vec3D, bool) {
+	ab := b.SubVec(a)
+	ac := c.SubVec(a)
+	ao := a
+	ao.Reverse()
+
+	abc := ab.CrossV(ac)
+	abcPerp := abc.CrossV(ac)
+	abPerp := ab.CrossV(abc)
+
+	if abcPerp.Dot(ao) > 0 {
+		if ac.Dot(ao) > 0 {
+			return []Vec3D{c, a}, ac.CrossV(ao).CrossV(ac), false
+		}
+		return gjkLine(a, b)
+	}
+
+	if abPerp.Dot(ao) > 0 {
+		return gjkLine(a, b)
+	}
+
+	if abc.Dot(ao) > 0 {
+		return []Vec3D{c, b, a}, abc, false
+	}
+
+	// origin lies in the plane of the triangle
+	if abc.Dot(ao) == 0 {
+		return []Vec3D{c, b, a}, Vec3D{}, true
+	}
+
+	abc.Reverse()
+	return []Vec3D{b, c, a}, abc, false
+}
+
+func gjkTetrahedron(a, b, c, d Vec3D) ([]Vec3D, Vec3D, bool) {
+	ao := a
+	ao.Reverse()
+
+	faces := [3][3]Vec3D{{a, b, c}, {a, c, d}, {a, d, b}}
+	opposite := [3]Vec3D{d, b, c}
+
+	for i, f := range faces {
+		n := f[1].SubVec(f[0]).CrossV(f[2].SubVec(f[0]))
+		if n.Dot(opposite[i].SubVec(f[0])) > 0 {
+			n.Reverse()
+		}
+
+		if n.Dot(ao) > 0 {
+			return gjkTriangle(f[0], f[1], f[2])
+		}
+	}
+
+	return []Vec3D{d, c, b, a}, Vec3D{}, true
+}
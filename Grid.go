@@ -0,0 +1,263 @@
+package golem
+
+import "math"
+
+// Uniform grid anchored at Origin, cell (i, j) spans
+// Origin + (i, j) * CellSize up to the next cell
+type Grid2D struct {
+	Origin   Vec2D
+	CellSize Vec2D
+}
+
+type Grid3D struct {
+	Origin   Vec3D
+	CellSize Vec3D
+}
+
+// Cell entered by a traversal, T is the ray param where the ray enters it
+// and Normal the face it crossed. The start cell has T 0 and no normal
+type VoxelHit struct {
+	Cell   [3]int
+	T      float64
+	Normal Vec3D
+}
+
+func NewGrid2D(origin, cellSize Vec2D) (Grid2D, error) {
+	if cellSize.X <= 0 || cellSize.Y <= 0 {
+		return Grid2D{}, ErrInvalidCellSize
+	}
+
+	return Grid2D{Origin: origin, CellSize: cellSize}, nil
+}
+
+func (g Grid2D) Cell(p Vec2D) [2]int {
+	return [2]int{
+		int(math.Floor((p.X - g.Origin.X) / g.CellSize.X)),
+		int(math.Floor((p.Y - g.Origin.Y) / g.CellSize.Y)),
+	}
+}
+
+func (g Grid2D) CellBounds(c [2]int) AABB2D {
+	min := Vec2D{
+		X: g.Origin.X + float64(c[0])*g.CellSize.X,
+		Y: g.Origin.Y + float64(c[1])*g.CellSize.Y,
+	}
+
+	return AABB2D{Min: min, Max: min.AddVec(g.CellSize)}
+}
+
+// Samples the segment once per cell along its major axis, the cells are
+// 8-connected like a Bresenham line between real endpoints
+func (g Grid2D) DDALine(a, b Vec2D) [][2]int {
+	u := g.local(a)
+	v := g.local(b)
+	d := v.SubVec(u)
+
+	steps := int(math.Ceil(math.Max(math.Abs(d.X), math.Abs(d.Y))))
+	out := [][2]int{g.Cell(a)}
+
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		c := [2]int{
+			int(math.Floor(u.X + d.X*t)),
+			int(math.Floor(u.Y + d.Y*t)),
+		}
+
+		if c != out[len(out)-1] {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+// Every cell the segment touches in order from a to b. When the segment
+// passes exactly through a corner both side cells are included
+func (g Grid2D) SupercoverLine(a, b Vec2D) [][2]int {
+	u := g.local(a)
+	v := g.local(b)
+
+	cell := [2]int{int(math.Floor(u.X)), int(math.Floor(u.Y))}
+	end := [2]int{int(math.Floor(v.X)), int(math.Floor(v.Y))}
+
+	sx, tMaxX, tDeltaX := ddaAxis(u.X, v.X-u.X, cell[0])
+	sy, tMaxY, tDeltaY := ddaAxis(u.Y, v.Y-u.Y, cell[1])
+
+	out := [][2]int{cell}
+	n := absInt(end[0]-cell[0]) + absInt(end[1]-cell[1])
+
+	for moved := 0; moved < n; {
+		switch {
+		case math.Abs(tMaxX-tMaxY) <= 1e-12 && cell[0] != end[0] && cell[1] != end[1]:
+			out = append(out, [2]int{cell[0] + sx, cell[1]}, [2]int{cell[0], cell[1] + sy})
+			cell[0] += sx
+			cell[1] += sy
+			tMaxX += tDeltaX
+			tMaxY += tDeltaY
+			moved += 2
+
+		case tMaxX < tMaxY:
+			cell[0] += sx
+			tMaxX += tDeltaX
+			moved++
+
+		default:
+			cell[1] += sy
+			tMaxY += tDeltaY
+			moved++
+		}
+
+		out = append(out, cell)
+	}
+
+	return out
+}
+
+func (g Grid2D) local(p Vec2D) Vec2D {
+	return Vec2D{
+		X: (p.X - g.Origin.X) / g.CellSize.X,
+		Y: (p.Y - g.Origin.Y) / g.CellSize.Y,
+	}
+}
+
+// Integer line between two cells, 8-connected and symmetric
+func BresenhamLine(x0, y0, x1, y1 int) [][2]int {
+	dx := absInt(x1 - x0)
+	dy := -absInt(y1 - y0)
+
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	out := make([][2]int, 0, max(dx, -dy)+1)
+	e := dx + dy
+
+	for {
+		out = append(out, [2]int{x0, y0})
+		if x0 == x1 && y0 == y1 {
+			return out
+		}
+
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func NewGrid3D(origin, cellSize Vec3D) (Grid3D, error) {
+	if cellSize.X <= 0 || cellSize.Y <= 0 || cellSize.Z <= 0 {
+		return Grid3D{}, ErrInvalidCellSize
+	}
+
+	return Grid3D{Origin: origin, CellSize: cellSize}, nil
+}
+
+func (g Grid3D) Cell(p Vec3D) [3]int {
+	return [3]int{
+		int(math.Floor((p.X - g.Origin.X) / g.CellSize.X)),
+		int(math.Floor((p.Y - g.Origin.Y) / g.CellSize.Y)),
+		int(math.Floor((p.Z - g.Origin.Z) / g.CellSize.Z)),
+	}
+}
+
+func (g Grid3D) CellBounds(c [3]int) AABB3D {
+	min := Vec3D{
+		X: g.Origin.X + float64(c[0])*g.CellSize.X,
+		Y: g.Origin.Y + float64(c[1])*g.CellSize.Y,
+		Z: g.Origin.Z + float64(c[2])*g.CellSize.Z,
+	}
+
+	return AABB3D{Min: min, Max: min.AddVec(g.CellSize)}
+}
+
+// Amanatides-Woo traversal. Cells are visited in order along the ray up to
+// maxT, visit returns false to stop early
+func (g Grid3D) Traverse(r Ray3D, maxT float64, visit func(VoxelHit) bool) {
+	hit := VoxelHit{Cell: g.Cell(r.Origin)}
+
+	var step [3]int
+	var tMax, tDelta [3]float64
+
+	for a := 0; a < 3; a++ {
+		size := vecAxis(g.CellSize, a)
+		o := (vecAxis(r.Origin, a) - vecAxis(g.Origin, a)) / size
+		d := vecAxis(r.Dir, a) / size
+
+		step[a], tMax[a], tDelta[a] = ddaAxis(o, d, hit.Cell[a])
+	}
+
+	for {
+		if !visit(hit) {
+			return
+		}
+
+		a := 0
+		if tMax[1] < tMax[a] {
+			a = 1
+		}
+		if tMax[2] < tMax[a] {
+			a = 2
+		}
+
+		if tMax[a] > maxT || math.IsInf(tMax[a], 1) {
+			return
+		}
+
+		hit.Cell[a] += step[a]
+		hit.T = tMax[a]
+		hit.Normal = Vec3D{}
+
+		switch a {
+		case 0:
+			hit.Normal.X = -float64(step[a])
+		case 1:
+			hit.Normal.Y = -float64(step[a])
+		default:
+			hit.Normal.Z = -float64(step[a])
+		}
+
+		tMax[a] += tDelta[a]
+	}
+}
+
+// Every cell the ray passes within maxT, maxT must be finite
+func (g Grid3D) TraverseAll(r Ray3D, maxT float64) []VoxelHit {
+	out := make([]VoxelHit, 0)
+	g.Traverse(r, maxT, func(h VoxelHit) bool {
+		out = append(out, h)
+		return true
+	})
+
+	return out
+}
+
+// step direction, param of the first boundary and param between boundaries
+// along one axis in cell units. A zero direction never crosses
+func ddaAxis(o, d float64, cell int) (int, float64, float64) {
+	switch {
+	case d > 0:
+		return 1, (float64(cell+1) - o) / d, 1 / d
+	case d < 0:
+		return -1, (float64(cell) - o) / d, -1 / d
+	default:
+		return 0, math.Inf(1), math.Inf(1)
+	}
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
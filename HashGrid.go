@@ -0,0 +1,475 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// Uniform grid over the whole plane, only occupied cells are stored in a
+// map keyed by the cell coordinates. Objects are added to every cell their
+// box touches so it works best for many objects of a similar size. Boxes
+// covering more than hashGridMaxCells cells, like a ground plane, are kept
+// in a list checked by every query instead
+type HashGrid2D struct {
+	CellSize float64
+
+	cells    map[[2]int][]int
+	items    map[int]*hashItem2D
+	overflow []int
+}
+
+type hashItem2D struct {
+	box    AABB2D
+	lo, hi [2]int
+}
+
+type HashGrid3D struct {
+	CellSize float64
+
+	cells    map[[3]int][]int
+	items    map[int]*hashItem3D
+	overflow []int
+}
+
+type hashItem3D struct {
+	box    AABB3D
+	lo, hi [3]int
+}
+
+// most cells an object is added to before it goes in the overflow list
+const hashGridMaxCells = 64
+
+func NewHashGrid2D(cellSize float64) (*HashGrid2D, error) {
+	if cellSize <= 0 {
+		return nil, ErrInvalidCellSize
+	}
+
+	return &HashGrid2D{
+		CellSize: cellSize,
+		cells:    make(map[[2]int][]int),
+		items:    make(map[int]*hashItem2D),
+	}, nil
+}
+
+// coordinates of the cell holding p
+func (g *HashGrid2D) Cell(p Vec2D) [2]int {
+	return [2]int{
+		int(math.Floor(p.X / g.CellSize)),
+		int(math.Floor(p.Y / g.CellSize)),
+	}
+}
+
+func (g *HashGrid2D) Len() int {
+	return len(g.items)
+}
+
+func (g *HashGrid2D) Bounds(id int) (AABB2D, bool) {
+	it, ok := g.items[id]
+	if !ok {
+		return AABB2D{}, false
+	}
+
+	return it.box, true
+}
+
+func (g *HashGrid2D) Insert(id int, box AABB2D) error {
+	if _, ok := g.items[id]; ok {
+		return ErrDuplicateID
+	}
+
+	it := &hashItem2D{box: box, lo: g.Cell(box.Min), hi: g.Cell(box.Max)}
+	g.items[id] = it
+	g.link(id, it.lo, it.hi)
+
+	return nil
+}
+
+func (g *HashGrid2D) Remove(id int) error {
+	it, ok := g.items[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	delete(g.items, id)
+	g.unlink(id, it.lo, it.hi)
+
+	return nil
+}
+
+// Only touches the cells when the covered range changes
+func (g *HashGrid2D) Move(id int, box AABB2D) error {
+	it, ok := g.items[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	it.box = box
+
+	lo, hi := g.Cell(box.Min), g.Cell(box.Max)
+	if lo == it.lo && hi == it.hi {
+		return nil
+	}
+
+	g.unlink(id, it.lo, it.hi)
+	it.lo, it.hi = lo, hi
+	g.link(id, lo, hi)
+
+	return nil
+}
+
+func hashCells2D(lo, hi [2]int) float64 {
+	return float64(hi[0]-lo[0]+1) * float64(hi[1]-lo[1]+1)
+}
+
+func (g *HashGrid2D) link(id int, lo, hi [2]int) {
+	if hashCells2D(lo, hi) > hashGridMaxCells {
+		g.overflow = append(g.overflow, id)
+		return
+	}
+
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			k := [2]int{x, y}
+			g.cells[k] = append(g.cells[k], id)
+		}
+	}
+}
+
+func (g *HashGrid2D) unlink(id int, lo, hi [2]int) {
+	if hashCells2D(lo, hi) > hashGridMaxCells {
+		g.overflow = removeID(g.overflow, id)
+		return
+	}
+
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			k := [2]int{x, y}
+			g.cells[k] = removeID(g.cells[k], id)
+			if len(g.cells[k]) == 0 {
+				delete(g.cells, k)
+			}
+		}
+	}
+}
+
+// ids of the objects whose boxes overlap box
+func (g *HashGrid2D) QueryRect(box AABB2D) []int {
+	return g.query(box, func(b AABB2D) bool {
+		return b.Intersects(box)
+	})
+}
+
+func (g *HashGrid2D) QueryCircle(c Circle) []int {
+	return g.query(c.Bounds(), func(b AABB2D) bool {
+		return b.IntersectsCircle(c)
+	})
+}
+
+// ids in increasing order whichever way they were found
+func (g *HashGrid2D) query(area AABB2D, overlaps func(AABB2D) bool) []int {
+	out := make([]int, 0)
+
+	lo, hi := g.Cell(area.Min), g.Cell(area.Max)
+
+	// scanning the objects is cheaper than visiting a huge range of cells
+	if hashCells2D(lo, hi) > float64(len(g.items)) {
+		for id, it := range g.items {
+			if overlaps(it.box) {
+				out = append(out, id)
+			}
+		}
+		sort.Ints(out)
+		return out
+	}
+
+	for _, id := range g.overflow {
+		if overlaps(g.items[id].box) {
+			out = append(out, id)
+		}
+	}
+
+	seen := make(map[int]bool)
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for _, id := range g.cells[[2]int{x, y}] {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+
+				if overlaps(g.items[id].box) {
+					out = append(out, id)
+				}
+			}
+		}
+	}
+
+	sort.Ints(out)
+	return out
+}
+
+// Every pair of overlapping objects once with the smaller id first, sorted
+func (g *HashGrid2D) Pairs() [][2]int {
+	found := make(map[[2]int]bool)
+
+	for _, ids := range g.cells {
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				p := [2]int{min(a, b), max(a, b)}
+				if found[p] {
+					continue
+				}
+
+				if g.items[a].box.Intersects(g.items[b].box) {
+					found[p] = true
+				}
+			}
+		}
+	}
+
+	// the big boxes are in no cell, test them against everything
+	for _, a := range g.overflow {
+		for b, it := range g.items {
+			if a != b && g.items[a].box.Intersects(it.box) {
+				found[[2]int{min(a, b), max(a, b)}] = true
+			}
+		}
+	}
+
+	return sortedPairs(found)
+}
+
+func NewHashGrid3D(cellSize float64) (*HashGrid3D, error) {
+	if cellSize <= 0 {
+		return nil, ErrInvalidCellSize
+	}
+
+	return &HashGrid3D{
+		CellSize: cellSize,
+		cells:    make(map[[3]int][]int),
+		items:    make(map[int]*hashItem3D),
+	}, nil
+}
+
+// coordinates of the cell holding p
+func (g *HashGrid3D) Cell(p Vec3D) [3]int {
+	return [3]int{
+		int(math.Floor(p.X / g.CellSize)),
+		int(math.Floor(p.Y / g.CellSize)),
+		int(math.Floor(p.Z / g.CellSize)),
+	}
+}
+
+func (g *HashGrid3D) Len() int {
+	return len(g.items)
+}
+
+func (g *HashGrid3D) Bounds(id int) (AABB3D, bool) {
+	it, ok := g.items[id]
+	if !ok {
+		return AABB3D{}, false
+	}
+
+	return it.box, true
+}
+
+func (g *HashGrid3D) Insert(id int, box AABB3D) error {
+	if _, ok := g.items[id]; ok {
+		return ErrDuplicateID
+	}
+
+	it := &hashItem3D{box: box, lo: g.Cell(box.Min), hi: g.Cell(box.Max)}
+	g.items[id] = it
+	g.link(id, it.lo, it.hi)
+
+	return nil
+}
+
+func (g *HashGrid3D) Remove(id int) error {
+	it, ok := g.items[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	delete(g.items, id)
+	g.unlink(id, it.lo, it.hi)
+
+	return nil
+}
+
+// Only touches the cells when the covered range changes
+func (g *HashGrid3D) Move(id int, box AABB3D) error {
+	it, ok := g.items[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	it.box = box
+
+	lo, hi := g.Cell(box.Min), g.Cell(box.Max)
+	if lo == it.lo && hi == it.hi {
+		return nil
+	}
+
+	g.unlink(id, it.lo, it.hi)
+	it.lo, it.hi = lo, hi
+	g.link(id, lo, hi)
+
+	return nil
+}
+
+func hashCells3D(lo, hi [3]int) float64 {
+	return float64(hi[0]-lo[0]+1) * float64(hi[1]-lo[1]+1) * float64(hi[2]-lo[2]+1)
+}
+
+func (g *HashGrid3D) link(id int, lo, hi [3]int) {
+	if hashCells3D(lo, hi) > hashGridMaxCells {
+		g.overflow = append(g.overflow, id)
+		return
+	}
+
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for z := lo[2]; z <= hi[2]; z++ {
+				k := [3]int{x, y, z}
+				g.cells[k] = append(g.cells[k], id)
+			}
+		}
+	}
+}
+
+func (g *HashGrid3D) unlink(id int, lo, hi [3]int) {
+	if hashCells3D(lo, hi) > hashGridMaxCells {
+		g.overflow = removeID(g.overflow, id)
+		return
+	}
+
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for z := lo[2]; z <= hi[2]; z++ {
+				k := [3]int{x, y, z}
+				g.cells[k] = removeID(g.cells[k], id)
+				if len(g.cells[k]) == 0 {
+					delete(g.cells, k)
+				}
+			}
+		}
+	}
+}
+
+// ids of the objects whose boxes overlap box
+func (g *HashGrid3D) QueryAABB(box AABB3D) []int {
+	return g.query(box, func(b AABB3D) bool {
+		return b.Intersects(box)
+	})
+}
+
+func (g *HashGrid3D) QuerySphere(s Sphere) []int {
+	return g.query(s.Bounds(), func(b AABB3D) bool {
+		return b.IntersectsSphere(s)
+	})
+}
+
+// ids in increasing order whichever way they were found
+func (g *HashGrid3D) query(area AABB3D, overlaps func(AABB3D) bool) []int {
+	out := make([]int, 0)
+
+	lo, hi := g.Cell(area.Min), g.Cell(area.Max)
+
+	// scanning the objects is cheaper than visiting a huge range of cells
+	if hashCells3D(lo, hi) > float64(len(g.items)) {
+		for id, it := range g.items {
+			if overlaps(it.box) {
+				out = append(out, id)
+			}
+		}
+		sort.Ints(out)
+		return out
+	}
+
+	for _, id := range g.overflow {
+		if overlaps(g.items[id].box) {
+			out = append(out, id)
+		}
+	}
+
+	seen := make(map[int]bool)
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for z := lo[2]; z <= hi[2]; z++ {
+				for _, id := range g.cells[[3]int{x, y, z}] {
+					if seen[id] {
+						continue
+					}
+					seen[id] = true
+
+					if overlaps(g.items[id].box) {
+						out = append(out, id)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Ints(out)
+	return out
+}
+
+// Every pair of overlapping objects once with the smaller id first, sorted
+func (g *HashGrid3D) Pairs() [][2]int {
+	found := make(map[[2]int]bool)
+
+	for _, ids := range g.cells {
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				p := [2]int{min(a, b), max(a, b)}
+				if found[p] {
+					continue
+				}
+
+				if g.items[a].box.Intersects(g.items[b].box) {
+					found[p] = true
+				}
+			}
+		}
+	}
+
+	// the big boxes are in no cell, test them against everything
+	for _, a := range g.overflow {
+		for b, it := range g.items {
+			if a != b && g.items[a].box.Intersects(it.box) {
+				found[[2]int{min(a, b), max(a, b)}] = true
+			}
+		}
+	}
+
+	return sortedPairs(found)
+}
+
+func removeID(ids []int, id int) []int {
+	for i, o := range ids {
+		if o == id {
+			last := len(ids) - 1
+			ids[i] = ids[last]
+			return ids[:last]
+		}
+	}
+
+	return ids
+}
+
+func sortedPairs(set map[[2]int]bool) [][2]int {
+	out := make([][2]int, 0, len(set))
+	for p := range set {
+		out = append(out, p)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i][0] != out[j][0] {
+			return out[i][0] < out[j][0]
+		}
+		return out[i][1] < out[j][1]
+	})
+
+	return out
+}
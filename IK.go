@@ -0,0 +1,299 @@
+package golem
+
+import "math"
+
+// Outcome of an iterative solve, Error is the distance left between the
+// end effector and the target
+type IKResult struct {
+	Iterations int
+	Error      float64
+	Converged  bool
+}
+
+// Limits of the bone leaving a joint. MaxBend is the largest angle to the
+// bone before it, or to the rest direction of the first bone, and 0 is
+// free. A non zero Hinge keeps the bone in the plane normal to that axis,
+// given in the frame of the bone before it
+type JointLimit struct {
+	MaxBend float64
+	Hinge   Vec3D
+}
+
+// Chain of joints where bone i runs from joint i to i+1 and the last joint
+// is the end effector. Rotations are world space, one per joint, and turn
+// with the bone leaving the joint when a solver moves it
+type IKChain struct {
+	Joints    []Vec3D
+	Rotations []Quaternion
+	Limits    []JointLimit
+
+	Tolerance     float64
+	MaxIterations int
+
+	lengths []float64
+	rest    Vec3D
+
+	// bone directions and rotations when FABRIK started
+	startDirs []Vec3D
+	startRots []Quaternion
+}
+
+// Rotations start as the identity, Limits as free joints
+func NewIKChain(joints []Vec3D) (*IKChain, error) {
+	if len(joints) < 2 {
+		return nil, ErrInvalidLen
+	}
+
+	c := &IKChain{
+		Joints:        append([]Vec3D(nil), joints...),
+		Rotations:     make([]Quaternion, len(joints)),
+		Limits:        make([]JointLimit, len(joints)),
+		Tolerance:     1e-4,
+		MaxIterations: 32,
+		lengths:       make([]float64, len(joints)-1),
+		startDirs:     make([]Vec3D, len(joints)-1),
+		startRots:     make([]Quaternion, len(joints)),
+	}
+
+	for i := range c.Rotations {
+		c.Rotations[i] = Quaternion{W: 1}
+	}
+
+	for i := range c.lengths {
+		c.lengths[i] = joints[i].Dist(joints[i+1])
+		if c.lengths[i] == 0 {
+			return nil, ErrZeroLen
+		}
+	}
+
+	c.rest = joints[1].SubVec(joints[0]).ScalerMulVec(1 / c.lengths[0])
+	return c, nil
+}
+
+// reach of the fully stretched chain
+func (c *IKChain) Length() float64 {
+	l := 0.0
+	for _, b := range c.lengths {
+		l += b
+	}
+
+	return l
+}
+
+// Cyclic coordinate descent, every pass turns each joint from the end
+// back to the root so the end effector points at the target
+func (c *IKChain) SolveCCD(target Vec3D) IKResult {
+	n := len(c.Joints)
+
+	res := IKResult{}
+	for ; res.Iterations < c.MaxIterations; res.Iterations++ {
+		if c.Joints[n-1].Dist(target) <= c.Tolerance {
+			break
+		}
+
+		for i := n - 2; i >= 0; i-- {
+			q := Quaternion{}
+			if err := q.SetFromTo(c.Joints[n-1].SubVec(c.Joints[i]), target.SubVec(c.Joints[i])); err != nil {
+				continue
+			}
+			c.rotateFrom(i, q)
+
+			d := c.boneDir(i)
+			if l := c.constrain(i, d); l != d {
+				if err := q.SetFromTo(d, l); err == nil {
+					c.rotateFrom(i, q)
+				}
+			}
+		}
+	}
+
+	return c.finish(target, res)
+}
+
+// Forward and backward reaching, moves the end effector onto the target
+// then pulls the chain back onto the root while keeping bone lengths
+func (c *IKChain) SolveFABRIK(target Vec3D) IKResult {
+	n := len(c.Joints)
+	root := c.Joints[0]
+
+	for i := range c.startDirs {
+		c.startDirs[i] = c.boneDir(i)
+	}
+	copy(c.startRots, c.Rotations)
+
+	res := IKResult{}
+	for ; res.Iterations < c.MaxIterations; res.Iterations++ {
+		if c.Joints[n-1].Dist(target) <= c.Tolerance {
+			break
+		}
+
+		c.Joints[n-1] = target
+		for i := n - 2; i >= 0; i-- {
+			d := c.Joints[i].SubVec(c.Joints[i+1])
+			if _, err := d.Normalize(); err != nil {
+				d = c.startDirs[i].ScalerMulVec(-1)
+			}
+			c.Joints[i] = c.Joints[i+1].AddVec(d.ScalerMulVec(c.lengths[i]))
+		}
+
+		c.Joints[0] = root
+		for i := 0; i < n-1; i++ {
+			d := c.Joints[i+1].SubVec(c.Joints[i])
+			if _, err := d.Normalize(); err != nil {
+				d = c.startDirs[i]
+			}
+			c.Joints[i+1] = c.Joints[i].AddVec(c.constrain(i, d).ScalerMulVec(c.lengths[i]))
+
+			// the shortest swing from where the bone started
+			q := Quaternion{}
+			if err := q.SetFromTo(c.startDirs[i], c.boneDir(i)); err == nil {
+				c.Rotations[i] = q.MultiplyQt(c.startRots[i])
+				if i == n-2 {
+					c.Rotations[n-1] = q.MultiplyQt(c.startRots[n-1])
+				}
+			}
+		}
+	}
+
+	return c.finish(target, res)
+}
+
+func (c *IKChain) finish(target Vec3D, res IKResult) IKResult {
+	res.Error = c.Joints[len(c.Joints)-1].Dist(target)
+	res.Converged = res.Error <= c.Tolerance
+
+	return res
+}
+
+func (c *IKChain) boneDir(i int) Vec3D {
+	return c.Joints[i+1].SubVec(c.Joints[i]).ScalerMulVec(1 / c.lengths[i])
+}
+
+// turns everything from joint i on about joint i
+func (c *IKChain) rotateFrom(i int, q Quaternion) {
+	r := q.ToRotMat3D()
+	for k := i + 1; k < len(c.Joints); k++ {
+		c.Joints[k] = r.RotateArndPoint(c.Joints[k], c.Joints[i])
+	}
+	for k := i; k < len(c.Rotations); k++ {
+		c.Rotations[k] = q.MultiplyQt(c.Rotations[k])
+	}
+}
+
+// closest direction to d the limit of joint i allows
+func (c *IKChain) constrain(i int, d Vec3D) Vec3D {
+	l := c.Limits[i]
+	if l.MaxBend <= 0 && l.Hinge == (Vec3D{}) {
+		return d
+	}
+
+	parent, frame := c.rest, Quaternion{W: 1}
+	if i > 0 {
+		parent, frame = c.boneDir(i-1), c.Rotations[i-1]
+	}
+
+	var axis Vec3D
+	if l.Hinge != (Vec3D{}) {
+		axis = frame.ToRotMat3D().RotateVec3D(l.Hinge)
+		axis.Normalize()
+
+		d = d.SubVec(axis.ScalerMulVec(axis.Dot(d)))
+		if _, err := d.Normalize(); err != nil {
+			return parent
+		}
+	}
+
+	if l.MaxBend <= 0 {
+		return d
+	}
+
+	cos := Clamp(parent.Dot(d), -1, 1)
+	if math.Acos(cos) <= l.MaxBend {
+		return d
+	}
+
+	// turn the parent direction towards d by the largest bend
+	if axis == (Vec3D{}) {
+		axis = parent.CrossV(d)
+		if _, err := axis.Normalize(); err != nil {
+			axis = anyPerpendicular(parent)
+		}
+	} else if n := parent.CrossV(d); n.Dot(axis) < 0 {
+		axis.Reverse()
+	}
+
+	return rotateAbout(parent, axis, l.MaxBend)
+}
+
+// Rodrigues rotation of v about the unit axis
+func rotateAbout(v, axis Vec3D, angle float64) Vec3D {
+	cos, sin := math.Cos(angle), math.Sin(angle)
+
+	out := v.ScalerMulVec(cos)
+	out.Add(axis.CrossV(v).ScalerMulVec(sin))
+	out.Add(axis.ScalerMulVec(axis.Dot(v) * (1 - cos)))
+
+	return out
+}
+
+// Analytic solution for a root, mid and end joint chain. The rotations
+// are world space deltas for the root and mid bones
+type TwoBoneResult struct {
+	Mid          Vec3D
+	End          Vec3D
+	RootRotation Quaternion
+	MidRotation  Quaternion
+	Reached      bool
+}
+
+// The chain bends in the plane through root, target and pole, with the
+// mid joint on the side of the pole. Targets out of reach stretch the
+// chain towards them
+func TwoBoneIK(root, mid, end, target, pole Vec3D) (TwoBoneResult, error) {
+	a := root.Dist(mid)
+	b := mid.Dist(end)
+	if a == 0 || b == 0 {
+		return TwoBoneResult{}, ErrZeroLen
+	}
+
+	dir := target.SubVec(root)
+	dist, err := dir.Normalize()
+	if err != nil {
+		// any direction works for a target on the root, keep the current
+		dist = 0
+		dir = end.SubVec(root)
+		if _, err := dir.Normalize(); err != nil {
+			dir = mid.SubVec(root).ScalerMulVec(1 / a)
+		}
+	}
+
+	lo, hi := math.Abs(a-b), a+b
+	reached := dist >= lo && dist <= hi
+	d := Clamp(dist, lo, hi)
+
+	// bend direction from the pole, then from the current mid joint
+	bend := pole.SubVec(root)
+	bend.Sub(dir.ScalerMulVec(dir.Dot(bend)))
+	if _, err := bend.Normalize(); err != nil {
+		bend = mid.SubVec(root)
+		bend.Sub(dir.ScalerMulVec(dir.Dot(bend)))
+		if _, err := bend.Normalize(); err != nil {
+			bend = anyPerpendicular(dir)
+		}
+	}
+
+	cos := 1.0
+	if d > 0 {
+		cos = Clamp((a*a+d*d-b*b)/(2*a*d), -1, 1)
+	}
+	sin := math.Sqrt(1 - cos*cos)
+
+	res := TwoBoneResult{Reached: reached}
+	res.Mid = root.AddVec(dir.ScalerMulVec(a * cos)).AddVec(bend.ScalerMulVec(a * sin))
+	res.End = root.AddVec(dir.ScalerMulVec(d))
+
+	res.RootRotation.SetFromTo(mid.SubVec(root), res.Mid.SubVec(root))
+	res.MidRotation.SetFromTo(end.SubVec(mid), res.End.SubVec(res.Mid))
+
+	return res, nil
+}
@@ -0,0 +1,116 @@
+package golem
+
+// Inertia tensors are about the center of mass in body space
+
+// Solid box
+func BoxInertia(mass float64, halfExtents Vec3D) Mat3D {
+	x, y, z := halfExtents.X*halfExtents.X, halfExtents.Y*halfExtents.Y, halfExtents.Z*halfExtents.Z
+
+	return Mat3D{
+		{mass * (y + z) / 3, 0, 0},
+		{0, mass * (x + z) / 3, 0},
+		{0, 0, mass * (x + y) / 3},
+	}
+}
+
+// Solid sphere
+func SphereInertia(mass, radius float64) Mat3D {
+	i := 0.4 * mass * radius * radius
+
+	return Mat3D{
+		{i, 0, 0},
+		{0, i, 0},
+		{0, 0, i},
+	}
+}
+
+// Solid capsule along the Y axis, halfHeight is half the length of the
+// cylinder between the two hemispheres
+func CapsuleInertia(mass, radius, halfHeight float64) Mat3D {
+	r2 := radius * radius
+	h := 2 * halfHeight
+
+	// mass split by volume between the cylinder and the two hemispheres
+	cyl := h * r2
+	sph := 4 * radius * r2 / 3
+	mc := mass * cyl / (cyl + sph)
+	ms := mass - mc
+
+	axial := mc*r2/2 + ms*0.4*r2
+	side := mc*(h*h/12+r2/4) + ms*(0.4*r2+h*h/4+3*h*radius/8)
+
+	return Mat3D{
+		{side, 0, 0},
+		{0, axial, 0},
+		{0, 0, side},
+	}
+}
+
+// Mass, center of mass and inertia of a closed mesh of uniform density,
+// summed over the tetrahedra each face makes with the origin
+func MeshInertia(mesh *TriangleMesh, density float64) (float64, Vec3D, Mat3D, error) {
+	volume := 0.0
+	var center Vec3D
+	var cov Mat3D
+
+	for i := range mesh.Faces {
+		t := mesh.Triangle(i)
+		a := Mat3D{
+			{t.A.X, t.B.X, t.C.X},
+			{t.A.Y, t.B.Y, t.C.Y},
+			{t.A.Z, t.B.Z, t.C.Z},
+		}
+		det := a.Det()
+
+		volume += det / 6
+		center.Add(t.A.AddVec(t.B).AddVec(t.C).ScalerMulVec(det / 24))
+
+		// covariance of the canonical tetrahedron mapped through a
+		cov.Add(a.Multiply(tetCovariance).Multiply(a.TranposeMat()).ScaleMat(det))
+	}
+
+	if volume == 0 {
+		return 0, Vec3D{}, Mat3D{}, ErrZeroVolume
+	}
+	center.ScalerMul(1 / volume)
+
+	// covariance about the center of mass
+	cov.Sub(outerVec3D(center, center).ScaleMat(volume))
+	cov.Scale(density)
+
+	inertia := Mat3D{}
+	inertia.SetIdentity()
+	inertia.Scale(cov.Trace())
+	inertia.Sub(cov)
+
+	return density * volume, center, inertia, nil
+}
+
+var tetCovariance = Mat3D{
+	{1.0 / 60, 1.0 / 120, 1.0 / 120},
+	{1.0 / 120, 1.0 / 60, 1.0 / 120},
+	{1.0 / 120, 1.0 / 120, 1.0 / 60},
+}
+
+// Inertia about a point at offset from the center of mass
+func ShiftInertia(inertia Mat3D, mass float64, offset Vec3D) Mat3D {
+	shift := Mat3D{}
+	shift.SetIdentity()
+	shift.Scale(offset.Dot(offset))
+	shift.Sub(outerVec3D(offset, offset))
+
+	return inertia.AddMat(shift.ScaleMat(mass))
+}
+
+// Inertia of the body turned by r, as R I R^T
+func RotateInertia(inertia Mat3D, r RotMat3D) Mat3D {
+	return r.Mat3D.Multiply(inertia).Multiply(r.Mat3D.TranposeMat())
+}
+
+func outerVec3D(a, b Vec3D) Mat3D {
+	return Mat3D{
+		{a.X * b.X, a.X * b.Y, a.X * b.Z},
+		{a.Y * b.X, a.Y * b.Y, a.Y * b.Z},
+		{a.Z * b.X, a.Z * b.Y, a.Z * b.Z},
+	}
+}
@@ -0,0 +1,255 @@
+package golem
+
+// Joints hold two bodies together through the Solver. Anchors and axes
+// are stored in the space of their body and taken from world space when
+// the joint is made
+
+// Anchor points of both bodies stay together, rotation is free
+type BallSocketJoint struct {
+	A, B             *RigidBody
+	AnchorA, AnchorB Vec3D
+
+	jointRows
+}
+
+// Ball socket that also keeps the bodies turning only about Axis
+type HingeJoint struct {
+	A, B             *RigidBody
+	AnchorA, AnchorB Vec3D
+	AxisA, AxisB     Vec3D
+
+	jointRows
+}
+
+// Keeps the relative rotation and lets B slide along Axis of A
+type SliderJoint struct {
+	A, B             *RigidBody
+	AnchorA, AnchorB Vec3D
+	Axis             Vec3D
+
+	rest Quaternion
+	jointRows
+}
+
+// Keeps the anchor points Length apart
+type DistanceJoint struct {
+	A, B             *RigidBody
+	AnchorA, AnchorB Vec3D
+	Length           float64
+
+	jointRows
+}
+
+// Glues the bodies in their current relative pose
+type FixedJoint struct {
+	A, B             *RigidBody
+	AnchorA, AnchorB Vec3D
+
+	rest Quaternion
+	jointRows
+}
+
+func NewBallSocketJoint(a, b *RigidBody, anchor Vec3D) *BallSocketJoint {
+	return &BallSocketJoint{A: a, B: b, AnchorA: a.ToLocal(anchor), AnchorB: b.ToLocal(anchor)}
+}
+
+func NewHingeJoint(a, b *RigidBody, anchor, axis Vec3D) (*HingeJoint, error) {
+	if _, err := axis.Normalize(); err != nil {
+		return nil, err
+	}
+
+	return &HingeJoint{
+		A: a, B: b,
+		AnchorA: a.ToLocal(anchor), AnchorB: b.ToLocal(anchor),
+		AxisA: localDir(a, axis), AxisB: localDir(b, axis),
+	}, nil
+}
+
+// slides through the current position of B
+func NewSliderJoint(a, b *RigidBody, axis Vec3D) (*SliderJoint, error) {
+	if _, err := axis.Normalize(); err != nil {
+		return nil, err
+	}
+
+	return &SliderJoint{
+		A: a, B: b,
+		AnchorA: a.ToLocal(b.Position),
+		Axis:    localDir(a, axis),
+		rest:    relativeRotation(a, b),
+	}, nil
+}
+
+// Length is the current distance between the anchors
+func NewDistanceJoint(a, b *RigidBody, anchorA, anchorB Vec3D) *DistanceJoint {
+	return &DistanceJoint{
+		A: a, B: b,
+		AnchorA: a.ToLocal(anchorA), AnchorB: b.ToLocal(anchorB),
+		Length: anchorA.Dist(anchorB),
+	}
+}
+
+// anchored at the position of B
+func NewFixedJoint(a, b *RigidBody) *FixedJoint {
+	return &FixedJoint{A: a, B: b, AnchorA: a.ToLocal(b.Position), rest: relativeRotation(a, b)}
+}
+
+func (j *BallSocketJoint) Prepare(dt, beta float64, warm bool) {
+	j.begin(j.A, j.B, 3, warm)
+	j.setPoint(0, j.A, j.B, j.A.ToWorld(j.AnchorA), j.B.ToWorld(j.AnchorB), beta/dt)
+	j.finish(j.A, j.B, warm)
+}
+
+func (j *HingeJoint) Prepare(dt, beta float64, warm bool) {
+	j.begin(j.A, j.B, 5, warm)
+	j.setPoint(0, j.A, j.B, j.A.ToWorld(j.AnchorA), j.B.ToWorld(j.AnchorB), beta/dt)
+
+	// turning about the two directions across the axis is locked, the
+	// cross product of the axes is how far B has tipped off it
+	rot := j.A.Orientation.ToRotMat3D()
+	a1 := rot.RotateVec3D(j.AxisA)
+	a2 := j.B.Orientation.ToRotMat3D().RotateVec3D(j.AxisB)
+	tip := a1.CrossV(a2)
+
+	// the basis across the axis turns with A so warm started impulses stay
+	// on the rows they were solved for
+	t1 := rot.RotateVec3D(anyPerpendicular(j.AxisA))
+	t2 := a1.CrossV(t1)
+	for k, t := range []Vec3D{t1, t2} {
+		j.rows[3+k].set(Vec3D{}, t, t, beta/dt*tip.Dot(t))
+	}
+
+	j.finish(j.A, j.B, warm)
+}
+
+func (j *SliderJoint) Prepare(dt, beta float64, warm bool) {
+	j.begin(j.A, j.B, 5, warm)
+	j.setOrientation(0, j.A, j.B, j.rest, beta/dt)
+
+	pa, pb := j.A.ToWorld(j.AnchorA), j.B.ToWorld(j.AnchorB)
+	ra, rb := pa.SubVec(j.A.Position), pb.SubVec(j.B.Position)
+	d := pb.SubVec(pa)
+
+	// the axis and the basis across it turn with A, so moving across it
+	// includes the spin of A and warm starting stays on the same rows
+	rot := j.A.Orientation.ToRotMat3D()
+	axis := rot.RotateVec3D(j.Axis)
+	t1 := rot.RotateVec3D(anyPerpendicular(j.Axis))
+	t2 := axis.CrossV(t1)
+	for k, t := range []Vec3D{t1, t2} {
+		j.rows[3+k].set(t, ra.AddVec(d).CrossV(t), rb.CrossV(t), beta/dt*d.Dot(t))
+	}
+
+	j.finish(j.A, j.B, warm)
+}
+
+// distance from the anchor of A to B along the axis
+func (j *SliderJoint) Translation() float64 {
+	d := j.B.ToWorld(j.AnchorB).SubVec(j.A.ToWorld(j.AnchorA))
+	return d.Dot(j.A.Orientation.ToRotMat3D().RotateVec3D(j.Axis))
+}
+
+func (j *DistanceJoint) Prepare(dt, beta float64, warm bool) {
+	j.begin(j.A, j.B, 1, warm)
+
+	pa, pb := j.A.ToWorld(j.AnchorA), j.B.ToWorld(j.AnchorB)
+	n := pb.SubVec(pa)
+	dist, err := n.Normalize()
+	if err != nil {
+		// anchors on top of each other, keep the last direction
+		n = j.rows[0].linear
+		if n == (Vec3D{}) {
+			n = Vec3D{X: 1}
+		}
+	}
+
+	ra, rb := pa.SubVec(j.A.Position), pb.SubVec(j.B.Position)
+	j.rows[0].set(n, ra.CrossV(n), rb.CrossV(n), beta/dt*(dist-j.Length))
+
+	j.finish(j.A, j.B, warm)
+}
+
+func (j *FixedJoint) Prepare(dt, beta float64, warm bool) {
+	j.begin(j.A, j.B, 6, warm)
+	j.setPoint(0, j.A, j.B, j.A.ToWorld(j.AnchorA), j.B.ToWorld(j.AnchorB), beta/dt)
+	j.setOrientation(3, j.A, j.B, j.rest, beta/dt)
+	j.finish(j.A, j.B, warm)
+}
+
+func (j *BallSocketJoint) Solve() { j.solve(j.A, j.B) }
+func (j *HingeJoint) Solve()      { j.solve(j.A, j.B) }
+func (j *SliderJoint) Solve()     { j.solve(j.A, j.B) }
+func (j *DistanceJoint) Solve()   { j.solve(j.A, j.B) }
+func (j *FixedJoint) Solve()      { j.solve(j.A, j.B) }
+
+// Rows of a joint with the inverse world inertias of its bodies for the
+// current step
+type jointRows struct {
+	rows   []constraintRow
+	ia, ib Mat3D
+}
+
+func (j *jointRows) begin(a, b *RigidBody, n int, warm bool) {
+	j.ia, j.ib = a.InvWorldInertia(), b.InvWorldInertia()
+
+	if len(j.rows) != n {
+		j.rows = make([]constraintRow, n)
+	}
+	if !warm {
+		for i := range j.rows {
+			j.rows[i].impulse = 0
+		}
+	}
+}
+
+func (j *jointRows) finish(a, b *RigidBody, warm bool) {
+	for i := range j.rows {
+		r := &j.rows[i]
+		r.prepare(a, b, j.ia, j.ib)
+		if warm {
+			r.apply(a, b, j.ia, j.ib, r.impulse)
+		}
+	}
+}
+
+func (j *jointRows) solve(a, b *RigidBody) {
+	for i := range j.rows {
+		j.rows[i].solve(a, b, j.ia, j.ib)
+	}
+}
+
+// three rows from i keeping the world points pa of a and pb of b together
+func (j *jointRows) setPoint(i int, a, b *RigidBody, pa, pb Vec3D, k float64) {
+	ra, rb := pa.SubVec(a.Position), pb.SubVec(b.Position)
+	e := pb.SubVec(pa)
+
+	for n, axis := range []Vec3D{{X: 1}, {Y: 1}, {Z: 1}} {
+		j.rows[i+n].set(axis, ra.CrossV(axis), rb.CrossV(axis), k*axis.Dot(e))
+	}
+}
+
+// three rows from i keeping the rotation of b at the rotation of a times rest
+func (j *jointRows) setOrientation(i int, a, b *RigidBody, rest Quaternion, k float64) {
+	target := a.Orientation.MultiplyQt(rest)
+	e := b.Orientation.MultiplyQt(target.ConjugateQt())
+	if e.W < 0 {
+		e.Negate()
+	}
+
+	// small angle rotation vector from the target to b
+	err := Vec3D{X: 2 * e.X, Y: 2 * e.Y, Z: 2 * e.Z}
+	for n, axis := range []Vec3D{{X: 1}, {Y: 1}, {Z: 1}} {
+		j.rows[i+n].set(Vec3D{}, axis, axis, k*axis.Dot(err))
+	}
+}
+
+// rotation of b in the space of a
+func relativeRotation(a, b *RigidBody) Quaternion {
+	inv := a.Orientation.ConjugateQt()
+	return inv.MultiplyQt(b.Orientation)
+}
+
+// world direction into the space of b
+func localDir(b *RigidBody, dir Vec3D) Vec3D {
+	r := b.Orientation.ToRotMat3D()
+	return r.Mat3D.TranposeMat().MultiplyVec3D(dir)
+}
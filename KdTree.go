@@ -0,0 +1,376 @@
+package golem
+
+import "sort"
+
+// Static k-d tree over a copy of the points. The tree is implicit, each
+// range of order is split at its median along the axis of largest spread.
+// Results are indices into Points
+type KdTree2D struct {
+	Points []Vec2D
+
+	order []int
+	axes  []int8
+}
+
+type KdTree3D struct {
+	Points []Vec3D
+
+	order []int
+	axes  []int8
+}
+
+// candidate list for k nearest, kept sorted by distance then index
+type kdBest struct {
+	k    int
+	idx  []int
+	dist []float64
+}
+
+func NewKdTree2D(points []Vec2D) *KdTree2D {
+	t := &KdTree2D{}
+	t.Rebuild(points)
+
+	return t
+}
+
+// Replaces the points and rebuilds the tree, reusing its buffers
+func (t *KdTree2D) Rebuild(points []Vec2D) {
+	t.Points = append(t.Points[:0], points...)
+	t.order = t.order[:0]
+	t.axes = t.axes[:0]
+
+	for i := range points {
+		t.order = append(t.order, i)
+		t.axes = append(t.axes, 0)
+	}
+
+	t.build(0, len(points))
+}
+
+func (t *KdTree2D) build(lo, hi int) {
+	if hi-lo <= 1 {
+		return
+	}
+
+	b := AABB2D{Min: t.Points[t.order[lo]], Max: t.Points[t.order[lo]]}
+	for _, i := range t.order[lo+1 : hi] {
+		b.AddPoint(t.Points[i])
+	}
+
+	s := b.Size()
+	axis := 0
+	if s.Y > s.X {
+		axis = 1
+	}
+
+	mid := (lo + hi) / 2
+	kdSelect(t.order[lo:hi], mid-lo, func(i int) float64 {
+		return vecAxis2D(t.Points[i], axis)
+	})
+	t.axes[mid] = int8(axis)
+
+	t.build(lo, mid)
+	t.build(mid+1, hi)
+}
+
+func (t *KdTree2D) Len() int {
+	return len(t.Points)
+}
+
+// Index of and distance to the point closest to p, false when empty
+func (t *KdTree2D) Nearest(p Vec2D) (int, float64, bool) {
+	best := t.KNearest(p, 1)
+	if len(best) == 0 {
+		return -1, 0, false
+	}
+
+	return best[0], p.Dist(t.Points[best[0]]), true
+}
+
+// Nearest first, ties broken by index
+func (t *KdTree2D) KNearest(p Vec2D, k int) []int {
+	if k <= 0 {
+		return []int{}
+	}
+
+	best := &kdBest{k: k}
+	t.knn(p, 0, len(t.Points), best)
+
+	return best.idx
+}
+
+func (t *KdTree2D) knn(p Vec2D, lo, hi int, best *kdBest) {
+	if lo >= hi {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	i := t.order[mid]
+	q := t.Points[i]
+
+	d := p.SubVec(q)
+	best.offer(i, d.Dot(d))
+
+	axis := int(t.axes[mid])
+	diff := vecAxis2D(p, axis) - vecAxis2D(q, axis)
+
+	if diff < 0 {
+		t.knn(p, lo, mid, best)
+		if best.accepts(diff * diff) {
+			t.knn(p, mid+1, hi, best)
+		}
+	} else {
+		t.knn(p, mid+1, hi, best)
+		if best.accepts(diff * diff) {
+			t.knn(p, lo, mid, best)
+		}
+	}
+}
+
+// Indices of the points within radius of p, sorted by index
+func (t *KdTree2D) RadiusSearch(p Vec2D, radius float64) []int {
+	out := make([]int, 0)
+	if radius < 0 {
+		return out
+	}
+
+	t.radius(p, radius*radius, 0, len(t.Points), &out)
+	sort.Ints(out)
+
+	return out
+}
+
+func (t *KdTree2D) radius(p Vec2D, r2 float64, lo, hi int, out *[]int) {
+	if lo >= hi {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	i := t.order[mid]
+	q := t.Points[i]
+
+	d := p.SubVec(q)
+	if d.Dot(d) <= r2 {
+		*out = append(*out, i)
+	}
+
+	axis := int(t.axes[mid])
+	diff := vecAxis2D(p, axis) - vecAxis2D(q, axis)
+
+	if diff <= 0 || diff*diff <= r2 {
+		t.radius(p, r2, lo, mid, out)
+	}
+	if diff >= 0 || diff*diff <= r2 {
+		t.radius(p, r2, mid+1, hi, out)
+	}
+}
+
+func NewKdTree3D(points []Vec3D) *KdTree3D {
+	t := &KdTree3D{}
+	t.Rebuild(points)
+
+	return t
+}
+
+// Replaces the points and rebuilds the tree, reusing its buffers
+func (t *KdTree3D) Rebuild(points []Vec3D) {
+	t.Points = append(t.Points[:0], points...)
+	t.order = t.order[:0]
+	t.axes = t.axes[:0]
+
+	for i := range points {
+		t.order = append(t.order, i)
+		t.axes = append(t.axes, 0)
+	}
+
+	t.build(0, len(points))
+}
+
+func (t *KdTree3D) build(lo, hi int) {
+	if hi-lo <= 1 {
+		return
+	}
+
+	b := AABB3D{Min: t.Points[t.order[lo]], Max: t.Points[t.order[lo]]}
+	for _, i := range t.order[lo+1 : hi] {
+		b.AddPoint(t.Points[i])
+	}
+
+	s := b.Size()
+	axis := 0
+	if s.Y > s.X && s.Y >= s.Z {
+		axis = 1
+	} else if s.Z > s.X && s.Z > s.Y {
+		axis = 2
+	}
+
+	mid := (lo + hi) / 2
+	kdSelect(t.order[lo:hi], mid-lo, func(i int) float64 {
+		return vecAxis(t.Points[i], axis)
+	})
+	t.axes[mid] = int8(axis)
+
+	t.build(lo, mid)
+	t.build(mid+1, hi)
+}
+
+func (t *KdTree3D) Len() int {
+	return len(t.Points)
+}
+
+// Index of and distance to the point closest to p, false when empty
+func (t *KdTree3D) Nearest(p Vec3D) (int, float64, bool) {
+	best := t.KNearest(p, 1)
+	if len(best) == 0 {
+		return -1, 0, false
+	}
+
+	return best[0], p.Dist(t.Points[best[0]]), true
+}
+
+// Nearest first, ties broken by index
+func (t *KdTree3D) KNearest(p Vec3D, k int) []int {
+	if k <= 0 {
+		return []int{}
+	}
+
+	best := &kdBest{k: k}
+	t.knn(p, 0, len(t.Points), best)
+
+	return best.idx
+}
+
+func (t *KdTree3D) knn(p Vec3D, lo, hi int, best *kdBest) {
+	if lo >= hi {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	i := t.order[mid]
+	q := t.Points[i]
+
+	d := p.SubVec(q)
+	best.offer(i, d.Dot(d))
+
+	axis := int(t.axes[mid])
+	diff := vecAxis(p, axis) - vecAxis(q, axis)
+
+	if diff < 0 {
+		t.knn(p, lo, mid, best)
+		if best.accepts(diff * diff) {
+			t.knn(p, mid+1, hi, best)
+		}
+	} else {
+		t.knn(p, mid+1, hi, best)
+		if best.accepts(diff * diff) {
+			t.knn(p, lo, mid, best)
+		}
+	}
+}
+
+// Indices of the points within radius of p, sorted by index
+func (t *KdTree3D) RadiusSearch(p Vec3D, radius float64) []int {
+	out := make([]int, 0)
+	if radius < 0 {
+		return out
+	}
+
+	t.radius(p, radius*radius, 0, len(t.Points), &out)
+	sort.Ints(out)
+
+	return out
+}
+
+func (t *KdTree3D) radius(p Vec3D, r2 float64, lo, hi int, out *[]int) {
+	if lo >= hi {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	i := t.order[mid]
+	q := t.Points[i]
+
+	d := p.SubVec(q)
+	if d.Dot(d) <= r2 {
+		*out = append(*out, i)
+	}
+
+	axis := int(t.axes[mid])
+	diff := vecAxis(p, axis) - vecAxis(q, axis)
+
+	if diff <= 0 || diff*diff <= r2 {
+		t.radius(p, r2, lo, mid, out)
+	}
+	if diff >= 0 || diff*diff <= r2 {
+		t.radius(p, r2, mid+1, hi, out)
+	}
+}
+
+func (b *kdBest) offer(i int, d float64) {
+	n := len(b.idx)
+	if n == b.k && (d > b.dist[n-1] || (d == b.dist[n-1] && i > b.idx[n-1])) {
+		return
+	}
+
+	pos := sort.Search(n, func(j int) bool {
+		return b.dist[j] > d || (b.dist[j] == d && b.idx[j] > i)
+	})
+
+	if n < b.k {
+		b.idx = append(b.idx, 0)
+		b.dist = append(b.dist, 0)
+		n++
+	}
+
+	copy(b.idx[pos+1:], b.idx[pos:n-1])
+	copy(b.dist[pos+1:], b.dist[pos:n-1])
+	b.idx[pos] = i
+	b.dist[pos] = d
+}
+
+// whether a subtree at squared distance d may still hold a candidate,
+// equal distances are kept for the index tie break
+func (b *kdBest) accepts(d float64) bool {
+	return len(b.idx) < b.k || d <= b.dist[len(b.dist)-1]
+}
+
+// Hoare style quickselect, afterwards idx[k] holds the element of rank k
+// with no larger key before it and no smaller key after it
+func kdSelect(idx []int, k int, key func(int) float64) {
+	lo, hi := 0, len(idx)-1
+
+	for lo < hi {
+		pivot := key(idx[(lo+hi)/2])
+		i, j := lo, hi
+
+		for i <= j {
+			for key(idx[i]) < pivot {
+				i++
+			}
+			for key(idx[j]) > pivot {
+				j--
+			}
+			if i <= j {
+				idx[i], idx[j] = idx[j], idx[i]
+				i++
+				j--
+			}
+		}
+
+		if k <= j {
+			hi = j
+		} else if k >= i {
+			lo = i
+		} else {
+			return
+		}
+	}
+}
+
+func vecAxis2D(v Vec2D, axis int) float64 {
+	if axis == 0 {
+		return v.X
+	}
+
+	return v.Y
+}
@@ -154,8 +154,8 @@ func (m Mat3D) AdjointMat() Mat3D {
 	adj[2][1] = -(m[0][0]*m[1][2] - m[0][2]*m[1][0])
 	adj[2][2] = m[0][0]*m[1][1] - m[0][1]*m[1][0]
 
+	adj.Transpose()
 	return adj
-
 }
 
 func (m *Mat3D) ToAdjoint() {
@@ -193,6 +193,14 @@ func (m Mat3D) Multiply(mat Mat3D) Mat3D {
 	return out
 }
 
+func (m Mat3D) MultiplyVec3D(vec Vec3D) Vec3D {
+	return Vec3D{
+		X: (m[0][0] * vec.X) + (m[0][1] * vec.Y) + (m[0][2] * vec.Z),
+		Y: (m[1][0] * vec.X) + (m[1][1] * vec.Y) + (m[1][2] * vec.Z),
+		Z: (m[2][0] * vec.X) + (m[2][1] * vec.Y) + (m[2][2] * vec.Z),
+	}
+}
+
 func (m *Mat3D) IsEqual(mat Mat3D) bool {
 	return (m[0][0] == mat[0][0] && m[0][1] == mat[0][1] && m[0][2] == mat[0][2] &&
 		m[1][0] == mat[1][0] && m[1][1] == mat[1][1] && m[1][2] == mat[1][2] &&
@@ -0,0 +1,304 @@
+package golem
+
+import "math"
+
+// Row major and applied to column vectors, the translation lives in the
+// last column like Mat3D multiplies vectors from the right
+type Mat4D [4][4]float64
+
+func IdentityMat4D() Mat4D {
+	m := Mat4D{}
+	m.SetIdentity()
+
+	return m
+}
+
+func TranslationMat4D(v Vec3D) Mat4D {
+	m := IdentityMat4D()
+	m[0][3] = v.X
+	m[1][3] = v.Y
+	m[2][3] = v.Z
+
+	return m
+}
+
+func ScalingMat4D(v Vec3D) Mat4D {
+	return Mat4D{
+		{v.X, 0, 0, 0},
+		{0, v.Y, 0, 0},
+		{0, 0, v.Z, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func RotationMat4D(r RotMat3D) Mat4D {
+	m := IdentityMat4D()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m[i][j] = r.Mat3D[i][j]
+		}
+	}
+
+	return m
+}
+
+// translation * rotation * scale, the usual transform of a scene node
+func TRSMat4D(t Vec3D, r Quaternion, s Vec3D) Mat4D {
+	r.Normalize()
+	rot := r.ToRotMat3D()
+
+	m := IdentityMat4D()
+	for i := 0; i < 3; i++ {
+		m[i][0] = rot.Mat3D[i][0] * s.X
+		m[i][1] = rot.Mat3D[i][1] * s.Y
+		m[i][2] = rot.Mat3D[i][2] * s.Z
+	}
+
+	m[0][3] = t.X
+	m[1][3] = t.Y
+	m[2][3] = t.Z
+
+	return m
+}
+
+// World to view transform of a camera placed at pos with orientation rot
+func ViewMat4D(pos Vec3D, rot Quaternion) Mat4D {
+	r := rot.ConjugateQt()
+	r.Normalize()
+	rt := r.ToRotMat3D()
+
+	m := RotationMat4D(rt)
+	t := rt.RotateVec3D(pos)
+	m[0][3] = -t.X
+	m[1][3] = -t.Y
+	m[2][3] = -t.Z
+
+	return m
+}
+
+// OpenGL style projection, the camera looks down -Z and depth maps to
+// [-1, 1] in clip space. fovY is the full vertical angle in radians
+func PerspectiveMat4D(fovY, aspect, near, far float64) (Mat4D, error) {
+	if fovY <= 0 || fovY >= math.Pi || aspect <= 0 || near <= 0 || far <= near {
+		return Mat4D{}, ErrInvalidProjection
+	}
+
+	f := 1 / math.Tan(fovY/2)
+
+	return Mat4D{
+		{f / aspect, 0, 0, 0},
+		{0, f, 0, 0},
+		{0, 0, (far + near) / (near - far), 2 * far * near / (near - far)},
+		{0, 0, -1, 0},
+	}, nil
+}
+
+func OrthographicMat4D(left, right, bottom, top, near, far float64) (Mat4D, error) {
+	if left == right || bottom == top || near == far {
+		return Mat4D{}, ErrInvalidProjection
+	}
+
+	return Mat4D{
+		{2 / (right - left), 0, 0, -(right + left) / (right - left)},
+		{0, 2 / (top - bottom), 0, -(top + bottom) / (top - bottom)},
+		{0, 0, -2 / (far - near), -(far + near) / (far - near)},
+		{0, 0, 0, 1},
+	}, nil
+}
+
+func (m *Mat4D) SetZero() {
+	*m = Mat4D{}
+}
+
+func (m *Mat4D) SetIdentity() {
+	*m = Mat4D{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func (m *Mat4D) Add(mat Mat4D) {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			m[i][j] += mat[i][j]
+		}
+	}
+}
+
+func (m Mat4D) AddMat(mat Mat4D) Mat4D {
+	m.Add(mat)
+	return m
+}
+
+func (m *Mat4D) Sub(mat Mat4D) {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			m[i][j] -= mat[i][j]
+		}
+	}
+}
+
+func (m Mat4D) SubMat(mat Mat4D) Mat4D {
+	m.Sub(mat)
+	return m
+}
+
+func (m *Mat4D) Scale(fac float64) {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			m[i][j] *= fac
+		}
+	}
+}
+
+func (m Mat4D) ScaleMat(fac float64) Mat4D {
+	m.Scale(fac)
+	return m
+}
+
+func (m *Mat4D) Transpose() {
+	for i := 0; i < 4; i++ {
+		for j := i + 1; j < 4; j++ {
+			m[i][j], m[j][i] = m[j][i], m[i][j]
+		}
+	}
+}
+
+func (m Mat4D) TransposeMat() Mat4D {
+	m.Transpose()
+	return m
+}
+
+// Laplace expansion over the 2x2 minors of the top and bottom rows
+func (m Mat4D) Det() float64 {
+	s0 := m[0][0]*m[1][1] - m[1][0]*m[0][1]
+	s1 := m[0][0]*m[1][2] - m[1][0]*m[0][2]
+	s2 := m[0][0]*m[1][3] - m[1][0]*m[0][3]
+	s3 := m[0][1]*m[1][2] - m[1][1]*m[0][2]
+	s4 := m[0][1]*m[1][3] - m[1][1]*m[0][3]
+	s5 := m[0][2]*m[1][3] - m[1][2]*m[0][3]
+
+	c5 := m[2][2]*m[3][3] - m[3][2]*m[2][3]
+	c4 := m[2][1]*m[3][3] - m[3][1]*m[2][3]
+	c3 := m[2][1]*m[3][2] - m[3][1]*m[2][2]
+	c2 := m[2][0]*m[3][3] - m[3][0]*m[2][3]
+	c1 := m[2][0]*m[3][2] - m[3][0]*m[2][2]
+	c0 := m[2][0]*m[3][1] - m[3][0]*m[2][1]
+
+	return s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+}
+
+func (m *Mat4D) Inverse() error {
+	s0 := m[0][0]*m[1][1] - m[1][0]*m[0][1]
+	s1 := m[0][0]*m[1][2] - m[1][0]*m[0][2]
+	s2 := m[0][0]*m[1][3] - m[1][0]*m[0][3]
+	s3 := m[0][1]*m[1][2] - m[1][1]*m[0][2]
+	s4 := m[0][1]*m[1][3] - m[1][1]*m[0][3]
+	s5 := m[0][2]*m[1][3] - m[1][2]*m[0][3]
+
+	c5 := m[2][2]*m[3][3] - m[3][2]*m[2][3]
+	c4 := m[2][1]*m[3][3] - m[3][1]*m[2][3]
+	c3 := m[2][1]*m[3][2] - m[3][1]*m[2][2]
+	c2 := m[2][0]*m[3][3] - m[3][0]*m[2][3]
+	c1 := m[2][0]*m[3][2] - m[3][0]*m[2][2]
+	c0 := m[2][0]*m[3][1] - m[3][0]*m[2][1]
+
+	det := s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+	if det == 0 {
+		return ErrZeroDet
+	}
+
+	a := *m
+	inv := 1 / det
+
+	m[0][0] = (a[1][1]*c5 - a[1][2]*c4 + a[1][3]*c3) * inv
+	m[0][1] = (-a[0][1]*c5 + a[0][2]*c4 - a[0][3]*c3) * inv
+	m[0][2] = (a[3][1]*s5 - a[3][2]*s4 + a[3][3]*s3) * inv
+	m[0][3] = (-a[2][1]*s5 + a[2][2]*s4 - a[2][3]*s3) * inv
+
+	m[1][0] = (-a[1][0]*c5 + a[1][2]*c2 - a[1][3]*c1) * inv
+	m[1][1] = (a[0][0]*c5 - a[0][2]*c2 + a[0][3]*c1) * inv
+	m[1][2] = (-a[3][0]*s5 + a[3][2]*s2 - a[3][3]*s1) * inv
+	m[1][3] = (a[2][0]*s5 - a[2][2]*s2 + a[2][3]*s1) * inv
+
+	m[2][0] = (a[1][0]*c4 - a[1][1]*c2 + a[1][3]*c0) * inv
+	m[2][1] = (-a[0][0]*c4 + a[0][1]*c2 - a[0][3]*c0) * inv
+	m[2][2] = (a[3][0]*s4 - a[3][1]*s2 + a[3][3]*s0) * inv
+	m[2][3] = (-a[2][0]*s4 + a[2][1]*s2 - a[2][3]*s0) * inv
+
+	m[3][0] = (-a[1][0]*c3 + a[1][1]*c1 - a[1][2]*c0) * inv
+	m[3][1] = (a[0][0]*c3 - a[0][1]*c1 + a[0][2]*c0) * inv
+	m[3][2] = (-a[3][0]*s3 + a[3][1]*s1 - a[3][2]*s0) * inv
+	m[3][3] = (a[2][0]*s3 - a[2][1]*s1 + a[2][2]*s0) * inv
+
+	return nil
+}
+
+func (m Mat4D) InverseMat() Mat4D {
+	m.Inverse()
+	return m
+}
+
+func (m Mat4D) Multiply(mat Mat4D) Mat4D {
+	out := Mat4D{}
+
+	for k := 0; k < 4; k++ {
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				out[i][j] += m[i][k] * mat[k][j]
+			}
+		}
+	}
+
+	return out
+}
+
+// Transforms a point with w = 1 and divides by the resulting w
+func (m Mat4D) TransformPoint(v Vec3D) Vec3D {
+	x := m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z + m[0][3]
+	y := m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z + m[1][3]
+	z := m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z + m[2][3]
+	w := m[3][0]*v.X + m[3][1]*v.Y + m[3][2]*v.Z + m[3][3]
+
+	if w != 1 && w != 0 {
+		return Vec3D{X: x / w, Y: y / w, Z: z / w}
+	}
+
+	return Vec3D{X: x, Y: y, Z: z}
+}
+
+// Transforms a direction with w = 0, translation has no effect
+func (m Mat4D) TransformDir(v Vec3D) Vec3D {
+	return Vec3D{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// upper left 3x3 block
+func (m Mat4D) Mat3D() Mat3D {
+	return Mat3D{
+		{m[0][0], m[0][1], m[0][2]},
+		{m[1][0], m[1][1], m[1][2]},
+		{m[2][0], m[2][1], m[2][2]},
+	}
+}
+
+func (m Mat4D) Translation() Vec3D {
+	return Vec3D{X: m[0][3], Y: m[1][3], Z: m[2][3]}
+}
+
+func (m *Mat4D) IsEqual(mat Mat4D) bool {
+	return *m == mat
+}
+
+func (m *Mat4D) IsIdentity() bool {
+	return *m == IdentityMat4D()
+}
+
+func (m *Mat4D) Trace() float64 {
+	return m[0][0] + m[1][1] + m[2][2] + m[3][3]
+}
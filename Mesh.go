@@ -0,0 +1,28 @@
+package golem
+
+// Indexed triangles wound ccw around the outward normal. Normals and UVs
+// are per vertex and may be empty
+type TriangleMesh struct {
+	Vertices []Vec3D
+	Normals  []Vec3D
+	UVs      []Vec2D
+	Faces    [][3]int
+}
+
+func (m *TriangleMesh) Triangle(i int) Triangle3D {
+	f := m.Faces[i]
+	return Triangle3D{A: m.Vertices[f[0]], B: m.Vertices[f[1]], C: m.Vertices[f[2]]}
+}
+
+func (m *TriangleMesh) Area() float64 {
+	area := 0.0
+	for i := range m.Faces {
+		area += m.Triangle(i).Area()
+	}
+
+	return area
+}
+
+func (m *TriangleMesh) Bounds() (AABB3D, error) {
+	return NewAABB3DFromPoints(m.Vertices)
+}
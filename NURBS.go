@@ -0,0 +1,791 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// Rational B-spline curve of the given degree. Knots holds
+// len(Points)+Degree+1 non decreasing values and the curve is defined on
+// [Knots[Degree], Knots[len(Points)]]
+type NURBSCurve struct {
+	Degree  int
+	Points  []Vec3D
+	Weights []float64
+	Knots   []float64
+}
+
+// Nil weights are all 1, nil knots are clamped and uniform on [0, 1]
+func NewNURBSCurve(degree int, points []Vec3D, weights, knots []float64) (*NURBSCurve, error) {
+	if degree < 1 || len(points) < degree+1 {
+		return nil, ErrInvalidLen
+	}
+
+	weights, err := nurbsWeights(weights, len(points))
+	if err != nil {
+		return nil, err
+	}
+
+	knots, err = nurbsKnots(knots, degree, len(points))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NURBSCurve{
+		Degree:  degree,
+		Points:  append([]Vec3D(nil), points...),
+		Weights: weights,
+		Knots:   knots,
+	}, nil
+}
+
+func (c *NURBSCurve) Domain() (float64, float64) {
+	return c.Knots[c.Degree], c.Knots[len(c.Points)]
+}
+
+func (c *NURBSCurve) At(u float64) Vec3D {
+	return c.Derivatives(u, 0)[0]
+}
+
+func (c *NURBSCurve) Derivative(u float64) Vec3D {
+	return c.Derivatives(u, 1)[1]
+}
+
+func (c *NURBSCurve) SecondDerivative(u float64) Vec3D {
+	return c.Derivatives(u, 2)[2]
+}
+
+// Point and its derivatives up to order at u, u is clamped to the domain
+func (c *NURBSCurve) Derivatives(u float64, order int) []Vec3D {
+	p := c.Degree
+	lo, hi := c.Domain()
+	u = Clamp(u, lo, hi)
+
+	span := nurbsSpan(c.Knots, p, len(c.Points), u)
+	ders := nurbsBasisDers(c.Knots, span, p, u, min(order, p))
+
+	aders := make([][4]float64, order+1)
+	for k := range ders {
+		for j := 0; j <= p; j++ {
+			i := span - p + j
+			aders[k] = hadd(aders[k], ders[k][j], homogeneous(c.Points[i], c.Weights[i]))
+		}
+	}
+
+	return rationalDers(aders)
+}
+
+// Inserts u times times without changing the shape, the multiplicity of u
+// stops at Degree
+func (c *NURBSCurve) InsertKnot(u float64, times int) error {
+	lo, hi := c.Domain()
+	if u < lo || u > hi {
+		return ErrInvalidInterPolParam
+	}
+
+	pw := homogeneousPoints(c.Points, c.Weights)
+	times = min(times, c.Degree-knotMultiplicity(c.Knots, u))
+
+	for k := 0; k < times; k++ {
+		c.Knots, pw = nurbsInsertKnot(c.Knots, pw, c.Degree, u)
+	}
+
+	c.Points, c.Weights = fromHomogeneous(pw)
+	return nil
+}
+
+// Raises the degree by t without changing the shape, the knots must be
+// clamped at both ends
+func (c *NURBSCurve) ElevateDegree(t int) error {
+	if t <= 0 {
+		return nil
+	}
+	if !nurbsClamped(c.Knots, c.Degree) {
+		return ErrInvalidKnots
+	}
+
+	pw := homogeneousPoints(c.Points, c.Weights)
+	c.Knots, pw = nurbsElevate(c.Knots, pw, c.Degree, t)
+	c.Points, c.Weights = fromHomogeneous(pw)
+	c.Degree += t
+
+	return nil
+}
+
+// Tensor product surface, Points[i][j] is the control point i along u and
+// j along v. Weights has the same shape as Points
+type NURBSSurface struct {
+	DegreeU, DegreeV int
+	Points           [][]Vec3D
+	Weights          [][]float64
+	KnotsU, KnotsV   []float64
+}
+
+// Nil weights are all 1, nil knots are clamped and uniform on [0, 1]
+func NewNURBSSurface(degreeU, degreeV int, points [][]Vec3D, weights [][]float64, knotsU, knotsV []float64) (*NURBSSurface, error) {
+	if degreeU < 1 || degreeV < 1 || len(points) < degreeU+1 || len(points[0]) < degreeV+1 {
+		return nil, ErrInvalidLen
+	}
+	if weights != nil && len(weights) != len(points) {
+		return nil, ErrInvalidLen
+	}
+
+	s := &NURBSSurface{
+		DegreeU: degreeU,
+		DegreeV: degreeV,
+		Points:  make([][]Vec3D, len(points)),
+		Weights: make([][]float64, len(points)),
+	}
+
+	for i, row := range points {
+		if len(row) != len(points[0]) {
+			return nil, ErrInvalidLen
+		}
+		s.Points[i] = append([]Vec3D(nil), row...)
+
+		var w []float64
+		if weights != nil {
+			w = weights[i]
+		}
+
+		var err error
+		if s.Weights[i], err = nurbsWeights(w, len(row)); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if s.KnotsU, err = nurbsKnots(knotsU, degreeU, len(points)); err != nil {
+		return nil, err
+	}
+	if s.KnotsV, err = nurbsKnots(knotsV, degreeV, len(points[0])); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *NURBSSurface) Domain() (u0, u1, v0, v1 float64) {
+	return s.KnotsU[s.DegreeU], s.KnotsU[len(s.Points)], s.KnotsV[s.DegreeV], s.KnotsV[len(s.Points[0])]
+}
+
+func (s *NURBSSurface) At(u, v float64) Vec3D {
+	p, _, _ := s.eval(u, v)
+	return p
+}
+
+// first partial derivatives along u and v
+func (s *NURBSSurface) Partials(u, v float64) (Vec3D, Vec3D) {
+	_, du, dv := s.eval(u, v)
+	return du, dv
+}
+
+// Unit normal du x dv. Fails where the partials are parallel, as at the
+// poles of a surface of revolution
+func (s *NURBSSurface) Normal(u, v float64) (Vec3D, error) {
+	_, du, dv := s.eval(u, v)
+
+	n := du.CrossV(dv)
+	if _, err := n.Normalize(); err != nil {
+		return Vec3D{}, err
+	}
+
+	return n, nil
+}
+
+func (s *NURBSSurface) eval(u, v float64) (Vec3D, Vec3D, Vec3D) {
+	pu, pv := s.DegreeU, s.DegreeV
+	u0, u1, v0, v1 := s.Domain()
+	u = Clamp(u, u0, u1)
+	v = Clamp(v, v0, v1)
+
+	spanU := nurbsSpan(s.KnotsU, pu, len(s.Points), u)
+	spanV := nurbsSpan(s.KnotsV, pv, len(s.Points[0]), v)
+	nu := nurbsBasisDers(s.KnotsU, spanU, pu, u, 1)
+	nv := nurbsBasisDers(s.KnotsV, spanV, pv, v, 1)
+
+	// A, dA/du and dA/dv in homogeneous space
+	var a [3][4]float64
+	for i := 0; i <= pu; i++ {
+		for j := 0; j <= pv; j++ {
+			r, c := spanU-pu+i, spanV-pv+j
+			h := homogeneous(s.Points[r][c], s.Weights[r][c])
+
+			a[0] = hadd(a[0], nu[0][i]*nv[0][j], h)
+			a[1] = hadd(a[1], nu[1][i]*nv[0][j], h)
+			a[2] = hadd(a[2], nu[0][i]*nv[1][j], h)
+		}
+	}
+
+	p := Vec3D{X: a[0][0] / a[0][3], Y: a[0][1] / a[0][3], Z: a[0][2] / a[0][3]}
+	partial := func(d [4]float64) Vec3D {
+		return Vec3D{X: d[0], Y: d[1], Z: d[2]}.SubVec(p.ScalerMulVec(d[3])).ScalerMulVec(1 / a[0][3])
+	}
+
+	return p, partial(a[1]), partial(a[2])
+}
+
+func (s *NURBSSurface) InsertKnotU(u float64, times int) error {
+	u0, u1, _, _ := s.Domain()
+	if u < u0 || u > u1 {
+		return ErrInvalidInterPolParam
+	}
+
+	times = min(times, s.DegreeU-knotMultiplicity(s.KnotsU, u))
+	if times <= 0 {
+		return nil
+	}
+
+	s.transformU(func(pw [][4]float64) ([]float64, [][4]float64) {
+		knots := s.KnotsU
+		for k := 0; k < times; k++ {
+			knots, pw = nurbsInsertKnot(knots, pw, s.DegreeU, u)
+		}
+		return knots, pw
+	})
+
+	return nil
+}
+
+func (s *NURBSSurface) InsertKnotV(v float64, times int) error {
+	s.transpose()
+	defer s.transpose()
+
+	return s.InsertKnotU(v, times)
+}
+
+func (s *NURBSSurface) ElevateDegreeU(t int) error {
+	if t <= 0 {
+		return nil
+	}
+	if !nurbsClamped(s.KnotsU, s.DegreeU) {
+		return ErrInvalidKnots
+	}
+
+	s.transformU(func(pw [][4]float64) ([]float64, [][4]float64) {
+		return nurbsElevate(s.KnotsU, pw, s.DegreeU, t)
+	})
+	s.DegreeU += t
+
+	return nil
+}
+
+func (s *NURBSSurface) ElevateDegreeV(t int) error {
+	s.transpose()
+	defer s.transpose()
+
+	return s.ElevateDegreeU(t)
+}
+
+// applies a curve operation to every column of control points along u,
+// the operation returns the same knots for all of them
+func (s *NURBSSurface) transformU(op func([][4]float64) ([]float64, [][4]float64)) {
+	cols := len(s.Points[0])
+
+	var knots []float64
+	var points [][]Vec3D
+	var weights [][]float64
+
+	for j := 0; j < cols; j++ {
+		pw := make([][4]float64, len(s.Points))
+		for i := range pw {
+			pw[i] = homogeneous(s.Points[i][j], s.Weights[i][j])
+		}
+
+		var out [][4]float64
+		knots, out = op(pw)
+
+		if points == nil {
+			points = make([][]Vec3D, len(out))
+			weights = make([][]float64, len(out))
+			for i := range out {
+				points[i] = make([]Vec3D, cols)
+				weights[i] = make([]float64, cols)
+			}
+		}
+
+		col, w := fromHomogeneous(out)
+		for i := range out {
+			points[i][j], weights[i][j] = col[i], w[i]
+		}
+	}
+
+	s.KnotsU, s.Points, s.Weights = knots, points, weights
+}
+
+// swaps the roles of u and v
+func (s *NURBSSurface) transpose() {
+	rows, cols := len(s.Points), len(s.Points[0])
+
+	points := make([][]Vec3D, cols)
+	weights := make([][]float64, cols)
+	for j := range points {
+		points[j] = make([]Vec3D, rows)
+		weights[j] = make([]float64, rows)
+		for i := 0; i < rows; i++ {
+			points[j][i], weights[j][i] = s.Points[i][j], s.Weights[i][j]
+		}
+	}
+
+	s.Points, s.Weights = points, weights
+	s.DegreeU, s.DegreeV = s.DegreeV, s.DegreeU
+	s.KnotsU, s.KnotsV = s.KnotsV, s.KnotsU
+}
+
+// Grid of (segmentsU+1) x (segmentsV+1) vertices evenly spaced in the
+// domain with UVs in [0, 1]. Faces are wound ccw around du x dv
+func (s *NURBSSurface) Tessellate(segmentsU, segmentsV int) (*TriangleMesh, error) {
+	if segmentsU < 1 || segmentsV < 1 {
+		return nil, ErrInvalidLen
+	}
+
+	u0, u1, v0, v1 := s.Domain()
+	verts := (segmentsU + 1) * (segmentsV + 1)
+
+	m := &TriangleMesh{
+		Vertices: make([]Vec3D, 0, verts),
+		Normals:  make([]Vec3D, 0, verts),
+		UVs:      make([]Vec2D, 0, verts),
+		Faces:    make([][3]int, 0, 2*segmentsU*segmentsV),
+	}
+
+	for i := 0; i <= segmentsU; i++ {
+		fu := float64(i) / float64(segmentsU)
+		u := u0 + (u1-u0)*fu
+
+		for j := 0; j <= segmentsV; j++ {
+			fv := float64(j) / float64(segmentsV)
+			v := v0 + (v1-v0)*fv
+
+			n, err := s.Normal(u, v)
+			if err != nil {
+				// degenerate point, take the normal from just inside
+				n, _ = s.Normal(u+(0.5-fu)*(u1-u0)*1e-6, v+(0.5-fv)*(v1-v0)*1e-6)
+			}
+
+			m.Vertices = append(m.Vertices, s.At(u, v))
+			m.Normals = append(m.Normals, n)
+			m.UVs = append(m.UVs, Vec2D{X: fu, Y: fv})
+		}
+	}
+
+	row := segmentsV + 1
+	for i := 0; i < segmentsU; i++ {
+		for j := 0; j < segmentsV; j++ {
+			a := i*row + j
+			b := a + row
+
+			m.Faces = append(m.Faces, [3]int{a, b, b + 1}, [3]int{a, b + 1, a + 1})
+		}
+	}
+
+	return m, nil
+}
+
+func nurbsWeights(weights []float64, n int) ([]float64, error) {
+	out := make([]float64, n)
+	if weights == nil {
+		for i := range out {
+			out[i] = 1
+		}
+		return out, nil
+	}
+
+	if len(weights) != n {
+		return nil, ErrInvalidLen
+	}
+	for i, w := range weights {
+		if !(w > 0) {
+			return nil, ErrInvalidWeights
+		}
+		out[i] = w
+	}
+
+	return out, nil
+}
+
+func nurbsKnots(knots []float64, p, n int) ([]float64, error) {
+	if knots == nil {
+		out := make([]float64, n+p+1)
+		for i := range out {
+			out[i] = Clamp(float64(i-p)/float64(n-p), 0, 1)
+		}
+		return out, nil
+	}
+
+	if len(knots) != n+p+1 {
+		return nil, ErrInvalidLen
+	}
+	for i := 1; i < len(knots); i++ {
+		if knots[i] < knots[i-1] {
+			return nil, ErrInvalidKnots
+		}
+	}
+	if knots[p] >= knots[n] {
+		return nil, ErrInvalidKnots
+	}
+
+	return append([]float64(nil), knots...), nil
+}
+
+func nurbsClamped(knots []float64, p int) bool {
+	m := len(knots) - 1
+	for i := 1; i <= p; i++ {
+		if knots[i] != knots[0] || knots[m-i] != knots[m] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func knotMultiplicity(knots []float64, u float64) int {
+	s := 0
+	for _, k := range knots {
+		if k == u {
+			s++
+		}
+	}
+
+	return s
+}
+
+// index i of the span knots[i] <= u < knots[i+1] for n control points,
+// the end of the domain belongs to the last span
+func nurbsSpan(knots []float64, p, n int, u float64) int {
+	if u >= knots[n] {
+		return n - 1
+	}
+	if u <= knots[p] {
+		return p
+	}
+
+	return p + sort.Search(n-p, func(k int) bool {
+		return knots[p+k+1] > u
+	})
+}
+
+// The p+1 non zero basis functions at u and their derivatives up to nd,
+// ders[k][j] is derivative k of the basis function span-p+j. Piegl and
+// Tiller A2.3
+func nurbsBasisDers(knots []float64, span, p int, u float64, nd int) [][]float64 {
+	ndu := make([][]float64, p+1)
+	for i := range ndu {
+		ndu[i] = make([]float64, p+1)
+	}
+	left := make([]float64, p+1)
+	right := make([]float64, p+1)
+
+	ndu[0][0] = 1
+	for j := 1; j <= p; j++ {
+		left[j] = u - knots[span+1-j]
+		right[j] = knots[span+j] - u
+
+		saved := 0.0
+		for r := 0; r < j; r++ {
+			// knot differences in the lower triangle
+			ndu[j][r] = right[r+1] + left[j-r]
+			tmp := ndu[r][j-1] / ndu[j][r]
+
+			ndu[r][j] = saved + right[r+1]*tmp
+			saved = left[j-r] * tmp
+		}
+		ndu[j][j] = saved
+	}
+
+	ders := make([][]float64, nd+1)
+	for k := range ders {
+		ders[k] = make([]float64, p+1)
+	}
+	for j := 0; j <= p; j++ {
+		ders[0][j] = ndu[j][p]
+	}
+
+	a := [2][]float64{make([]float64, p+1), make([]float64, p+1)}
+	for r := 0; r <= p; r++ {
+		s1, s2 := 0, 1
+		a[0][0] = 1
+
+		for k := 1; k <= nd; k++ {
+			d := 0.0
+			rk, pk := r-k, p-k
+
+			if r >= k {
+				a[s2][0] = a[s1][0] / ndu[pk+1][rk]
+				d = a[s2][0] * ndu[rk][pk]
+			}
+
+			j1, j2 := 1, k-1
+			if rk < -1 {
+				j1 = -rk
+			}
+			if r-1 > pk {
+				j2 = p - r
+			}
+
+			for j := j1; j <= j2; j++ {
+				a[s2][j] = (a[s1][j] - a[s1][j-1]) / ndu[pk+1][rk+j]
+				d += a[s2][j] * ndu[rk+j][pk]
+			}
+
+			if r <= pk {
+				a[s2][k] = -a[s1][k-1] / ndu[pk+1][r]
+				d += a[s2][k] * ndu[r][pk]
+			}
+
+			ders[k][r] = d
+			s1, s2 = s2, s1
+		}
+	}
+
+	f := float64(p)
+	for k := 1; k <= nd; k++ {
+		for j := range ders[k] {
+			ders[k][j] *= f
+		}
+		f *= float64(p - k)
+	}
+
+	return ders
+}
+
+// derivatives of A / w from the derivatives of the homogeneous curve A
+func rationalDers(aders [][4]float64) []Vec3D {
+	out := make([]Vec3D, len(aders))
+
+	for k := range aders {
+		v := Vec3D{X: aders[k][0], Y: aders[k][1], Z: aders[k][2]}
+		for i := 1; i <= k; i++ {
+			v.Sub(out[k-i].ScalerMulVec(binomial(k, i) * aders[i][3]))
+		}
+		out[k] = v.ScalerMulVec(1 / aders[0][3])
+	}
+
+	return out
+}
+
+// Boehm's single knot insertion in homogeneous space
+func nurbsInsertKnot(knots []float64, pw [][4]float64, p int, u float64) ([]float64, [][4]float64) {
+	n := len(pw)
+	k := nurbsSpan(knots, p, n, u)
+
+	nk := make([]float64, 0, len(knots)+1)
+	nk = append(nk, knots[:k+1]...)
+	nk = append(nk, u)
+	nk = append(nk, knots[k+1:]...)
+
+	q := make([][4]float64, n+1)
+	for i := range q {
+		switch {
+		case i <= k-p:
+			q[i] = pw[i]
+		case i > k:
+			q[i] = pw[i-1]
+		default:
+			a := (u - knots[i]) / (knots[i+p] - knots[i])
+			q[i] = hlerp(pw[i-1], pw[i], a)
+		}
+	}
+
+	return nk, q
+}
+
+// Degree elevation by t of a clamped curve in homogeneous space, Piegl
+// and Tiller A5.9. Each Bezier segment is elevated and the knots added
+// while splitting are removed again
+func nurbsElevate(knots []float64, pw [][4]float64, p, t int) ([]float64, [][4]float64) {
+	m := len(knots) - 1
+	ph := p + t
+	ph2 := ph / 2
+
+	bezalfs := make([][]float64, ph+1)
+	for i := range bezalfs {
+		bezalfs[i] = make([]float64, p+1)
+	}
+	bezalfs[0][0], bezalfs[ph][p] = 1, 1
+
+	for i := 1; i <= ph2; i++ {
+		inv := 1 / binomial(ph, i)
+		for j := max(0, i-t); j <= min(p, i); j++ {
+			bezalfs[i][j] = inv * binomial(p, j) * binomial(t, i-j)
+		}
+	}
+	for i := ph2 + 1; i < ph; i++ {
+		for j := max(0, i-t); j <= min(p, i); j++ {
+			bezalfs[i][j] = bezalfs[ph-i][p-j]
+		}
+	}
+
+	size := (m + 1) * (t + 1)
+	uh := make([]float64, size+ph+1)
+	qw := make([][4]float64, size)
+
+	bpts := make([][4]float64, p+1)
+	ebpts := make([][4]float64, ph+1)
+	next := make([][4]float64, p)
+	alfs := make([]float64, p)
+
+	mh, kind, cind := ph, ph+1, 1
+	r, a, b := -1, p, p+1
+	ua := knots[0]
+
+	qw[0] = pw[0]
+	for i := 0; i <= ph; i++ {
+		uh[i] = ua
+	}
+	copy(bpts, pw[:p+1])
+
+	for b < m {
+		i := b
+		for b < m && knots[b] == knots[b+1] {
+			b++
+		}
+		mul := b - i + 1
+		mh += mul + t
+		ub := knots[b]
+
+		oldr := r
+		r = p - mul
+
+		lbz, rbz := 1, ph
+		if oldr > 0 {
+			lbz = (oldr + 2) / 2
+		}
+		if r > 0 {
+			rbz = ph - (r+1)/2
+		}
+
+		// split off the Bezier segment [ua, ub]
+		if r > 0 {
+			numer := ub - ua
+			for k := p; k > mul; k-- {
+				alfs[k-mul-1] = numer / (knots[a+k] - ua)
+			}
+			for j := 1; j <= r; j++ {
+				s := mul + j
+				for k := p; k >= s; k-- {
+					bpts[k] = hlerp(bpts[k-1], bpts[k], alfs[k-s])
+				}
+				next[r-j] = bpts[p]
+			}
+		}
+
+		for i := lbz; i <= ph; i++ {
+			ebpts[i] = [4]float64{}
+			for j := max(0, i-t); j <= min(p, i); j++ {
+				ebpts[i] = hadd(ebpts[i], bezalfs[i][j], bpts[j])
+			}
+		}
+
+		// remove ua oldr times
+		if oldr > 1 {
+			first, last := kind-2, kind
+			den := ub - ua
+			bet := (ub - uh[kind-1]) / den
+
+			for tr := 1; tr < oldr; tr++ {
+				i, j := first, last
+				kj := j - kind + 1
+
+				for j-i > tr {
+					if i < cind {
+						alf := (ub - uh[i]) / (ua - uh[i])
+						qw[i] = hlerp(qw[i-1], qw[i], alf)
+					}
+					if j >= lbz {
+						if j-tr <= kind-ph+oldr {
+							gam := (ub - uh[j-tr]) / den
+							ebpts[kj] = hlerp(ebpts[kj+1], ebpts[kj], gam)
+						} else {
+							ebpts[kj] = hlerp(ebpts[kj+1], ebpts[kj], bet)
+						}
+					}
+					i++
+					j--
+					kj--
+				}
+
+				first--
+				last++
+			}
+		}
+
+		if a != p {
+			for i := 0; i < ph-oldr; i++ {
+				uh[kind] = ua
+				kind++
+			}
+		}
+		for j := lbz; j <= rbz; j++ {
+			qw[cind] = ebpts[j]
+			cind++
+		}
+
+		if b < m {
+			copy(bpts, next[:r])
+			for j := r; j <= p; j++ {
+				bpts[j] = pw[b-p+j]
+			}
+			a, b, ua = b, b+1, ub
+		} else {
+			for i := 0; i <= ph; i++ {
+				uh[kind+i] = ub
+			}
+		}
+	}
+
+	nh := mh - ph - 1
+	return uh[:nh+ph+2], qw[:nh+1]
+}
+
+func homogeneous(p Vec3D, w float64) [4]float64 {
+	return [4]float64{p.X * w, p.Y * w, p.Z * w, w}
+}
+
+func homogeneousPoints(points []Vec3D, weights []float64) [][4]float64 {
+	out := make([][4]float64, len(points))
+	for i, p := range points {
+		out[i] = homogeneous(p, weights[i])
+	}
+
+	return out
+}
+
+func fromHomogeneous(pw [][4]float64) ([]Vec3D, []float64) {
+	points := make([]Vec3D, len(pw))
+	weights := make([]float64, len(pw))
+
+	for i, h := range pw {
+		points[i] = Vec3D{X: h[0] / h[3], Y: h[1] / h[3], Z: h[2] / h[3]}
+		weights[i] = h[3]
+	}
+
+	return points, weights
+}
+
+// a + f*b
+func hadd(a [4]float64, f float64, b [4]float64) [4]float64 {
+	return [4]float64{a[0] + f*b[0], a[1] + f*b[1], a[2] + f*b[2], a[3] + f*b[3]}
+}
+
+func hlerp(a, b [4]float64, t float64) [4]float64 {
+	return [4]float64{
+		a[0] + t*(b[0]-a[0]),
+		a[1] + t*(b[1]-a[1]),
+		a[2] + t*(b[2]-a[2]),
+		a[3] + t*(b[3]-a[3]),
+	}
+}
+
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+
+	out := 1.0
+	for i := 1; i <= min(k, n-k); i++ {
+		out = out * float64(n-min(k, n-k)+i) / float64(i)
+	}
+
+	return math.Round(out)
+}
@@ -0,0 +1,158 @@
+package golem
+
+import "math"
+
+// Oriented box, the columns of Rotation are the local axes in world space
+type OBB struct {
+	Center      Vec3D
+	HalfExtents Vec3D
+	Rotation    RotMat3D
+}
+
+func NewOBB(center, halfExtents Vec3D, rot Quaternion) OBB {
+	rot.Normalize()
+
+	return OBB{
+		Center:      center,
+		HalfExtents: halfExtents,
+		Rotation:    rot.ToRotMat3D(),
+	}
+}
+
+func NewOBBFromAABB(b AABB3D) OBB {
+	o := OBB{Center: b.Center(), HalfExtents: b.Extents()}
+	o.Rotation.SetIdentity()
+	o.Rotation.Order = QtSet
+
+	return o
+}
+
+// local axis i in world space
+func (o OBB) Axis(i int) Vec3D {
+	return Vec3D{
+		X: o.Rotation.Mat3D[0][i],
+		Y: o.Rotation.Mat3D[1][i],
+		Z: o.Rotation.Mat3D[2][i],
+	}
+}
+
+func (o OBB) halfAxes() [3]Vec3D {
+	return [3]Vec3D{
+		o.Axis(0).ScalerMulVec(o.HalfExtents.X),
+		o.Axis(1).ScalerMulVec(o.HalfExtents.Y),
+		o.Axis(2).ScalerMulVec(o.HalfExtents.Z),
+	}
+}
+
+// corner i has the sign of local axis k set when bit k of i is set
+func (o OBB) Corners() [8]Vec3D {
+	h := o.halfAxes()
+
+	var out [8]Vec3D
+	for i := range out {
+		p := o.Center
+		for k := 0; k < 3; k++ {
+			if i&(1<<k) != 0 {
+				p.Add(h[k])
+			} else {
+				p.Sub(h[k])
+			}
+		}
+		out[i] = p
+	}
+
+	return out
+}
+
+// half the width of the box measured along dir
+func (o OBB) ProjectedRadius(dir Vec3D) float64 {
+	h := o.halfAxes()
+	return math.Abs(dir.Dot(h[0])) + math.Abs(dir.Dot(h[1])) + math.Abs(dir.Dot(h[2]))
+}
+
+func (o OBB) Bounds() AABB3D {
+	h := o.halfAxes()
+
+	ext := Vec3D{
+		X: math.Abs(h[0].X) + math.Abs(h[1].X) + math.Abs(h[2].X),
+		Y: math.Abs(h[0].Y) + math.Abs(h[1].Y) + math.Abs(h[2].Y),
+		Z: math.Abs(h[0].Z) + math.Abs(h[1].Z) + math.Abs(h[2].Z),
+	}
+
+	return NewAABB3D(o.Center, ext)
+}
+
+// p in box coordinates, the inverse of the rotation is its transpose
+func (o OBB) toLocal(p Vec3D) Vec3D {
+	d := p.SubVec(o.Center)
+
+	return Vec3D{
+		X: d.Dot(o.Axis(0)),
+		Y: d.Dot(o.Axis(1)),
+		Z: d.Dot(o.Axis(2)),
+	}
+}
+
+func (o OBB) ContainsPoint(p Vec3D) bool {
+	l := o.toLocal(p)
+
+	return math.Abs(l.X) <= o.HalfExtents.X &&
+		math.Abs(l.Y) <= o.HalfExtents.Y &&
+		math.Abs(l.Z) <= o.HalfExtents.Z
+}
+
+func (o OBB) ClosestPoint(p Vec3D) Vec3D {
+	l := o.toLocal(p)
+
+	l.X = Clamp(l.X, -o.HalfExtents.X, o.HalfExtents.X)
+	l.Y = Clamp(l.Y, -o.HalfExtents.Y, o.HalfExtents.Y)
+	l.Z = Clamp(l.Z, -o.HalfExtents.Z, o.HalfExtents.Z)
+
+	out := o.Center
+	out.Add(o.Axis(0).ScalerMulVec(l.X))
+	out.Add(o.Axis(1).ScalerMulVec(l.Y))
+	out.Add(o.Axis(2).ScalerMulVec(l.Z))
+
+	return out
+}
+
+func (o OBB) Support(dir Vec3D) Vec3D {
+	out := o.Center
+	for _, h := range o.halfAxes() {
+		if h.Dot(dir) >= 0 {
+			out.Add(h)
+		} else {
+			out.Sub(h)
+		}
+	}
+
+	return out
+}
+
+// Separating axis test over the 3 + 3 face axes and the 9 edge pairs
+func (o OBB) Intersects(b OBB) bool {
+	axes := make([]Vec3D, 0, 15)
+	for i := 0; i < 3; i++ {
+		axes = append(axes, o.Axis(i), b.Axis(i))
+	}
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			c := o.Axis(i).CrossV(b.Axis(j))
+
+			// parallel edges, the face axes already cover this direction
+			if c.Dot(c) > 1e-12 {
+				axes = append(axes, c)
+			}
+		}
+	}
+
+	d := b.Center.SubVec(o.Center)
+	for _, a := range axes {
+		if math.Abs(d.Dot(a)) > o.ProjectedRadius(a)+b.ProjectedRadius(a) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,323 @@
+package golem
+
+import "container/heap"
+
+// Loose octree keyed by user ids. Every node's loose bounds are its cell
+// scaled by Looseness and an item lives in the deepest node whose loose
+// bounds hold its whole box. Items outside the world bounds stay in the root
+type Octree struct {
+	MaxDepth   int
+	BucketSize int
+	Looseness  float64
+
+	root  *octNode
+	items map[int]*octItem
+}
+
+type octNode struct {
+	center   Vec3D
+	half     Vec3D
+	loose    AABB3D
+	depth    int
+	count    int
+	parent   *octNode
+	children *[8]*octNode
+	items    []*octItem
+}
+
+type octItem struct {
+	id   int
+	box  AABB3D
+	node *octNode
+}
+
+// a looseness < 1 uses the usual factor of 2, a leaf holding more than
+// bucketSize items is split unless it is at maxDepth
+func NewOctree(bounds AABB3D, maxDepth, bucketSize int, looseness float64) (*Octree, error) {
+	if bounds.Min.X >= bounds.Max.X || bounds.Min.Y >= bounds.Max.Y || bounds.Min.Z >= bounds.Max.Z {
+		return nil, ErrInvalidBounds
+	}
+
+	if looseness < 1 {
+		looseness = 2
+	}
+
+	o := &Octree{
+		MaxDepth:   max(maxDepth, 0),
+		BucketSize: max(bucketSize, 1),
+		Looseness:  looseness,
+		items:      make(map[int]*octItem),
+	}
+	o.root = o.newNode(bounds.Center(), bounds.Extents(), 0, nil)
+
+	return o, nil
+}
+
+func (o *Octree) newNode(center, half Vec3D, depth int, parent *octNode) *octNode {
+	return &octNode{
+		center: center,
+		half:   half,
+		loose:  NewAABB3D(center, half.ScalerMulVec(o.Looseness)),
+		depth:  depth,
+		parent: parent,
+	}
+}
+
+func (o *Octree) Len() int {
+	return len(o.items)
+}
+
+func (o *Octree) Bounds(id int) (AABB3D, bool) {
+	it, ok := o.items[id]
+	if !ok {
+		return AABB3D{}, false
+	}
+
+	return it.box, true
+}
+
+// Points can be inserted as zero sized boxes
+func (o *Octree) Insert(id int, box AABB3D) error {
+	if _, ok := o.items[id]; ok {
+		return ErrDuplicateID
+	}
+
+	it := &octItem{id: id, box: box}
+	o.items[id] = it
+	o.insert(o.root, it)
+
+	return nil
+}
+
+func (o *Octree) Remove(id int) error {
+	it, ok := o.items[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	delete(o.items, id)
+	o.detach(it)
+
+	return nil
+}
+
+// Items that still belong to the same node are updated in place
+func (o *Octree) Move(id int, box AABB3D) error {
+	it, ok := o.items[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	n := it.node
+	if (n == o.root || n.loose.Contains(box)) && o.childFor(n, box) == nil {
+		it.box = box
+		return nil
+	}
+
+	o.detach(it)
+	it.box = box
+	o.insert(o.root, it)
+
+	return nil
+}
+
+// the child the box would descend into, nil when it has to stay in n
+func (o *Octree) childFor(n *octNode, box AABB3D) *octNode {
+	if n.children == nil {
+		return nil
+	}
+
+	c := box.Center()
+	i := 0
+	if c.X >= n.center.X {
+		i |= 1
+	}
+	if c.Y >= n.center.Y {
+		i |= 2
+	}
+	if c.Z >= n.center.Z {
+		i |= 4
+	}
+
+	child := n.children[i]
+	if !child.loose.Contains(box) {
+		return nil
+	}
+
+	return child
+}
+
+func (o *Octree) insert(n *octNode, it *octItem) {
+	for {
+		n.count++
+
+		child := o.childFor(n, it.box)
+		if child == nil {
+			break
+		}
+		n = child
+	}
+
+	it.node = n
+	n.items = append(n.items, it)
+
+	if n.children == nil && len(n.items) > o.BucketSize && n.depth < o.MaxDepth {
+		o.split(n)
+	}
+}
+
+func (o *Octree) split(n *octNode) {
+	h := n.half.ScalerMulVec(0.5)
+
+	n.children = &[8]*octNode{}
+	for i := 0; i < 8; i++ {
+		c := n.center
+		if i&1 == 0 {
+			c.X -= h.X
+		} else {
+			c.X += h.X
+		}
+		if i&2 == 0 {
+			c.Y -= h.Y
+		} else {
+			c.Y += h.Y
+		}
+		if i&4 == 0 {
+			c.Z -= h.Z
+		} else {
+			c.Z += h.Z
+		}
+
+		n.children[i] = o.newNode(c, h, n.depth+1, n)
+	}
+
+	items := n.items
+	n.items = nil
+	n.count -= len(items)
+
+	for _, it := range items {
+		// descends at most one level since the children are empty
+		o.insert(n, it)
+	}
+}
+
+func (o *Octree) detach(it *octItem) {
+	n := it.node
+	for i, other := range n.items {
+		if other == it {
+			last := len(n.items) - 1
+			n.items[i] = n.items[last]
+			n.items[last] = nil
+			n.items = n.items[:last]
+			break
+		}
+	}
+	it.node = nil
+
+	// collapse the highest ancestor whose subtree fits in one bucket
+	var merge *octNode
+	for p := n; p != nil; p = p.parent {
+		p.count--
+		if p.children != nil && p.count <= o.BucketSize {
+			merge = p
+		}
+	}
+
+	if merge != nil {
+		o.collapse(merge)
+	}
+}
+
+func (o *Octree) collapse(n *octNode) {
+	for _, c := range n.children {
+		o.gather(c, n)
+	}
+	n.children = nil
+}
+
+func (o *Octree) gather(n, into *octNode) {
+	for _, it := range n.items {
+		it.node = into
+		into.items = append(into.items, it)
+	}
+
+	if n.children != nil {
+		for _, c := range n.children {
+			o.gather(c, into)
+		}
+	}
+}
+
+// ids of the items whose boxes overlap box
+func (o *Octree) QueryAABB(box AABB3D) []int {
+	return o.query(func(b AABB3D) bool {
+		return b.Intersects(box)
+	})
+}
+
+func (o *Octree) QuerySphere(s Sphere) []int {
+	return o.query(func(b AABB3D) bool {
+		return b.IntersectsSphere(s)
+	})
+}
+
+func (o *Octree) query(overlaps func(AABB3D) bool) []int {
+	out := make([]int, 0)
+
+	stack := []*octNode{o.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// the root may hold items outside the world bounds
+		if n != o.root && !overlaps(n.loose) {
+			continue
+		}
+
+		for _, it := range n.items {
+			if overlaps(it.box) {
+				out = append(out, it.id)
+			}
+		}
+
+		if n.children != nil {
+			stack = append(stack, n.children[:]...)
+		}
+	}
+
+	return out
+}
+
+// The k items closest to p measured to their boxes, nearest first and
+// ties broken by id
+func (o *Octree) KNearest(p Vec3D, k int) []int {
+	out := make([]int, 0, k)
+	if k <= 0 {
+		return out
+	}
+
+	h := &knnHeap{{node: o.root}}
+	for h.Len() > 0 && len(out) < k {
+		e := heap.Pop(h).(knnEntry)
+
+		n, ok := e.node.(*octNode)
+		if !ok {
+			out = append(out, e.id)
+			continue
+		}
+
+		for _, it := range n.items {
+			heap.Push(h, knnEntry{dist: p.Dist(it.box.ClosestPoint(p)), id: it.id})
+		}
+
+		if n.children != nil {
+			for _, c := range n.children {
+				if c.count > 0 {
+					heap.Push(h, knnEntry{dist: p.Dist(c.loose.ClosestPoint(p)), node: c})
+				}
+			}
+		}
+	}
+
+	return out
+}
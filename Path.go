@@ -0,0 +1,147 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// Polyline through Points as a Curve3D, segment i covers params [i, i+1]
+type Path3D struct {
+	Points []Vec3D
+
+	lengths []float64
+}
+
+func NewPath3D(points []Vec3D) (*Path3D, error) {
+	if len(points) < 2 {
+		return nil, ErrInvalidLen
+	}
+
+	p := &Path3D{Points: points}
+	p.Update()
+
+	return p, nil
+}
+
+// recomputes the cached lengths after Points was edited in place
+func (p *Path3D) Update() {
+	p.lengths = append(p.lengths[:0], 0)
+	for i := 1; i < len(p.Points); i++ {
+		p.lengths = append(p.lengths, p.lengths[i-1]+p.Points[i-1].Dist(p.Points[i]))
+	}
+}
+
+func (p *Path3D) Segments() int {
+	return len(p.Points) - 1
+}
+
+func (p *Path3D) Length() float64 {
+	return p.lengths[len(p.lengths)-1]
+}
+
+func (p *Path3D) Domain() (float64, float64) {
+	return 0, float64(p.Segments())
+}
+
+func (p *Path3D) At(t float64) Vec3D {
+	i, u := splineLocate(t, p.Segments())
+
+	a := p.Points[i]
+	return a.AddVec(p.Points[i+1].SubVec(a).ScalerMulVec(u))
+}
+
+// constant along each segment, a vertex takes the segment after it
+func (p *Path3D) Derivative(t float64) Vec3D {
+	i, _ := splineLocate(t, p.Segments())
+
+	return p.Points[i+1].SubVec(p.Points[i])
+}
+
+func (p *Path3D) SecondDerivative(t float64) Vec3D {
+	return Vec3D{}
+}
+
+// exact table with one breakpoint per vertex
+func (p *Path3D) ArcLengthTable() *ArcLengthTable {
+	params := make([]float64, len(p.Points))
+	for i := range params {
+		params[i] = float64(i)
+	}
+
+	return &ArcLengthTable{
+		Params:  params,
+		Lengths: append([]float64(nil), p.lengths...),
+		speed: func(t float64) float64 {
+			d := p.Derivative(t)
+			return d.Length()
+		},
+	}
+}
+
+// point at arc length s from the first vertex, s is clamped to the path
+func (p *Path3D) PointAtDistance(s float64) Vec3D {
+	return p.At(p.ParamAtDistance(s))
+}
+
+func (p *Path3D) ParamAtDistance(s float64) float64 {
+	if s <= 0 {
+		return 0
+	}
+	if s >= p.Length() {
+		return float64(p.Segments())
+	}
+
+	i := sort.SearchFloat64s(p.lengths, s)
+	seg := p.lengths[i] - p.lengths[i-1]
+	if seg == 0 {
+		return float64(i)
+	}
+
+	return float64(i-1) + (s-p.lengths[i-1])/seg
+}
+
+// arc length from the first vertex to param t
+func (p *Path3D) DistanceAtParam(t float64) float64 {
+	i, u := splineLocate(t, p.Segments())
+
+	return p.lengths[i] + u*(p.lengths[i+1]-p.lengths[i])
+}
+
+// New path with count vertices spaced evenly along this one, corners
+// between the samples are cut
+func (p *Path3D) Resample(count int) (*Path3D, error) {
+	if count < 2 {
+		return nil, ErrInvalidLen
+	}
+
+	points := make([]Vec3D, count)
+	for i := range points {
+		points[i] = p.PointAtDistance(p.Length() * float64(i) / float64(count-1))
+	}
+
+	return NewPath3D(points)
+}
+
+// Param and point on the path closest to q
+func (p *Path3D) ClosestPoint(q Vec3D) (float64, Vec3D) {
+	best, bestDist := 0.0, math.Inf(1)
+
+	for i := 0; i < p.Segments(); i++ {
+		a := p.Points[i]
+		ab := p.Points[i+1].SubVec(a)
+
+		aq := q.SubVec(a)
+
+		u := 0.0
+		if l := ab.Dot(ab); l > 0 {
+			u = Clamp(aq.Dot(ab)/l, 0, 1)
+		}
+
+		c := a.AddVec(ab.ScalerMulVec(u))
+		if d := q.Dist(c); d < bestDist {
+			best, bestDist = float64(i)+u, d
+		}
+	}
+
+	return best, p.At(best)
+}
@@ -0,0 +1,76 @@
+package golem
+
+import "math"
+
+// All points p with Normal.p + D == 0, the normal side is the positive side
+type Plane struct {
+	Normal Vec3D
+	D      float64
+}
+
+func NewPlane(normal, point Vec3D) (Plane, error) {
+	if _, err := normal.Normalize(); err != nil {
+		return Plane{}, err
+	}
+
+	return Plane{
+		Normal: normal,
+		D:      -normal.Dot(point),
+	}, nil
+}
+
+// normal follows the right hand rule for a -> b -> c
+func NewPlaneFromPoints(a, b, c Vec3D) (Plane, error) {
+	n := b.SubVec(a).CrossV(c.SubVec(a))
+	if _, err := n.Normalize(); err != nil {
+		return Plane{}, ErrDegenerateTriangle
+	}
+
+	return Plane{
+		Normal: n,
+		D:      -n.Dot(a),
+	}, nil
+}
+
+// scales the plane so the normal has unit length
+func (p *Plane) Normalize() error {
+	l, err := p.Normal.Normalize()
+	if err != nil {
+		return err
+	}
+
+	p.D /= l
+	return nil
+}
+
+func (p Plane) SignedDistance(pt Vec3D) float64 {
+	return p.Normal.Dot(pt) + p.D
+}
+
+func (p Plane) ProjectPoint(pt Vec3D) Vec3D {
+	return pt.SubVec(p.Normal.ScalerMulVec(p.SignedDistance(pt)))
+}
+
+// returns the ray param of the hit, parallel rays never hit
+func (p Plane) IntersectRay(r Ray3D) (float64, bool) {
+	den := p.Normal.Dot(r.Dir)
+	if math.Abs(den) < 1e-12 {
+		return 0, false
+	}
+
+	t := -p.SignedDistance(r.Origin) / den
+	if t < 0 {
+		return 0, false
+	}
+
+	return t, true
+}
+
+// true when the whole box lies on the negative side
+func (p Plane) IsAABBBehind(b AABB3D) bool {
+	return p.SignedDistance(b.Support(p.Normal)) < 0
+}
+
+func (p Plane) IsSphereBehind(s Sphere) bool {
+	return p.SignedDistance(s.Center) < -s.Radius
+}
@@ -0,0 +1,519 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// Shoelace formula, positive for counter clockwise winding
+func PolygonSignedArea(poly []Vec2D) float64 {
+	area := 0.0
+	n := len(poly)
+
+	for i := 0; i < n; i++ {
+		area += poly[i].Cross2D(poly[(i+1)%n])
+	}
+
+	return area / 2
+}
+
+func PolygonArea(poly []Vec2D) float64 {
+	return math.Abs(PolygonSignedArea(poly))
+}
+
+func PolygonCentroid(poly []Vec2D) (Vec2D, error) {
+	if len(poly) < 3 {
+		return Vec2D{}, ErrInvalidLen
+	}
+
+	area := PolygonSignedArea(poly)
+	if area == 0 {
+		return Vec2D{}, ErrDegeneratePolygon
+	}
+
+	c := Vec2D{}
+	n := len(poly)
+
+	for i := 0; i < n; i++ {
+		p := poly[i]
+		q := poly[(i+1)%n]
+		cross := p.Cross2D(q)
+
+		c.X += (p.X + q.X) * cross
+		c.Y += (p.Y + q.Y) * cross
+	}
+
+	c.ScalerDiv(6 * area)
+	return c, nil
+}
+
+func IsPolygonCCW(poly []Vec2D) bool {
+	return PolygonSignedArea(poly) > 0
+}
+
+// returns a new slice with the winding order reversed
+func ReversePolygon(poly []Vec2D) []Vec2D {
+	out := make([]Vec2D, len(poly))
+	for i, p := range poly {
+		out[len(poly)-1-i] = p
+	}
+
+	return out
+}
+
+// returns the polygon with counter clockwise winding, reversing a copy if needed
+func PolygonToCCW(poly []Vec2D) []Vec2D {
+	if PolygonSignedArea(poly) < 0 {
+		return ReversePolygon(poly)
+	}
+
+	return poly
+}
+
+// returns the polygon with clockwise winding, reversing a copy if needed
+func PolygonToCW(poly []Vec2D) []Vec2D {
+	if PolygonSignedArea(poly) > 0 {
+		return ReversePolygon(poly)
+	}
+
+	return poly
+}
+
+// Even-odd crossing test, works for concave and self intersecting polygons
+func PointInPolygon(p Vec2D, poly []Vec2D) bool {
+	inside := false
+	n := len(poly)
+
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a := poly[i]
+		b := poly[j]
+
+		if (a.Y > p.Y) != (b.Y > p.Y) {
+			x := a.X + ((p.Y-a.Y)/(b.Y-a.Y))*(b.X-a.X)
+			if p.X < x {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// Andrew's monotone chain, returns the hull in counter clockwise order
+// without collinear points
+func ConvexHull2D(points []Vec2D) []Vec2D {
+	if len(points) < 3 {
+		out := make([]Vec2D, len(points))
+		copy(out, points)
+		return out
+	}
+
+	pts := make([]Vec2D, len(points))
+	copy(pts, points)
+
+	sort.Slice(pts, func(i, j int) bool {
+		if pts[i].X == pts[j].X {
+			return pts[i].Y < pts[j].Y
+		}
+		return pts[i].X < pts[j].X
+	})
+
+	hull := make([]Vec2D, 0, 2*len(pts))
+
+	for _, p := range pts {
+		for len(hull) >= 2 && orient2D(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	lower := len(hull) + 1
+	for i := len(pts) - 2; i >= 0; i-- {
+		p := pts[i]
+		for len(hull) >= lower && orient2D(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	return hull[:len(hull)-1]
+}
+
+// Douglas-Peucker on an open polyline, keeps both end points
+func SimplifyPolyline(points []Vec2D, epsilon float64) []Vec2D {
+	if len(points) < 3 {
+		out := make([]Vec2D, len(points))
+		copy(out, points)
+		return out
+	}
+
+	keep := make([]bool, len(points))
+	keep[0] = true
+	keep[len(points)-1] = true
+
+	douglasPeucker(points, 0, len(points)-1, epsilon, keep)
+
+	out := make([]Vec2D, 0, len(points))
+	for i, k := range keep {
+		if k {
+			out = append(out, points[i])
+		}
+	}
+
+	return out
+}
+
+// Douglas-Peucker on a closed polygon, the ring is split at the first vertex
+// and the vertex farthest from it so the result stays closed
+func SimplifyPolygon(poly []Vec2D, epsilon float64) []Vec2D {
+	n := len(poly)
+	if n < 4 {
+		out := make([]Vec2D, n)
+		copy(out, poly)
+		return out
+	}
+
+	far := 0
+	farDist := -1.0
+	for i := 1; i < n; i++ {
+		if d := poly[0].Dist(poly[i]); d > farDist {
+			far = i
+			farDist = d
+		}
+	}
+
+	ring := make([]Vec2D, n+1)
+	copy(ring, poly)
+	ring[n] = poly[0]
+
+	keep := make([]bool, n+1)
+	keep[0] = true
+	keep[far] = true
+
+	douglasPeucker(ring, 0, far, epsilon, keep)
+	douglasPeucker(ring, far, n, epsilon, keep)
+
+	out := make([]Vec2D, 0, n)
+	for i := 0; i < n; i++ {
+		if keep[i] {
+			out = append(out, ring[i])
+		}
+	}
+
+	return out
+}
+
+func douglasPeucker(points []Vec2D, first, last int, epsilon float64, keep []bool) {
+	if last-first < 2 {
+		return
+	}
+
+	idx := -1
+	maxDist := epsilon
+
+	for i := first + 1; i < last; i++ {
+		if d := pointSegmentDist2D(points[i], points[first], points[last]); d > maxDist {
+			idx = i
+			maxDist = d
+		}
+	}
+
+	if idx == -1 {
+		return
+	}
+
+	keep[idx] = true
+	douglasPeucker(points, first, idx, epsilon, keep)
+	douglasPeucker(points, idx, last, epsilon, keep)
+}
+
+// Offsets every edge along its outward normal by dist, so positive values grow
+// the polygon and negative values shrink it irrespective of the winding.
+// Corners sharper than miterLimit (as a multiple of dist) are beveled
+func OffsetPolygon(poly []Vec2D, dist, miterLimit float64) []Vec2D {
+	n := len(poly)
+	if n < 3 || dist == 0 {
+		out := make([]Vec2D, n)
+		copy(out, poly)
+		return out
+	}
+
+	// outward normal is the right perpendicular for counter clockwise polygons
+	sign := 1.0
+	if PolygonSignedArea(poly) < 0 {
+		sign = -1
+	}
+
+	normals := make([]Vec2D, n)
+	for i := 0; i < n; i++ {
+		e := poly[(i+1)%n].SubVec(poly[i])
+		e.Normalize()
+		normals[i] = e.RightPerpendicular().ScalerMulVec(sign)
+	}
+
+	out := make([]Vec2D, 0, n)
+	for i := 0; i < n; i++ {
+		n0 := normals[(i+n-1)%n]
+		n1 := normals[i]
+
+		// the miter m satisfies m.n0 == m.n1 == 1, its length is 1 / cos(half angle)
+		den := 1 + n0.Dot(n1)
+		miter := n0.AddVec(n1)
+
+		if den < 1e-12 || miter.Length()/den > miterLimit {
+			out = append(out, poly[i].AddVec(n0.ScalerMulVec(dist)))
+			out = append(out, poly[i].AddVec(n1.ScalerMulVec(dist)))
+			continue
+		}
+
+		miter.ScalerMul(dist / den)
+		out = append(out, poly[i].AddVec(miter))
+	}
+
+	return out
+}
+
+// Ear clipping, holes are bridged into the outer boundary first.
+// Returns the triangles as indices into the vertices of outer followed by
+// the vertices of each hole in order, all triangles are counter clockwise
+func TriangulatePolygon(outer []Vec2D, holes [][]Vec2D) ([][3]int, error) {
+	if len(outer) < 3 {
+		return nil, ErrInvalidLen
+	}
+
+	verts := make([]Vec2D, 0, len(outer))
+	verts = append(verts, outer...)
+
+	ring := make([]int, len(outer))
+	for i := range ring {
+		ring[i] = i
+	}
+
+	if PolygonSignedArea(outer) < 0 {
+		reverseInts(ring)
+	}
+
+	type holeRing struct {
+		idx  []int
+		maxX int
+	}
+
+	hs := make([]holeRing, 0, len(holes))
+	for _, h := range holes {
+		if len(h) < 3 {
+			return nil, ErrInvalidLen
+		}
+
+		start := len(verts)
+		verts = append(verts, h...)
+
+		idx := make([]int, len(h))
+		for i := range idx {
+			idx[i] = start + i
+		}
+
+		if PolygonSignedArea(h) > 0 {
+			reverseInts(idx)
+		}
+
+		maxX := 0
+		for i := range idx {
+			if verts[idx[i]].X > verts[idx[maxX]].X {
+				maxX = i
+			}
+		}
+
+		hs = append(hs, holeRing{idx: idx, maxX: maxX})
+	}
+
+	sort.SliceStable(hs, func(i, j int) bool {
+		return verts[hs[i].idx[hs[i].maxX]].X > verts[hs[j].idx[hs[j].maxX]].X
+	})
+
+	for _, h := range hs {
+		var err error
+		ring, err = bridgeHole(verts, ring, h.idx, h.maxX)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return earClip(verts, ring)
+}
+
+// Eberly's method, joins the hole to a mutually visible vertex of the ring
+func bridgeHole(verts []Vec2D, ring, hole []int, holeStart int) ([]int, error) {
+	m := verts[hole[holeStart]]
+
+	hitX := math.Inf(1)
+	edge := -1
+
+	n := len(ring)
+	for i := 0; i < n; i++ {
+		a := verts[ring[i]]
+		b := verts[ring[(i+1)%n]]
+
+		// ring is ccw so only edges going upwards face the inside
+		// when looking along +x
+		if a.Y > m.Y || b.Y < m.Y || a.Y == b.Y {
+			continue
+		}
+
+		x := a.X + ((m.Y-a.Y)/(b.Y-a.Y))*(b.X-a.X)
+		if x >= m.X && x < hitX {
+			hitX = x
+			edge = i
+		}
+	}
+
+	if edge == -1 {
+		return nil, ErrTriangulation
+	}
+
+	i := Vec2D{X: hitX, Y: m.Y}
+	a := ring[edge]
+	b := ring[(edge+1)%n]
+
+	best := edge
+	if verts[b].X > verts[a].X {
+		best = (edge + 1) % n
+	}
+
+	p := verts[ring[best]]
+	if !p.IsEqual(i) {
+		tri := Triangle2D{A: m, B: i, C: p}
+		if !tri.IsCCW() {
+			tri = Triangle2D{A: m, B: p, C: i}
+		}
+
+		bestAngle := math.Inf(1)
+		for k := 0; k < n; k++ {
+			v := verts[ring[k]]
+			if k == best || !isReflex(verts, ring, k) || !tri.ContainsPoint(v) {
+				continue
+			}
+
+			d := v.SubVec(m)
+			angle := math.Abs(math.Atan2(d.Y, d.X))
+			if angle < bestAngle || (angle == bestAngle && d.Length() < verts[ring[best]].Dist(m)) {
+				best = k
+				bestAngle = angle
+			}
+		}
+	}
+
+	out := make([]int, 0, n+len(hole)+2)
+	out = append(out, ring[:best+1]...)
+	for k := 0; k <= len(hole); k++ {
+		out = append(out, hole[(holeStart+k)%len(hole)])
+	}
+	out = append(out, ring[best])
+	out = append(out, ring[best+1:]...)
+
+	return out, nil
+}
+
+func earClip(verts []Vec2D, ring []int) ([][3]int, error) {
+	idx := make([]int, len(ring))
+	copy(idx, ring)
+
+	tris := make([][3]int, 0, len(idx)-2)
+
+	for len(idx) > 3 {
+		ear := -1
+
+		for i := range idx {
+			if isEar(verts, idx, i) {
+				ear = i
+				break
+			}
+		}
+
+		// No proper ear, happens with collinear or touching vertices
+		if ear == -1 {
+			for i := range idx {
+				n := len(idx)
+				if orient2D(verts[idx[(i+n-1)%n]], verts[idx[i]], verts[idx[(i+1)%n]]) >= 0 {
+					ear = i
+					break
+				}
+			}
+		}
+
+		if ear == -1 {
+			return nil, ErrTriangulation
+		}
+
+		n := len(idx)
+		a := idx[(ear+n-1)%n]
+		b := idx[ear]
+		c := idx[(ear+1)%n]
+
+		if orient2D(verts[a], verts[b], verts[c]) > 0 {
+			tris = append(tris, [3]int{a, b, c})
+		}
+
+		idx = append(idx[:ear], idx[ear+1:]...)
+	}
+
+	if orient2D(verts[idx[0]], verts[idx[1]], verts[idx[2]]) > 0 {
+		tris = append(tris, [3]int{idx[0], idx[1], idx[2]})
+	}
+
+	return tris, nil
+}
+
+func isEar(verts []Vec2D, idx []int, i int) bool {
+	n := len(idx)
+	a := verts[idx[(i+n-1)%n]]
+	b := verts[idx[i]]
+	c := verts[idx[(i+1)%n]]
+
+	if orient2D(a, b, c) <= 0 {
+		return false
+	}
+
+	tri := Triangle2D{A: a, B: b, C: c}
+
+	for k := 0; k < n; k++ {
+		p := verts[idx[k]]
+		if p.IsEqual(a) || p.IsEqual(b) || p.IsEqual(c) {
+			continue
+		}
+
+		if tri.ContainsPoint(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isReflex(verts []Vec2D, ring []int, i int) bool {
+	n := len(ring)
+	return orient2D(verts[ring[(i+n-1)%n]], verts[ring[i]], verts[ring[(i+1)%n]]) <= 0
+}
+
+func reverseInts(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// positive when a -> b -> c turns counter clockwise
+func orient2D(a, b, c Vec2D) float64 {
+	return ((b.X - a.X) * (c.Y - a.Y)) - ((b.Y - a.Y) * (c.X - a.X))
+}
+
+func pointSegmentDist2D(p, a, b Vec2D) float64 {
+	ab := b.SubVec(a)
+	lenSq := ab.Dot(ab)
+	if lenSq == 0 {
+		return p.Dist(a)
+	}
+
+	ap := p.SubVec(a)
+	t := Clamp(ap.Dot(ab)/lenSq, 0, 1)
+
+	return p.Dist(a.AddVec(ab.ScalerMulVec(t)))
+}
@@ -0,0 +1,511 @@
+package golem
+
+import (
+	"math"
+	"sort"
+)
+
+// Polygon with optional holes, winding of the input rings does not matter.
+// Results of boolean operations always have ccw outers and cw holes
+type Polygon2D struct {
+	Outer []Vec2D
+	Holes [][]Vec2D
+}
+
+type BooleanOp int
+
+const (
+	BoolUnion BooleanOp = iota
+	BoolIntersection
+	BoolDifference
+	BoolXor
+)
+
+// tolerance on the edge params for snapping intersections onto vertices
+const boolEps = 1e-9
+
+type boolEdge struct {
+	a, b  Vec2D
+	owner int
+}
+
+type boolSplit struct {
+	t float64
+	p Vec2D
+}
+
+type boolPointPair struct {
+	a, b Vec2D
+}
+
+func (p Polygon2D) Area() float64 {
+	area := PolygonArea(p.Outer)
+	for _, h := range p.Holes {
+		area -= PolygonArea(h)
+	}
+
+	return area
+}
+
+// Even-odd test against the outer and all the holes
+func (p Polygon2D) ContainsPoint(pt Vec2D) bool {
+	if !PointInPolygon(pt, p.Outer) {
+		return false
+	}
+
+	for _, h := range p.Holes {
+		if PointInPolygon(pt, h) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func PolygonUnion(a, b []Polygon2D) []Polygon2D {
+	return PolygonBoolean(a, b, BoolUnion)
+}
+
+func PolygonIntersection(a, b []Polygon2D) []Polygon2D {
+	return PolygonBoolean(a, b, BoolIntersection)
+}
+
+// a - b
+func PolygonDifference(a, b []Polygon2D) []Polygon2D {
+	return PolygonBoolean(a, b, BoolDifference)
+}
+
+func PolygonXor(a, b []Polygon2D) []Polygon2D {
+	return PolygonBoolean(a, b, BoolXor)
+}
+
+// Polygons inside a single set are expected not to overlap each other.
+// Edges are split at every intersection, each piece is classified against
+// the other set and the kept pieces are linked back into rings. Shared
+// (collinear overlapping) edges are resolved by their direction so the
+// result is the same for every run on the same input
+func PolygonBoolean(a, b []Polygon2D, op BooleanOp) []Polygon2D {
+	edges := make([]boolEdge, 0)
+	edges = appendBoolEdges(edges, a, 0)
+	edges = appendBoolEdges(edges, b, 1)
+
+	splits := make([][]boolSplit, len(edges))
+	for i := 0; i < len(edges); i++ {
+		for j := i + 1; j < len(edges); j++ {
+			intersectBoolEdges(edges, splits, i, j)
+		}
+	}
+
+	pieces := make([]boolEdge, 0, len(edges))
+	for i, e := range edges {
+		pieces = appendBoolPieces(pieces, e, splits[i])
+	}
+
+	owned := [2]map[boolPointPair]bool{{}, {}}
+	for _, e := range pieces {
+		owned[e.owner][boolPointPair{e.a, e.b}] = true
+	}
+
+	rings := [2][][]Vec2D{boolRings(a), boolRings(b)}
+
+	kept := make([]boolEdge, 0, len(pieces))
+	for _, e := range pieces {
+		other := 1 - e.owner
+
+		same := owned[other][boolPointPair{e.a, e.b}]
+		opposite := owned[other][boolPointPair{e.b, e.a}]
+
+		if same || opposite {
+			// shared edges are only ever taken from a
+			if e.owner != 0 {
+				continue
+			}
+
+			if (same && (op == BoolUnion || op == BoolIntersection)) || (opposite && op == BoolDifference) {
+				kept = append(kept, e)
+			}
+			continue
+		}
+
+		mid := Vec2D{X: (e.a.X + e.b.X) / 2, Y: (e.a.Y + e.b.Y) / 2}
+		inside := boolPointInRings(mid, rings[other])
+
+		switch op {
+		case BoolUnion:
+			if !inside {
+				kept = append(kept, e)
+			}
+
+		case BoolIntersection:
+			if inside {
+				kept = append(kept, e)
+			}
+
+		case BoolDifference:
+			if e.owner == 0 && !inside {
+				kept = append(kept, e)
+			} else if e.owner == 1 && inside {
+				kept = append(kept, boolEdge{a: e.b, b: e.a, owner: e.owner})
+			}
+
+		case BoolXor:
+			if inside {
+				kept = append(kept, boolEdge{a: e.b, b: e.a, owner: e.owner})
+			} else {
+				kept = append(kept, e)
+			}
+		}
+	}
+
+	return groupBoolRings(linkBoolEdges(cancelBoolEdges(kept)))
+}
+
+// Sutherland-Hodgman, clips subject against a convex window of any winding.
+// Points lying on the window boundary are kept
+func ClipPolygonConvex(subject, window []Vec2D) []Vec2D {
+	if len(subject) < 3 || len(window) < 3 {
+		return nil
+	}
+
+	window = PolygonToCCW(window)
+
+	out := make([]Vec2D, len(subject))
+	copy(out, subject)
+
+	for i := range window {
+		if len(out) == 0 {
+			break
+		}
+
+		a := window[i]
+		b := window[(i+1)%len(window)]
+
+		in := out
+		out = make([]Vec2D, 0, len(in)+1)
+
+		prev := in[len(in)-1]
+		prevSide := orient2D(a, b, prev)
+
+		for _, cur := range in {
+			curSide := orient2D(a, b, cur)
+
+			if curSide >= 0 {
+				if prevSide < 0 {
+					out = append(out, lineIntersect2D(prev, cur, prevSide, curSide))
+				}
+				out = append(out, cur)
+			} else if prevSide >= 0 {
+				if prevSide > 0 {
+					out = append(out, lineIntersect2D(prev, cur, prevSide, curSide))
+				}
+			}
+
+			prev = cur
+			prevSide = curSide
+		}
+	}
+
+	if len(out) < 3 {
+		return nil
+	}
+
+	return out
+}
+
+// point where the segment p -> q crosses the line, given the signed
+// distances (up to a common scale) of p and q from it
+func lineIntersect2D(p, q Vec2D, dp, dq float64) Vec2D {
+	t := dp / (dp - dq)
+
+	return Vec2D{
+		X: p.X + (t * (q.X - p.X)),
+		Y: p.Y + (t * (q.Y - p.Y)),
+	}
+}
+
+func boolRings(polys []Polygon2D) [][]Vec2D {
+	out := make([][]Vec2D, 0, len(polys))
+	for _, p := range polys {
+		out = append(out, p.Outer)
+		out = append(out, p.Holes...)
+	}
+
+	return out
+}
+
+func boolPointInRings(p Vec2D, rings [][]Vec2D) bool {
+	inside := false
+	for _, r := range rings {
+		if PointInPolygon(p, r) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// directed edges with the filled region on the left
+func appendBoolEdges(edges []boolEdge, polys []Polygon2D, owner int) []boolEdge {
+	addRing := func(ring []Vec2D) {
+		n := len(ring)
+		for i := 0; i < n; i++ {
+			a := ring[i]
+			b := ring[(i+1)%n]
+			if a.IsNotEqual(b) {
+				edges = append(edges, boolEdge{a: a, b: b, owner: owner})
+			}
+		}
+	}
+
+	for _, p := range polys {
+		if len(p.Outer) < 3 {
+			continue
+		}
+
+		addRing(PolygonToCCW(p.Outer))
+		for _, h := range p.Holes {
+			if len(h) >= 3 {
+				addRing(PolygonToCW(h))
+			}
+		}
+	}
+
+	return edges
+}
+
+func intersectBoolEdges(edges []boolEdge, splits [][]boolSplit, i, j int) {
+	p1, p2 := edges[i].a, edges[i].b
+	q1, q2 := edges[j].a, edges[j].b
+
+	r := p2.SubVec(p1)
+	s := q2.SubVec(q1)
+	qp := q1.SubVec(p1)
+
+	rr := r.Dot(r)
+	ss := s.Dot(s)
+	denom := r.Cross2D(s)
+
+	if math.Abs(denom) <= boolEps*math.Sqrt(rr*ss) {
+		// parallel, only collinear overlaps need splitting
+		if math.Abs(qp.Cross2D(r)) > boolEps*rr {
+			return
+		}
+
+		addBoolSplitOnto(splits, i, p1, r, rr, q1)
+		addBoolSplitOnto(splits, i, p1, r, rr, q2)
+		addBoolSplitOnto(splits, j, q1, s, ss, p1)
+		addBoolSplitOnto(splits, j, q1, s, ss, p2)
+		return
+	}
+
+	t := qp.Cross2D(s) / denom
+	u := qp.Cross2D(r) / denom
+
+	if t < -boolEps || t > 1+boolEps || u < -boolEps || u > 1+boolEps {
+		return
+	}
+
+	// snap onto existing vertices so both edges share the exact same point
+	var p Vec2D
+	switch {
+	case t <= boolEps:
+		p = p1
+	case t >= 1-boolEps:
+		p = p2
+	case u <= boolEps:
+		p = q1
+	case u >= 1-boolEps:
+		p = q2
+	default:
+		p = p1.AddVec(r.ScalerMulVec(t))
+	}
+
+	if t > boolEps && t < 1-boolEps {
+		splits[i] = append(splits[i], boolSplit{t: t, p: p})
+	}
+
+	if u > boolEps && u < 1-boolEps {
+		splits[j] = append(splits[j], boolSplit{t: u, p: p})
+	}
+}
+
+// splits edge k starting at a with direction d at point p if p is strictly inside it
+func addBoolSplitOnto(splits [][]boolSplit, k int, a, d Vec2D, dd float64, p Vec2D) {
+	ap := p.SubVec(a)
+	t := ap.Dot(d) / dd
+
+	if t > boolEps && t < 1-boolEps {
+		splits[k] = append(splits[k], boolSplit{t: t, p: p})
+	}
+}
+
+func appendBoolPieces(pieces []boolEdge, e boolEdge, splits []boolSplit) []boolEdge {
+	sort.SliceStable(splits, func(i, j int) bool {
+		return splits[i].t < splits[j].t
+	})
+
+	start := e.a
+	for _, sp := range splits {
+		if sp.p.IsEqual(start) || sp.p.IsEqual(e.b) {
+			continue
+		}
+
+		pieces = append(pieces, boolEdge{a: start, b: sp.p, owner: e.owner})
+		start = sp.p
+	}
+
+	return append(pieces, boolEdge{a: start, b: e.b, owner: e.owner})
+}
+
+// an edge and its reverse bound the filled region on both sides, drop both
+func cancelBoolEdges(edges []boolEdge) []boolEdge {
+	count := make(map[boolPointPair]int, len(edges))
+	for _, e := range edges {
+		count[boolPointPair{e.a, e.b}]++
+	}
+
+	out := make([]boolEdge, 0, len(edges))
+	for _, e := range edges {
+		key := boolPointPair{e.a, e.b}
+		rev := boolPointPair{e.b, e.a}
+
+		if count[rev] > 0 && count[key] > 0 {
+			count[rev]--
+			count[key]--
+			continue
+		}
+
+		if count[key] > 0 {
+			count[key]--
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// At vertices with several outgoing edges the sharpest left turn is taken,
+// so rings touching at a single vertex come out as separate rings
+func linkBoolEdges(edges []boolEdge) [][]Vec2D {
+	outgoing := make(map[Vec2D][]int, len(edges))
+	for i, e := range edges {
+		outgoing[e.a] = append(outgoing[e.a], i)
+	}
+
+	used := make([]bool, len(edges))
+	rings := make([][]Vec2D, 0)
+
+	for i := range edges {
+		if used[i] {
+			continue
+		}
+
+		ring := make([]Vec2D, 0)
+		start := edges[i].a
+		cur := i
+		closed := false
+
+		for {
+			used[cur] = true
+			ring = append(ring, edges[cur].a)
+
+			end := edges[cur].b
+			if end.IsEqual(start) {
+				closed = true
+				break
+			}
+
+			dir := end.SubVec(edges[cur].a)
+			next := -1
+			bestTurn := math.Inf(-1)
+
+			for _, k := range outgoing[end] {
+				if used[k] {
+					continue
+				}
+
+				out := edges[k].b.SubVec(end)
+				turn := math.Atan2(dir.Cross2D(out), dir.Dot(out))
+				if turn > bestTurn {
+					bestTurn = turn
+					next = k
+				}
+			}
+
+			if next == -1 {
+				break
+			}
+
+			cur = next
+		}
+
+		if closed {
+			if ring = removeCollinear(ring); len(ring) >= 3 {
+				rings = append(rings, ring)
+			}
+		}
+	}
+
+	return rings
+}
+
+func removeCollinear(ring []Vec2D) []Vec2D {
+	for changed := true; changed && len(ring) >= 3; {
+		changed = false
+		n := len(ring)
+
+		for i := 0; i < n; i++ {
+			a := ring[(i+n-1)%n]
+			b := ring[i]
+			c := ring[(i+1)%n]
+
+			ab := b.SubVec(a)
+			bc := c.SubVec(b)
+
+			if ab.Cross2D(bc) == 0 && ab.Dot(bc) >= 0 {
+				ring = append(ring[:i], ring[i+1:]...)
+				changed = true
+				break
+			}
+		}
+	}
+
+	return ring
+}
+
+// ccw rings are outers, each cw ring goes to the smallest outer containing it
+func groupBoolRings(rings [][]Vec2D) []Polygon2D {
+	out := make([]Polygon2D, 0)
+	areas := make([]float64, 0)
+	holes := make([][]Vec2D, 0)
+
+	for _, r := range rings {
+		area := PolygonSignedArea(r)
+		if area > 0 {
+			out = append(out, Polygon2D{Outer: r})
+			areas = append(areas, area)
+		} else if area < 0 {
+			holes = append(holes, r)
+		}
+	}
+
+	for _, h := range holes {
+		// a point just inside the hole next to its first edge
+		e := h[1].SubVec(h[0])
+		mid := Vec2D{X: (h[0].X + h[1].X) / 2, Y: (h[0].Y + h[1].Y) / 2}
+		sample := mid.AddVec(e.RightPerpendicular().ScalerMulVec(1e-6))
+
+		best := -1
+		for i := range out {
+			if (best == -1 || areas[i] < areas[best]) && PointInPolygon(sample, out[i].Outer) {
+				best = i
+			}
+		}
+
+		if best != -1 {
+			out[best].Holes = append(out[best].Holes, h)
+		}
+	}
+
+	return out
+}
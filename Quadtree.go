@@ -0,0 +1,357 @@
+package golem
+
+import "container/heap"
+
+// Loose quadtree keyed by user ids. Every node's loose bounds are its cell
+// scaled by Looseness and an item lives in the deepest node whose loose
+// bounds hold its whole box. Items outside the world bounds stay in the root
+type Quadtree struct {
+	MaxDepth   int
+	BucketSize int
+	Looseness  float64
+
+	root  *quadNode
+	items map[int]*quadItem
+}
+
+type quadNode struct {
+	center   Vec2D
+	half     Vec2D
+	loose    AABB2D
+	depth    int
+	count    int
+	parent   *quadNode
+	children *[4]*quadNode
+	items    []*quadItem
+}
+
+type quadItem struct {
+	id   int
+	box  AABB2D
+	node *quadNode
+}
+
+// a looseness < 1 uses the usual factor of 2, a leaf holding more than
+// bucketSize items is split unless it is at maxDepth
+func NewQuadtree(bounds AABB2D, maxDepth, bucketSize int, looseness float64) (*Quadtree, error) {
+	if bounds.Min.X >= bounds.Max.X || bounds.Min.Y >= bounds.Max.Y {
+		return nil, ErrInvalidBounds
+	}
+
+	if looseness < 1 {
+		looseness = 2
+	}
+
+	q := &Quadtree{
+		MaxDepth:   max(maxDepth, 0),
+		BucketSize: max(bucketSize, 1),
+		Looseness:  looseness,
+		items:      make(map[int]*quadItem),
+	}
+	q.root = q.newNode(bounds.Center(), bounds.Extents(), 0, nil)
+
+	return q, nil
+}
+
+func (q *Quadtree) newNode(center, half Vec2D, depth int, parent *quadNode) *quadNode {
+	return &quadNode{
+		center: center,
+		half:   half,
+		loose:  NewAABB2D(center, half.ScalerMulVec(q.Looseness)),
+		depth:  depth,
+		parent: parent,
+	}
+}
+
+func (q *Quadtree) Len() int {
+	return len(q.items)
+}
+
+func (q *Quadtree) Bounds(id int) (AABB2D, bool) {
+	it, ok := q.items[id]
+	if !ok {
+		return AABB2D{}, false
+	}
+
+	return it.box, true
+}
+
+// Points can be inserted as zero sized boxes
+func (q *Quadtree) Insert(id int, box AABB2D) error {
+	if _, ok := q.items[id]; ok {
+		return ErrDuplicateID
+	}
+
+	it := &quadItem{id: id, box: box}
+	q.items[id] = it
+	q.insert(q.root, it)
+
+	return nil
+}
+
+func (q *Quadtree) Remove(id int) error {
+	it, ok := q.items[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	delete(q.items, id)
+	q.detach(it)
+
+	return nil
+}
+
+// Items that still belong to the same node are updated in place
+func (q *Quadtree) Move(id int, box AABB2D) error {
+	it, ok := q.items[id]
+	if !ok {
+		return ErrUnknownID
+	}
+
+	n := it.node
+	if (n == q.root || n.loose.Contains(box)) && q.childFor(n, box) == nil {
+		it.box = box
+		return nil
+	}
+
+	q.detach(it)
+	it.box = box
+	q.insert(q.root, it)
+
+	return nil
+}
+
+// the child the box would descend into, nil when it has to stay in n
+func (q *Quadtree) childFor(n *quadNode, box AABB2D) *quadNode {
+	if n.children == nil {
+		return nil
+	}
+
+	c := box.Center()
+	i := 0
+	if c.X >= n.center.X {
+		i |= 1
+	}
+	if c.Y >= n.center.Y {
+		i |= 2
+	}
+
+	child := n.children[i]
+	if !child.loose.Contains(box) {
+		return nil
+	}
+
+	return child
+}
+
+func (q *Quadtree) insert(n *quadNode, it *quadItem) {
+	for {
+		n.count++
+
+		child := q.childFor(n, it.box)
+		if child == nil {
+			break
+		}
+		n = child
+	}
+
+	it.node = n
+	n.items = append(n.items, it)
+
+	if n.children == nil && len(n.items) > q.BucketSize && n.depth < q.MaxDepth {
+		q.split(n)
+	}
+}
+
+func (q *Quadtree) split(n *quadNode) {
+	h := n.half.ScalerMulVec(0.5)
+
+	n.children = &[4]*quadNode{}
+	for i := 0; i < 4; i++ {
+		c := n.center
+		if i&1 == 0 {
+			c.X -= h.X
+		} else {
+			c.X += h.X
+		}
+		if i&2 == 0 {
+			c.Y -= h.Y
+		} else {
+			c.Y += h.Y
+		}
+
+		n.children[i] = q.newNode(c, h, n.depth+1, n)
+	}
+
+	items := n.items
+	n.items = nil
+	n.count -= len(items)
+
+	for _, it := range items {
+		// descends at most one level since the children are empty
+		q.insert(n, it)
+	}
+}
+
+func (q *Quadtree) detach(it *quadItem) {
+	n := it.node
+	for i, o := range n.items {
+		if o == it {
+			last := len(n.items) - 1
+			n.items[i] = n.items[last]
+			n.items[last] = nil
+			n.items = n.items[:last]
+			break
+		}
+	}
+	it.node = nil
+
+	// collapse the highest ancestor whose subtree fits in one bucket
+	var merge *quadNode
+	for p := n; p != nil; p = p.parent {
+		p.count--
+		if p.children != nil && p.count <= q.BucketSize {
+			merge = p
+		}
+	}
+
+	if merge != nil {
+		q.collapse(merge)
+	}
+}
+
+func (q *Quadtree) collapse(n *quadNode) {
+	for _, c := range n.children {
+		q.gather(c, n)
+	}
+	n.children = nil
+}
+
+func (q *Quadtree) gather(n, into *quadNode) {
+	for _, it := range n.items {
+		it.node = into
+		into.items = append(into.items, it)
+	}
+
+	if n.children != nil {
+		for _, c := range n.children {
+			q.gather(c, into)
+		}
+	}
+}
+
+// ids of the items whose boxes overlap box
+func (q *Quadtree) QueryRect(box AABB2D) []int {
+	return q.query(func(b AABB2D) bool {
+		return b.Intersects(box)
+	})
+}
+
+func (q *Quadtree) QueryCircle(c Circle) []int {
+	return q.query(func(b AABB2D) bool {
+		return b.IntersectsCircle(c)
+	})
+}
+
+func (q *Quadtree) query(overlaps func(AABB2D) bool) []int {
+	out := make([]int, 0)
+
+	stack := []*quadNode{q.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// the root may hold items outside the world bounds
+		if n != q.root && !overlaps(n.loose) {
+			continue
+		}
+
+		for _, it := range n.items {
+			if overlaps(it.box) {
+				out = append(out, it.id)
+			}
+		}
+
+		if n.children != nil {
+			stack = append(stack, n.children[:]...)
+		}
+	}
+
+	return out
+}
+
+// The k items closest to p measured to their boxes, nearest first and
+// ties broken by id
+func (q *Quadtree) KNearest(p Vec2D, k int) []int {
+	out := make([]int, 0, k)
+	if k <= 0 {
+		return out
+	}
+
+	h := &knnHeap{{node: q.root}}
+	for h.Len() > 0 && len(out) < k {
+		e := heap.Pop(h).(knnEntry)
+
+		n, ok := e.node.(*quadNode)
+		if !ok {
+			out = append(out, e.id)
+			continue
+		}
+
+		for _, it := range n.items {
+			heap.Push(h, knnEntry{dist: p.Dist(it.box.ClosestPoint(p)), id: it.id})
+		}
+
+		if n.children != nil {
+			for _, c := range n.children {
+				if c.count > 0 {
+					heap.Push(h, knnEntry{dist: p.Dist(c.loose.ClosestPoint(p)), node: c})
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// Best first search queue shared by the spatial trees. Node is nil for
+// items, at equal distance nodes come first so no closer item is missed
+type knnEntry struct {
+	dist float64
+	id   int
+	node any
+}
+
+type knnHeap []knnEntry
+
+func (h knnHeap) Len() int {
+	return len(h)
+}
+
+func (h knnHeap) Less(i, j int) bool {
+	if h[i].dist != h[j].dist {
+		return h[i].dist < h[j].dist
+	}
+
+	if (h[i].node == nil) != (h[j].node == nil) {
+		return h[i].node != nil
+	}
+
+	return h[i].id < h[j].id
+}
+
+func (h knnHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *knnHeap) Push(x any) {
+	*h = append(*h, x.(knnEntry))
+}
+
+func (h *knnHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return e
+}
@@ -81,6 +81,29 @@ func (q *Quaternion) SetFromRotMat3D(r RotMat3D) {
 	}
 }
 
+// Shortest rotation taking the direction of from to the direction of to
+func (q *Quaternion) SetFromTo(from, to Vec3D) error {
+	if _, err := from.Normalize(); err != nil {
+		return err
+	}
+	if _, err := to.Normalize(); err != nil {
+		return err
+	}
+
+	d := from.Dot(to)
+	if d < -1+1e-12 {
+		axis := anyPerpendicular(from)
+		q.Set(0, axis.X, axis.Y, axis.Z)
+		return nil
+	}
+
+	c := from.CrossV(to)
+	q.Set(1+d, c.X, c.Y, c.Z)
+	q.Normalize()
+
+	return nil
+}
+
 // Creates a Pure Quarternion from a Vec3d
 func (q *Quaternion) SetFromVec3D(v Vec3D) {
 	q.W = 0
@@ -217,7 +240,7 @@ func (q Quaternion) InverseQt() (Quaternion, error) {
 }
 
 func (q *Quaternion) Dot(qt Quaternion) float64 {
-	return (q.X * qt.X) + (q.Y * qt.Y) + (q.Z * qt.Z)
+	return (q.W * qt.W) + (q.X * qt.X) + (q.Y * qt.Y) + (q.Z * qt.Z)
 }
 
 func (q *Quaternion) Multiply(qt Quaternion) {
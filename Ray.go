@@ -0,0 +1,27 @@
+package golem
+
+type Ray3D struct {
+	Origin Vec3D
+	Dir    Vec3D
+}
+
+func NewRay3D(origin, dir Vec3D) (Ray3D, error) {
+	_, err := dir.Normalize()
+	if err != nil {
+		return Ray3D{}, err
+	}
+
+	return Ray3D{
+		Origin: origin,
+		Dir:    dir,
+	}, nil
+}
+
+// Point along the ray at param t => Origin + t*Dir
+func (r Ray3D) At(t float64) Vec3D {
+	return Vec3D{
+		X: r.Origin.X + (t * r.Dir.X),
+		Y: r.Origin.Y + (t * r.Dir.Y),
+		Z: r.Origin.Z + (t * r.Dir.Z),
+	}
+}
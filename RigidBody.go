@@ -0,0 +1,302 @@
+package golem
+
+import "math"
+
+// Position is the center of mass, velocities are in world space. A body
+// with zero mass is kinematic, forces do not move it but it keeps moving
+// with its velocities
+type RigidBody struct {
+	Position        Vec3D
+	Orientation     Quaternion
+	LinearVelocity  Vec3D
+	AngularVelocity Vec3D
+
+	// rate the velocities decay at, per second
+	LinearDamping  float64
+	AngularDamping float64
+
+	mass       float64
+	invMass    float64
+	inertia    Mat3D
+	invInertia Mat3D
+
+	force  Vec3D
+	torque Vec3D
+}
+
+// inertia is in body space about the center of mass
+func NewRigidBody(mass float64, inertia Mat3D) (*RigidBody, error) {
+	b := &RigidBody{Orientation: Quaternion{W: 1}}
+	if err := b.SetMass(mass, inertia); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *RigidBody) SetMass(mass float64, inertia Mat3D) error {
+	if mass < 0 {
+		return ErrInvalidMass
+	}
+
+	if mass == 0 {
+		b.mass, b.invMass = 0, 0
+		b.inertia, b.invInertia = Mat3D{}, Mat3D{}
+		return nil
+	}
+
+	inv := inertia
+	if err := inv.Inverse(); err != nil {
+		return err
+	}
+
+	b.mass, b.invMass = mass, 1/mass
+	b.inertia, b.invInertia = inertia, inv
+
+	return nil
+}
+
+func (b *RigidBody) Mass() float64 {
+	return b.mass
+}
+
+func (b *RigidBody) InvMass() float64 {
+	return b.invMass
+}
+
+func (b *RigidBody) IsKinematic() bool {
+	return b.invMass == 0
+}
+
+// body space inertia
+func (b *RigidBody) Inertia() Mat3D {
+	return b.inertia
+}
+
+func (b *RigidBody) WorldInertia() Mat3D {
+	return RotateInertia(b.inertia, b.Orientation.ToRotMat3D())
+}
+
+func (b *RigidBody) InvWorldInertia() Mat3D {
+	return RotateInertia(b.invInertia, b.Orientation.ToRotMat3D())
+}
+
+// point in body space to world space
+func (b *RigidBody) ToWorld(p Vec3D) Vec3D {
+	return b.Orientation.ToRotMat3D().RotateVec3D(p).AddVec(b.Position)
+}
+
+// point in world space to body space
+func (b *RigidBody) ToLocal(p Vec3D) Vec3D {
+	r := b.Orientation.ToRotMat3D()
+	return r.Mat3D.TranposeMat().MultiplyVec3D(p.SubVec(b.Position))
+}
+
+// velocity of the world point p moving with the body
+func (b *RigidBody) VelocityAt(p Vec3D) Vec3D {
+	return b.LinearVelocity.AddVec(b.AngularVelocity.CrossV(p.SubVec(b.Position)))
+}
+
+func (b *RigidBody) ApplyForce(f Vec3D) {
+	b.force.Add(f)
+}
+
+func (b *RigidBody) ApplyTorque(t Vec3D) {
+	b.torque.Add(t)
+}
+
+// force at the world point p, off center forces add torque
+func (b *RigidBody) ApplyForceAtPoint(f, p Vec3D) {
+	b.force.Add(f)
+	b.torque.Add(p.SubVec(b.Position).CrossV(f))
+}
+
+func (b *RigidBody) ApplyImpulse(j Vec3D) {
+	b.LinearVelocity.Add(j.ScalerMulVec(b.invMass))
+}
+
+func (b *RigidBody) ApplyAngularImpulse(j Vec3D) {
+	b.AngularVelocity.Add(b.InvWorldInertia().MultiplyVec3D(j))
+}
+
+// impulse at the world point p
+func (b *RigidBody) ApplyImpulseAtPoint(j, p Vec3D) {
+	b.ApplyImpulse(j)
+	b.ApplyAngularImpulse(p.SubVec(b.Position).CrossV(j))
+}
+
+// forces and torques gathered since the last step
+func (b *RigidBody) Force() Vec3D {
+	return b.force
+}
+
+func (b *RigidBody) Torque() Vec3D {
+	return b.torque
+}
+
+func (b *RigidBody) ClearForces() {
+	b.force.SetZero()
+	b.torque.SetZero()
+}
+
+func (b *RigidBody) LinearMomentum() Vec3D {
+	return b.LinearVelocity.ScalerMulVec(b.mass)
+}
+
+func (b *RigidBody) AngularMomentum() Vec3D {
+	return b.WorldInertia().MultiplyVec3D(b.AngularVelocity)
+}
+
+func (b *RigidBody) KineticEnergy() float64 {
+	l := b.AngularMomentum()
+	return 0.5 * (b.mass*b.LinearVelocity.Dot(b.LinearVelocity) + l.Dot(b.AngularVelocity))
+}
+
+// Semi implicit Euler, velocities are updated first and then move the
+// body. Clears the accumulated forces
+func (b *RigidBody) IntegrateEuler(dt float64) {
+	b.IntegrateVelocity(dt)
+	b.IntegratePosition(dt)
+}
+
+// First half of IntegrateEuler, applies and clears the accumulated forces.
+// Constraint solvers run between the two halves
+func (b *RigidBody) IntegrateVelocity(dt float64) {
+	if b.invMass != 0 {
+		b.LinearVelocity.Add(b.force.ScalerMulVec(b.invMass * dt))
+
+		b.AngularVelocity = b.gyroscopic(dt)
+		b.AngularVelocity.Add(b.InvWorldInertia().MultiplyVec3D(b.torque).ScalerMulVec(dt))
+
+		b.damp(dt)
+	}
+
+	b.ClearForces()
+}
+
+// Second half of IntegrateEuler, moves the body by its velocities
+func (b *RigidBody) IntegratePosition(dt float64) {
+	b.Position.Add(b.LinearVelocity.ScalerMulVec(dt))
+	b.Orientation = spin(b.Orientation, b.AngularVelocity, dt)
+}
+
+// The gyroscopic term makes bodies with unequal axes tumble. Applied
+// explicitly it adds energy every step, so the body space Euler equations
+// are solved with the implicit midpoint rule, which keeps both the energy
+// and the size of the angular momentum of a free body
+func (b *RigidBody) gyroscopic(dt float64) Vec3D {
+	r := b.Orientation.ToRotMat3D().Mat3D
+	w0 := r.TranposeMat().MultiplyVec3D(b.AngularVelocity)
+	w := w0
+
+	// Newton steps on I (w - w0) + dt m x I m = 0 with m the midpoint
+	for i := 0; i < 3; i++ {
+		mid := w.AddVec(w0).ScalerMulVec(0.5)
+		im := b.inertia.MultiplyVec3D(mid)
+
+		f := b.inertia.MultiplyVec3D(w.SubVec(w0)).AddVec(mid.CrossV(im).ScalerMulVec(dt))
+		j := b.inertia.AddMat(skewVec3D(mid).Multiply(b.inertia).SubMat(skewVec3D(im)).ScaleMat(dt / 2))
+		if err := j.Inverse(); err != nil {
+			break
+		}
+		w.Sub(j.MultiplyVec3D(f))
+	}
+
+	return r.MultiplyVec3D(w)
+}
+
+// Fourth order Runge Kutta on position, orientation and momentum with the
+// accumulated forces held over the step. Clears the accumulated forces
+func (b *RigidBody) IntegrateRK4(dt float64) {
+	if b.invMass == 0 {
+		b.IntegrateEuler(dt)
+		return
+	}
+
+	s := bodyState{
+		pos: b.Position,
+		rot: b.Orientation,
+		lin: b.LinearMomentum(),
+		ang: b.AngularMomentum(),
+	}
+
+	k1 := b.derive(s)
+	k2 := b.derive(s.step(k1, dt/2))
+	k3 := b.derive(s.step(k2, dt/2))
+	k4 := b.derive(s.step(k3, dt))
+
+	s = s.step(k1, dt/6).step(k2, dt/3).step(k3, dt/3).step(k4, dt/6)
+	s.rot.Normalize()
+
+	b.Position, b.Orientation = s.pos, s.rot
+	b.LinearVelocity = s.lin.ScalerMulVec(b.invMass)
+	b.AngularVelocity = b.InvWorldInertia().MultiplyVec3D(s.ang)
+
+	b.damp(dt)
+	b.ClearForces()
+}
+
+func (b *RigidBody) damp(dt float64) {
+	if b.LinearDamping > 0 {
+		b.LinearVelocity.ScalerMul(math.Exp(-b.LinearDamping * dt))
+	}
+	if b.AngularDamping > 0 {
+		b.AngularVelocity.ScalerMul(math.Exp(-b.AngularDamping * dt))
+	}
+}
+
+// position, orientation and linear and angular momentum
+type bodyState struct {
+	pos Vec3D
+	rot Quaternion
+	lin Vec3D
+	ang Vec3D
+}
+
+func (b *RigidBody) derive(s bodyState) bodyState {
+	r := s.rot
+	r.Normalize()
+	w := RotateInertia(b.invInertia, r.ToRotMat3D()).MultiplyVec3D(s.ang)
+
+	return bodyState{
+		pos: s.lin.ScalerMulVec(b.invMass),
+		rot: spinRate(s.rot, w),
+		lin: b.force,
+		ang: b.torque,
+	}
+}
+
+// s plus d times dt, d holding rates
+func (s bodyState) step(d bodyState, dt float64) bodyState {
+	return bodyState{
+		pos: s.pos.AddVec(d.pos.ScalerMulVec(dt)),
+		rot: s.rot.AddQt(d.rot.ScaleByQt(dt)),
+		lin: s.lin.AddVec(d.lin.ScalerMulVec(dt)),
+		ang: s.ang.AddVec(d.ang.ScalerMulVec(dt)),
+	}
+}
+
+// rate of change of q spinning at the world angular velocity w
+func spinRate(q Quaternion, w Vec3D) Quaternion {
+	p := Quaternion{X: w.X, Y: w.Y, Z: w.Z}
+	return p.MultiplyQt(q).ScaleByQt(0.5)
+}
+
+// q after spinning at w for dt, renormalized
+func spin(q Quaternion, w Vec3D, dt float64) Quaternion {
+	q.Add(spinRate(q, w).ScaleByQt(dt))
+	if _, err := q.Normalize(); err != nil {
+		return Quaternion{W: 1}
+	}
+
+	return q
+}
+
+// matrix of the cross product with v
+func skewVec3D(v Vec3D) Mat3D {
+	return Mat3D{
+		{0, -v.Z, v.Y},
+		{v.Z, 0, -v.X},
+		{-v.Y, v.X, 0},
+	}
+}
@@ -0,0 +1,382 @@
+package golem
+
+import "math"
+
+// Collision shape in the local space of a body, whose origin is taken as
+// the center of mass. Collide2D handles *CircleShape and *PolygonShape
+type Shape2D interface {
+	// box around the shape placed at pos turned by rot
+	Bounds(pos Vec2D, rot RotMat2D) AABB2D
+	// mass and inertia about the local origin for the density
+	MassData(density float64) (float64, float64)
+	// distance along the unit dir to the first hit and the normal there,
+	// rays starting inside the shape do not hit it
+	Raycast(pos Vec2D, rot RotMat2D, origin, dir Vec2D, maxDist float64) (float64, Vec2D, bool)
+}
+
+type CircleShape struct {
+	Center Vec2D
+	Radius float64
+}
+
+// Convex polygon with ccw vertices, Normals[i] is the outward normal of
+// the edge from vertex i to i+1
+type PolygonShape struct {
+	Vertices []Vec2D
+	Normals  []Vec2D
+}
+
+// Points of a contact between two shapes, Normal points from A to B
+type Manifold2D struct {
+	Normal Vec2D
+	Points []ContactPoint2D
+}
+
+// ID names the features that touch so the contact can be matched from
+// one step to the next
+type ContactPoint2D struct {
+	Point Vec2D
+	Depth float64
+	ID    int
+}
+
+// Convex hull of the points moved so its centroid, the center of mass of
+// the body, is on the origin. Returns the offset taken off the points, a
+// body placed at it puts the shape back where the points were
+func NewPolygonShape(points []Vec2D) (*PolygonShape, Vec2D, error) {
+	hull := ConvexHull2D(points)
+	if len(hull) < 3 || PolygonArea(hull) == 0 {
+		return nil, Vec2D{}, ErrDegeneratePolygon
+	}
+
+	centroid, err := PolygonCentroid(hull)
+	if err != nil {
+		return nil, Vec2D{}, err
+	}
+	for i := range hull {
+		hull[i].Sub(centroid)
+	}
+
+	p := &PolygonShape{Vertices: hull, Normals: make([]Vec2D, len(hull))}
+	for i := range hull {
+		e := hull[(i+1)%len(hull)].SubVec(hull[i])
+		n := e.RightPerpendicular()
+		n.Normalize()
+		p.Normals[i] = n
+	}
+
+	return p, centroid, nil
+}
+
+// box centered on the origin
+func NewBoxShape(halfWidth, halfHeight float64) (*PolygonShape, error) {
+	p, _, err := NewPolygonShape([]Vec2D{
+		{X: -halfWidth, Y: -halfHeight},
+		{X: halfWidth, Y: -halfHeight},
+		{X: halfWidth, Y: halfHeight},
+		{X: -halfWidth, Y: halfHeight},
+	})
+
+	return p, err
+}
+
+func (c *CircleShape) Bounds(pos Vec2D, rot RotMat2D) AABB2D {
+	return NewAABB2D(pos.AddVec(rot.RotateVec2D(c.Center)), Vec2D{X: c.Radius, Y: c.Radius})
+}
+
+func (c *CircleShape) MassData(density float64) (float64, float64) {
+	m := density * math.Pi * c.Radius * c.Radius
+	return m, m * (c.Radius*c.Radius/2 + c.Center.Dot(c.Center))
+}
+
+func (c *CircleShape) Raycast(pos Vec2D, rot RotMat2D, origin, dir Vec2D, maxDist float64) (float64, Vec2D, bool) {
+	w := c.world(pos, rot)
+
+	// |origin + t dir - center| = radius with a unit dir
+	m := origin.SubVec(w.Center)
+	b := m.Dot(dir)
+	k := m.Dot(m) - w.Radius*w.Radius
+	if k <= 0 || b > 0 {
+		return 0, Vec2D{}, false
+	}
+
+	disc := b*b - k
+	if disc < 0 {
+		return 0, Vec2D{}, false
+	}
+
+	t := -b - math.Sqrt(disc)
+	if t > maxDist {
+		return 0, Vec2D{}, false
+	}
+
+	n := m.AddVec(dir.ScalerMulVec(t))
+	n.Normalize()
+
+	return t, n, true
+}
+
+func (c *CircleShape) world(pos Vec2D, rot RotMat2D) Circle {
+	return Circle{Center: pos.AddVec(rot.RotateVec2D(c.Center)), Radius: c.Radius}
+}
+
+func (p *PolygonShape) Bounds(pos Vec2D, rot RotMat2D) AABB2D {
+	first := pos.AddVec(rot.RotateVec2D(p.Vertices[0]))
+	box := AABB2D{Min: first, Max: first}
+	for _, v := range p.Vertices[1:] {
+		box.AddPoint(pos.AddVec(rot.RotateVec2D(v)))
+	}
+
+	return box
+}
+
+// fan of triangles from the origin
+func (p *PolygonShape) MassData(density float64) (float64, float64) {
+	mass, inertia := 0.0, 0.0
+
+	for i, a := range p.Vertices {
+		b := p.Vertices[(i+1)%len(p.Vertices)]
+		area := a.Cross2D(b) / 2
+
+		mass += density * area
+		inertia += density * area * (a.Dot(a) + a.Dot(b) + b.Dot(b)) / 6
+	}
+
+	return mass, inertia
+}
+
+// Cyrus-Beck clipping of the ray against the edges
+func (p *PolygonShape) Raycast(pos Vec2D, rot RotMat2D, origin, dir Vec2D, maxDist float64) (float64, Vec2D, bool) {
+	w := p.world(pos, rot)
+
+	lower, upper := 0.0, maxDist
+	edge := -1
+	for i, n := range w.normals {
+		num := n.Dot(w.verts[i].SubVec(origin))
+		den := n.Dot(dir)
+
+		if den == 0 {
+			if num < 0 {
+				return 0, Vec2D{}, false
+			}
+		} else if den < 0 && num < lower*den {
+			lower, edge = num/den, i
+		} else if den > 0 && num < upper*den {
+			upper = num / den
+		}
+
+		if upper < lower {
+			return 0, Vec2D{}, false
+		}
+	}
+
+	if edge < 0 {
+		return 0, Vec2D{}, false
+	}
+
+	return lower, w.normals[edge], true
+}
+
+type worldPolygon struct {
+	verts   []Vec2D
+	normals []Vec2D
+}
+
+func (p *PolygonShape) world(pos Vec2D, rot RotMat2D) worldPolygon {
+	w := worldPolygon{verts: make([]Vec2D, len(p.Vertices)), normals: make([]Vec2D, len(p.Normals))}
+	for i := range p.Vertices {
+		w.verts[i] = pos.AddVec(rot.RotateVec2D(p.Vertices[i]))
+		w.normals[i] = rot.RotateVec2D(p.Normals[i])
+	}
+
+	return w
+}
+
+// Contact between shape a at posA turned by rotA and shape b
+func Collide2D(a Shape2D, posA Vec2D, rotA RotMat2D, b Shape2D, posB Vec2D, rotB RotMat2D) (Manifold2D, bool) {
+	switch sa := a.(type) {
+	case *CircleShape:
+		switch sb := b.(type) {
+		case *CircleShape:
+			return collideCircles(sa.world(posA, rotA), sb.world(posB, rotB))
+		case *PolygonShape:
+			m, ok := collidePolygonCircle(sb.world(posB, rotB), sa.world(posA, rotA))
+			m.Normal.Reverse()
+			return m, ok
+		}
+
+	case *PolygonShape:
+		switch sb := b.(type) {
+		case *CircleShape:
+			return collidePolygonCircle(sa.world(posA, rotA), sb.world(posB, rotB))
+		case *PolygonShape:
+			return collidePolygons(sa.world(posA, rotA), sb.world(posB, rotB))
+		}
+	}
+
+	return Manifold2D{}, false
+}
+
+func collideCircles(a, b Circle) (Manifold2D, bool) {
+	n := b.Center.SubVec(a.Center)
+	dist, err := n.Normalize()
+	if err != nil {
+		n, dist = Vec2D{Y: 1}, 0
+	}
+
+	depth := a.Radius + b.Radius - dist
+	if depth < 0 {
+		return Manifold2D{}, false
+	}
+
+	// halfway between the two surfaces
+	p := a.Center.AddVec(n.ScalerMulVec(a.Radius - depth/2))
+	return Manifold2D{Normal: n, Points: []ContactPoint2D{{Point: p, Depth: depth}}}, true
+}
+
+func collidePolygonCircle(p worldPolygon, c Circle) (Manifold2D, bool) {
+	edge, sep := 0, math.Inf(-1)
+	for i, n := range p.normals {
+		if s := n.Dot(c.Center.SubVec(p.verts[i])); s > sep {
+			edge, sep = i, s
+		}
+	}
+	if sep > c.Radius {
+		return Manifold2D{}, false
+	}
+
+	v1, v2 := p.verts[edge], p.verts[(edge+1)%len(p.verts)]
+	n := p.normals[edge]
+
+	// beyond the ends of the edge the closest feature is a vertex
+	if sep > 0 {
+		for _, v := range []Vec2D{v1, v2} {
+			o := v1
+			if v == v1 {
+				o = v2
+			}
+			d := c.Center.SubVec(v)
+			if d.Dot(o.SubVec(v)) > 0 {
+				continue
+			}
+
+			dist, _ := d.Normalize()
+			if dist > c.Radius {
+				return Manifold2D{}, false
+			}
+			n, sep = d, dist
+			break
+		}
+	}
+
+	depth := c.Radius - sep
+	pt := c.Center.SubVec(n.ScalerMulVec(c.Radius - depth/2))
+
+	return Manifold2D{Normal: n, Points: []ContactPoint2D{{Point: pt, Depth: depth, ID: edge}}}, true
+}
+
+// edge of a with the largest separation from b
+func maxSeparation(a, b worldPolygon) (int, float64) {
+	edge, best := 0, math.Inf(-1)
+	for i, n := range a.normals {
+		s := math.Inf(1)
+		for _, v := range b.verts {
+			s = math.Min(s, n.Dot(v.SubVec(a.verts[i])))
+		}
+		if s > best {
+			edge, best = i, s
+		}
+	}
+
+	return edge, best
+}
+
+type clipVertex struct {
+	p  Vec2D
+	id int
+}
+
+// SAT picks the reference face, the incident edge of the other polygon is
+// clipped to its sides and points behind the face are kept
+func collidePolygons(a, b worldPolygon) (Manifold2D, bool) {
+	edgeA, sepA := maxSeparation(a, b)
+	if sepA > 0 {
+		return Manifold2D{}, false
+	}
+	edgeB, sepB := maxSeparation(b, a)
+	if sepB > 0 {
+		return Manifold2D{}, false
+	}
+
+	// prefer a so the choice does not flicker between nearly equal faces
+	ref, inc, edge, flip := a, b, edgeA, 0
+	if sepB > sepA+1e-3 {
+		ref, inc, edge, flip = b, a, edgeB, 1
+	}
+
+	n := ref.normals[edge]
+	best, minDot := 0, math.Inf(1)
+	for i, m := range inc.normals {
+		if d := n.Dot(m); d < minDot {
+			best, minDot = i, d
+		}
+	}
+
+	k := len(inc.verts)
+	seg := []clipVertex{{inc.verts[best], best}, {inc.verts[(best+1)%k], (best + 1) % k}}
+
+	v1, v2 := ref.verts[edge], ref.verts[(edge+1)%len(ref.verts)]
+	tangent := v2.SubVec(v1)
+	tangent.Normalize()
+
+	seg = clipSegment(seg, tangent.ScalerMulVec(-1), -tangent.Dot(v1), 100+edge)
+	if len(seg) < 2 {
+		return Manifold2D{}, false
+	}
+	seg = clipSegment(seg, tangent, tangent.Dot(v2), 100+(edge+1)%len(ref.verts))
+	if len(seg) < 2 {
+		return Manifold2D{}, false
+	}
+
+	m := Manifold2D{Normal: n}
+	if flip == 1 {
+		m.Normal = n.ScalerMulVec(-1)
+	}
+
+	for _, cv := range seg {
+		s := n.Dot(cv.p.SubVec(v1))
+		if s > 0 {
+			continue
+		}
+
+		m.Points = append(m.Points, ContactPoint2D{
+			Point: cv.p.SubVec(n.ScalerMulVec(s / 2)),
+			Depth: -s,
+			ID:    flip<<20 | edge<<10 | cv.id,
+		})
+	}
+
+	return m, len(m.Points) > 0
+}
+
+// keeps the part of the segment with n.p <= offset, new points take id
+func clipSegment(seg []clipVertex, n Vec2D, offset float64, id int) []clipVertex {
+	d0 := n.Dot(seg[0].p) - offset
+	d1 := n.Dot(seg[1].p) - offset
+
+	out := make([]clipVertex, 0, 2)
+	if d0 <= 0 {
+		out = append(out, seg[0])
+	}
+	if d1 <= 0 {
+		out = append(out, seg[1])
+	}
+
+	if d0*d1 < 0 {
+		t := d0 / (d0 - d1)
+		p := seg[0].p.AddVec(seg[1].p.SubVec(seg[0].p).ScalerMulVec(t))
+		out = append(out, clipVertex{p, id})
+	}
+
+	return out
+}
@@ -0,0 +1,269 @@
+package golem
+
+// Parent is the index of the parent bone or -1 for a root, Bind is the
+// local transform of the bind pose
+type Bone struct {
+	Name   string
+	Parent int
+	Bind   Transform
+}
+
+// Bones are ordered so every parent comes before its children, which lets
+// global poses be built in a single pass
+type Skeleton struct {
+	Bones       []Bone
+	InverseBind []Mat4D
+
+	names map[string]int
+}
+
+// Local transforms, one per bone
+type Pose []Transform
+
+func NewSkeleton(bones []Bone) (*Skeleton, error) {
+	s := &Skeleton{
+		Bones:       append([]Bone(nil), bones...),
+		InverseBind: make([]Mat4D, len(bones)),
+		names:       make(map[string]int, len(bones)),
+	}
+
+	for i, b := range s.Bones {
+		if b.Parent < -1 || b.Parent >= i {
+			return nil, ErrInvalidParent
+		}
+		if _, ok := s.names[b.Name]; !ok && b.Name != "" {
+			s.names[b.Name] = i
+		}
+	}
+
+	global, _ := s.GlobalMatrices(s.BindPose(), nil)
+	for i, m := range global {
+		if err := m.Inverse(); err != nil {
+			return nil, err
+		}
+		s.InverseBind[i] = m
+	}
+
+	return s, nil
+}
+
+// first bone with the name
+func (s *Skeleton) BoneIndex(name string) (int, bool) {
+	i, ok := s.names[name]
+	return i, ok
+}
+
+func (s *Skeleton) BindPose() Pose {
+	p := make(Pose, len(s.Bones))
+	for i, b := range s.Bones {
+		p[i] = b.Bind
+	}
+
+	return p
+}
+
+// Model space matrix of every bone, out is reused when large enough
+func (s *Skeleton) GlobalMatrices(p Pose, out []Mat4D) ([]Mat4D, error) {
+	if len(p) != len(s.Bones) {
+		return nil, ErrInvalidLen
+	}
+
+	if cap(out) < len(p) {
+		out = make([]Mat4D, len(p))
+	}
+	out = out[:len(p)]
+
+	for i, b := range s.Bones {
+		out[i] = p[i].Mat4D()
+		if b.Parent >= 0 {
+			out[i] = out[b.Parent].Multiply(out[i])
+		}
+	}
+
+	return out, nil
+}
+
+// Model space transforms, see Transform.Multiply for non uniform scale
+func (s *Skeleton) GlobalTransforms(p Pose, out []Transform) ([]Transform, error) {
+	if len(p) != len(s.Bones) {
+		return nil, ErrInvalidLen
+	}
+
+	if cap(out) < len(p) {
+		out = make([]Transform, len(p))
+	}
+	out = out[:len(p)]
+
+	for i, b := range s.Bones {
+		out[i] = p[i]
+		if b.Parent >= 0 {
+			out[i] = out[b.Parent].Multiply(p[i])
+		}
+	}
+
+	return out, nil
+}
+
+// Global matrix times inverse bind matrix per bone, ready for a skinning
+// shader. out is reused when large enough
+func (s *Skeleton) SkinningPalette(p Pose, out []Mat4D) ([]Mat4D, error) {
+	out, err := s.GlobalMatrices(p, out)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range out {
+		out[i] = out[i].Multiply(s.InverseBind[i])
+	}
+
+	return out, nil
+}
+
+// Mask of 1 for root and every bone below it and 0 elsewhere
+func (s *Skeleton) Mask(root int) []float64 {
+	mask := make([]float64, len(s.Bones))
+	if root < 0 || root >= len(mask) {
+		return mask
+	}
+
+	mask[root] = 1
+	for i := root + 1; i < len(s.Bones); i++ {
+		if p := s.Bones[i].Parent; p >= 0 && mask[p] == 1 {
+			mask[i] = 1
+		}
+	}
+
+	return mask
+}
+
+// Per bone blend from a to b by weight using nlerp, out is reused when
+// large enough
+func BlendPoses(a, b Pose, weight float64, out Pose) (Pose, error) {
+	return blendPoses(a, b, nil, weight, false, out)
+}
+
+func BlendPosesSlerp(a, b Pose, weight float64, out Pose) (Pose, error) {
+	return blendPoses(a, b, nil, weight, true, out)
+}
+
+// Blends layer over base by weight times the mask of each bone, bones
+// with a mask of 0 keep base
+func BlendPosesMasked(base, layer Pose, mask []float64, weight float64, out Pose) (Pose, error) {
+	if len(mask) != len(base) {
+		return nil, ErrInvalidLen
+	}
+
+	return blendPoses(base, layer, mask, weight, false, out)
+}
+
+func blendPoses(a, b Pose, mask []float64, weight float64, slerp bool, out Pose) (Pose, error) {
+	if len(a) != len(b) {
+		return nil, ErrInvalidLen
+	}
+
+	out = poseBuffer(out, len(a))
+	for i := range a {
+		w := weight
+		if mask != nil {
+			w *= mask[i]
+		}
+
+		if slerp {
+			out[i] = a[i].Slerp(b[i], w)
+		} else {
+			out[i] = a[i].Lerp(b[i], w)
+		}
+	}
+
+	return out, nil
+}
+
+// Normalized weighted average of any number of poses. Rotations are
+// summed on the hemisphere of the first pose and renormalized
+func BlendPosesWeighted(poses []Pose, weights []float64, out Pose) (Pose, error) {
+	if len(poses) == 0 || len(poses) != len(weights) {
+		return nil, ErrInvalidLen
+	}
+
+	total := 0.0
+	for k, p := range poses {
+		if len(p) != len(poses[0]) {
+			return nil, ErrInvalidLen
+		}
+		total += weights[k]
+	}
+	if total == 0 {
+		return nil, ErrZeroDiv
+	}
+
+	out = poseBuffer(out, len(poses[0]))
+	for i := range out {
+		var t, s Vec3D
+		var r Quaternion
+		ref := poses[0][i].Rotation
+
+		for k, p := range poses {
+			w := weights[k] / total
+			t.Add(p[i].Translation.ScalerMulVec(w))
+			s.Add(p[i].Scale.ScalerMulVec(w))
+
+			q := p[i].Rotation
+			if ref.Dot(q) < 0 {
+				q.Negate()
+			}
+			r.Add(q.ScaleByQt(w))
+		}
+
+		if _, err := r.Normalize(); err != nil {
+			r = ref
+		}
+		out[i] = Transform{Translation: t, Rotation: r, Scale: s}
+	}
+
+	return out, nil
+}
+
+// Adds the difference between additive and reference on top of base by
+// weight, clamped to [0, 1], as for layering a breathing or recoil clip over
+// any motion
+func AddPoses(base, additive, reference Pose, weight float64, out Pose) (Pose, error) {
+	if len(base) != len(additive) || len(base) != len(reference) {
+		return nil, ErrInvalidLen
+	}
+
+	out = poseBuffer(out, len(base))
+	for i := range base {
+		ref := reference[i]
+		add := additive[i]
+
+		// delta rotation on the identity hemisphere
+		inv := ref.Rotation.ConjugateQt()
+		d := inv.MultiplyQt(add.Rotation)
+		if d.W < 0 {
+			d.Negate()
+		}
+		delta := Transform{
+			Translation: add.Translation.SubVec(ref.Translation),
+			Rotation:    d,
+			Scale:       Vec3D{X: add.Scale.X / ref.Scale.X, Y: add.Scale.Y / ref.Scale.Y, Z: add.Scale.Z / ref.Scale.Z},
+		}
+		delta = IdentityTransform().Slerp(delta, weight)
+
+		b := base[i]
+		out[i] = Transform{
+			Translation: b.Translation.AddVec(delta.Translation),
+			Rotation:    b.Rotation.MultiplyQt(delta.Rotation),
+			Scale:       Vec3D{X: b.Scale.X * delta.Scale.X, Y: b.Scale.Y * delta.Scale.Y, Z: b.Scale.Z * delta.Scale.Z},
+		}
+	}
+
+	return out, nil
+}
+
+func poseBuffer(out Pose, n int) Pose {
+	if cap(out) < n {
+		return make(Pose, n)
+	}
+
+	return out[:n]
+}
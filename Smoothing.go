@@ -0,0 +1,172 @@
+package golem
+
+import "math"
+
+// Critically damped spring towards target that reaches it in roughly
+// smoothTime seconds without overshooting. velocity carries the state
+// between calls and maxSpeed <= 0 leaves the speed unlimited. The result
+// barely depends on how the time is split into steps of dt
+func SmoothDamp(current, target float64, velocity *float64, smoothTime, maxSpeed, dt float64) float64 {
+	if dt <= 0 {
+		return current
+	}
+
+	omega, decay := smoothDampDecay(smoothTime, dt)
+
+	change := current - target
+	if maxSpeed > 0 {
+		maxChange := maxSpeed * smoothTime
+		change = Clamp(change, -maxChange, maxChange)
+	}
+	goal := current - change
+
+	tmp := (*velocity + omega*change) * dt
+	*velocity = (*velocity - omega*tmp) * decay
+	out := goal + (change+tmp)*decay
+
+	// do not pass the target
+	if (target-current > 0) == (out > target) {
+		out = target
+		*velocity = 0
+	}
+
+	return out
+}
+
+func SmoothDampVec2D(current, target Vec2D, velocity *Vec2D, smoothTime, maxSpeed, dt float64) Vec2D {
+	if dt <= 0 {
+		return current
+	}
+
+	omega, decay := smoothDampDecay(smoothTime, dt)
+
+	change := current.SubVec(target)
+	if l := change.Length(); maxSpeed > 0 && l > maxSpeed*smoothTime {
+		change.ScalerMul(maxSpeed * smoothTime / l)
+	}
+	goal := current.SubVec(change)
+
+	tmp := velocity.AddVec(change.ScalerMulVec(omega)).ScalerMulVec(dt)
+	*velocity = velocity.SubVec(tmp.ScalerMulVec(omega)).ScalerMulVec(decay)
+	out := goal.AddVec(change.AddVec(tmp).ScalerMulVec(decay))
+
+	toTarget := target.SubVec(current)
+	if past := out.SubVec(target); toTarget.Dot(past) > 0 {
+		out = target
+		*velocity = Vec2D{}
+	}
+
+	return out
+}
+
+func SmoothDampVec3D(current, target Vec3D, velocity *Vec3D, smoothTime, maxSpeed, dt float64) Vec3D {
+	if dt <= 0 {
+		return current
+	}
+
+	omega, decay := smoothDampDecay(smoothTime, dt)
+
+	change := current.SubVec(target)
+	if l := change.Length(); maxSpeed > 0 && l > maxSpeed*smoothTime {
+		change.ScalerMul(maxSpeed * smoothTime / l)
+	}
+	goal := current.SubVec(change)
+
+	tmp := velocity.AddVec(change.ScalerMulVec(omega)).ScalerMulVec(dt)
+	*velocity = velocity.SubVec(tmp.ScalerMulVec(omega)).ScalerMulVec(decay)
+	out := goal.AddVec(change.AddVec(tmp).ScalerMulVec(decay))
+
+	toTarget := target.SubVec(current)
+	if past := out.SubVec(target); toTarget.Dot(past) > 0 {
+		out = target
+		*velocity = Vec3D{}
+	}
+
+	return out
+}
+
+// SmoothDamp the short way round, the result is normalized to [-Pi, Pi]
+func SmoothDampAngle(current, target float64, velocity *float64, smoothTime, maxSpeed, dt float64) float64 {
+	target = current + NormalizeAngle(target-current)
+
+	return NormalizeAngle(SmoothDamp(current, target, velocity, smoothTime, maxSpeed, dt))
+}
+
+// Damps the components on the hemisphere of current and renormalizes.
+// velocity stays tangent to the unit sphere, maxSpeed is roughly radians
+// per second
+func SmoothDampQuaternion(current, target Quaternion, velocity *Quaternion, smoothTime, maxSpeed, dt float64) (Quaternion, error) {
+	if _, err := current.Normalize(); err != nil {
+		return Quaternion{}, err
+	}
+	if _, err := target.Normalize(); err != nil {
+		return Quaternion{}, err
+	}
+	if dt <= 0 {
+		return current, nil
+	}
+
+	if current.Dot(target) < 0 {
+		target.Negate()
+	}
+
+	omega, decay := smoothDampDecay(smoothTime, dt)
+
+	// a rotation by a small angle moves the components by half of it
+	change := current.SubQt(target)
+	if l := change.Magnitude(); maxSpeed > 0 && l > maxSpeed*smoothTime/2 {
+		change.ScaleBy(maxSpeed * smoothTime / 2 / l)
+	}
+	goal := current.SubQt(change)
+
+	tmp := velocity.AddQt(change.ScaleByQt(omega)).ScaleByQt(dt)
+	v := velocity.SubQt(tmp.ScaleByQt(omega)).ScaleByQt(decay)
+	out := goal.AddQt(change.AddQt(tmp).ScaleByQt(decay))
+
+	if _, err := out.Normalize(); err != nil {
+		return Quaternion{}, err
+	}
+
+	*velocity = v.SubQt(out.ScaleByQt(out.Dot(v)))
+	return out, nil
+}
+
+func smoothDampDecay(smoothTime, dt float64) (float64, float64) {
+	omega := 2 / math.Max(smoothTime, 1e-4)
+	x := omega * dt
+
+	return omega, 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+}
+
+// Exponential decay towards target at rate lambda per second, the frame
+// rate independent form of lerping by a fixed t every frame
+func Damp(current, target, lambda, dt float64) float64 {
+	return target + (current-target)*math.Exp(-lambda*dt)
+}
+
+func DampVec2D(current, target Vec2D, lambda, dt float64) Vec2D {
+	return target.AddVec(current.SubVec(target).ScalerMulVec(math.Exp(-lambda * dt)))
+}
+
+func DampVec3D(current, target Vec3D, lambda, dt float64) Vec3D {
+	return target.AddVec(current.SubVec(target).ScalerMulVec(math.Exp(-lambda * dt)))
+}
+
+// Damp the short way round, the result is normalized to [-Pi, Pi]
+func DampAngle(current, target, lambda, dt float64) float64 {
+	return NormalizeAngle(current + NormalizeAngle(target-current)*(1-math.Exp(-lambda*dt)))
+}
+
+func DampQuaternion(current, target Quaternion, lambda, dt float64) (Quaternion, error) {
+	return current.SlerpQt(target, Clamp(1-math.Exp(-lambda*dt), 0, 1))
+}
+
+// Lerp factor that makes a fixed t per frame independent of the frame
+// rate, halfLife is the time to close half of the distance
+func DampFactor(halfLife, dt float64) float64 {
+	if halfLife <= 0 {
+		return 1
+	}
+
+	return 1 - math.Exp2(-dt/halfLife)
+}
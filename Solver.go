@@ -0,0 +1,216 @@
+package golem
+
+import "math"
+
+// Sequential impulse solver for contacts and joints between rigid bodies.
+// Constraints are visited in the order given so a replay of the same
+// input reproduces the same result exactly
+type Solver struct {
+	Iterations int
+
+	// fraction of the position error removed per step
+	Baumgarte float64
+	// penetration left alone to keep resting contacts from jittering
+	Slop float64
+	// approach speed below which contacts do not bounce
+	RestitutionThreshold float64
+
+	// reuse the impulses of the last step as a starting guess
+	WarmStarting bool
+
+	cache map[contactKey]contactImpulse
+}
+
+// Velocity constraint between bodies, solved by the Solver
+type Joint interface {
+	// Sets the joint up for a step of dt correcting beta of the position
+	// error. With warm the impulses of the last step are applied again
+	Prepare(dt, beta float64, warm bool)
+	// one iteration of the solver
+	Solve()
+}
+
+// Point where A and B touch, Normal points from A to B. Static bodies are
+// bodies of zero mass. ID tells contacts between the same bodies apart
+// from one step to the next for warm starting, such as a feature index
+type Contact struct {
+	A, B   *RigidBody
+	Point  Vec3D
+	Normal Vec3D
+	Depth  float64
+	ID     int
+
+	Friction    float64
+	Restitution float64
+
+	normal   constraintRow
+	friction [2]constraintRow
+	ia, ib   Mat3D
+}
+
+type contactKey struct {
+	a, b *RigidBody
+	id   int
+}
+
+type contactImpulse struct {
+	normal  float64
+	tangent Vec3D
+}
+
+func NewSolver() *Solver {
+	return &Solver{
+		Iterations:           10,
+		Baumgarte:            0.2,
+		Slop:                 0.005,
+		RestitutionThreshold: 1,
+		WarmStarting:         true,
+		cache:                make(map[contactKey]contactImpulse),
+	}
+}
+
+// Applies impulses to the bodies so contacts stop closing and joints hold.
+// Runs between IntegrateVelocity and IntegratePosition of the bodies
+func (s *Solver) Solve(contacts []Contact, joints []Joint, dt float64) {
+	if dt <= 0 {
+		return
+	}
+
+	for _, j := range joints {
+		j.Prepare(dt, s.Baumgarte, s.WarmStarting)
+	}
+	for i := range contacts {
+		s.prepare(&contacts[i], dt)
+	}
+
+	for it := 0; it < s.Iterations; it++ {
+		for _, j := range joints {
+			j.Solve()
+		}
+		for i := range contacts {
+			contacts[i].solve()
+		}
+	}
+
+	if s.cache == nil {
+		s.cache = make(map[contactKey]contactImpulse)
+	}
+	clear(s.cache)
+	for i := range contacts {
+		c := &contacts[i]
+		s.cache[contactKey{c.A, c.B, c.ID}] = contactImpulse{normal: c.normal.impulse, tangent: c.tangentImpulse()}
+	}
+}
+
+func (s *Solver) prepare(c *Contact, dt float64) {
+	c.ia, c.ib = c.A.InvWorldInertia(), c.B.InvWorldInertia()
+
+	ra, rb := c.Point.SubVec(c.A.Position), c.Point.SubVec(c.B.Position)
+	n := c.Normal
+	t1 := anyPerpendicular(n)
+	t2 := n.CrossV(t1)
+
+	c.normal.set(n, ra.CrossV(n), rb.CrossV(n), 0)
+	c.normal.lo, c.normal.hi = 0, math.Inf(1)
+
+	// push out of penetration, or bounce when approaching fast enough
+	vn := c.normal.velocity(c.A, c.B)
+	c.normal.bias = -s.Baumgarte / dt * max(c.Depth-s.Slop, 0)
+	if vn < -s.RestitutionThreshold {
+		c.normal.bias = min(c.normal.bias, c.Restitution*vn)
+	}
+
+	for k, t := range []Vec3D{t1, t2} {
+		c.friction[k].set(t, ra.CrossV(t), rb.CrossV(t), 0)
+	}
+
+	rows := []*constraintRow{&c.normal, &c.friction[0], &c.friction[1]}
+	for _, r := range rows {
+		r.prepare(c.A, c.B, c.ia, c.ib)
+	}
+
+	if prev, ok := s.cache[contactKey{c.A, c.B, c.ID}]; ok && s.WarmStarting {
+		c.normal.impulse = prev.normal
+		c.friction[0].impulse = prev.tangent.Dot(t1)
+		c.friction[1].impulse = prev.tangent.Dot(t2)
+
+		for _, r := range rows {
+			r.apply(c.A, c.B, c.ia, c.ib, r.impulse)
+		}
+	}
+}
+
+func (c *Contact) solve() {
+	// friction bounded by the normal impulse of the last iteration
+	limit := c.Friction * c.normal.impulse
+	for k := range c.friction {
+		c.friction[k].lo, c.friction[k].hi = -limit, limit
+		c.friction[k].solve(c.A, c.B, c.ia, c.ib)
+	}
+
+	c.normal.solve(c.A, c.B, c.ia, c.ib)
+}
+
+// Impulse the solver applied to B along the normal, A took the opposite
+func (c *Contact) NormalImpulse() float64 {
+	return c.normal.impulse
+}
+
+// Friction impulse the solver applied to B, A took the opposite
+func (c *Contact) TangentImpulse() Vec3D {
+	return c.tangentImpulse()
+}
+
+func (c *Contact) tangentImpulse() Vec3D {
+	return c.friction[0].linear.ScalerMulVec(c.friction[0].impulse).AddVec(c.friction[1].linear.ScalerMulVec(c.friction[1].impulse))
+}
+
+// One scalar constraint on the velocities of two bodies,
+// linear.(vB - vA) + angB.wB - angA.wA + bias = 0, with the summed impulse
+// kept between lo and hi
+type constraintRow struct {
+	linear     Vec3D
+	angA, angB Vec3D
+
+	mass    float64
+	bias    float64
+	impulse float64
+	lo, hi  float64
+}
+
+func (r *constraintRow) set(linear, angA, angB Vec3D, bias float64) {
+	r.linear, r.angA, r.angB = linear, angA, angB
+	r.bias = bias
+	r.lo, r.hi = math.Inf(-1), math.Inf(1)
+}
+
+func (r *constraintRow) prepare(a, b *RigidBody, ia, ib Mat3D) {
+	k := (a.invMass + b.invMass) * r.linear.Dot(r.linear)
+	k += r.angA.Dot(ia.MultiplyVec3D(r.angA)) + r.angB.Dot(ib.MultiplyVec3D(r.angB))
+
+	r.mass = 0
+	if k > 0 {
+		r.mass = 1 / k
+	}
+}
+
+func (r *constraintRow) velocity(a, b *RigidBody) float64 {
+	v := b.LinearVelocity.SubVec(a.LinearVelocity)
+	return r.linear.Dot(v) + r.angB.Dot(b.AngularVelocity) - r.angA.Dot(a.AngularVelocity)
+}
+
+func (r *constraintRow) apply(a, b *RigidBody, ia, ib Mat3D, lambda float64) {
+	a.LinearVelocity.Sub(r.linear.ScalerMulVec(lambda * a.invMass))
+	a.AngularVelocity.Sub(ia.MultiplyVec3D(r.angA.ScalerMulVec(lambda)))
+
+	b.LinearVelocity.Add(r.linear.ScalerMulVec(lambda * b.invMass))
+	b.AngularVelocity.Add(ib.MultiplyVec3D(r.angB.ScalerMulVec(lambda)))
+}
+
+func (r *constraintRow) solve(a, b *RigidBody, ia, ib Mat3D) {
+	lambda := -r.mass * (r.velocity(a, b) + r.bias)
+
+	old := r.impulse
+	r.impulse = Clamp(old+lambda, r.lo, r.hi)
+	r.apply(a, b, ia, ib, r.impulse-old)
+}
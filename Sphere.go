@@ -0,0 +1,53 @@
+package golem
+
+import "math"
+
+type Sphere struct {
+	Center Vec3D
+	Radius float64
+}
+
+func (s Sphere) ContainsPoint(p Vec3D) bool {
+	return s.Center.Dist(p) <= s.Radius
+}
+
+// touching spheres intersect
+func (s Sphere) Intersects(o Sphere) bool {
+	return s.Center.Dist(o.Center) <= s.Radius+o.Radius
+}
+
+func (s Sphere) Bounds() AABB3D {
+	return NewAABB3D(s.Center, Vec3D{X: s.Radius, Y: s.Radius, Z: s.Radius})
+}
+
+// returns the nearest non negative ray param, 0 when the origin is inside
+func (s Sphere) IntersectRay(r Ray3D) (float64, bool) {
+	m := r.Origin.SubVec(s.Center)
+
+	a := r.Dir.Dot(r.Dir)
+	if a == 0 {
+		return 0, false
+	}
+
+	b := m.Dot(r.Dir)
+	c := m.Dot(m) - (s.Radius * s.Radius)
+
+	if c <= 0 {
+		return 0, true
+	}
+
+	disc := (b * b) - (a * c)
+	if b > 0 || disc < 0 {
+		return 0, false
+	}
+
+	return (-b - math.Sqrt(disc)) / a, true
+}
+
+func (s Sphere) Support(dir Vec3D) Vec3D {
+	if _, err := dir.Normalize(); err != nil {
+		return s.Center
+	}
+
+	return s.Center.AddVec(dir.ScalerMulVec(s.Radius))
+}
@@ -0,0 +1,452 @@
+package golem
+
+import "math"
+
+// Catmull-Rom knot spacing
+const (
+	CatmullRomUniform     = 0.0
+	CatmullRomCentripetal = 0.5
+	CatmullRomChordal     = 1.0
+)
+
+// Cubic segment from P0 to P1 leaving with tangent M0 and arriving with M1
+type Hermite2D struct {
+	P0, M0, P1, M1 Vec2D
+}
+
+// Passes through every point with one unit of domain per segment. The ends
+// are extended by mirroring the neighbouring point
+type CatmullRom2D struct {
+	Points []Vec2D
+	Alpha  float64
+}
+
+// Uniform cubic B-spline with one unit of domain per segment. A clamped
+// spline adds a mirrored phantom point past each end so the curve starts
+// and ends on the first and last points
+type BSpline2D struct {
+	Points  []Vec2D
+	Clamped bool
+}
+
+func (h Hermite2D) ToBezier() CubicBezier2D {
+	return CubicBezier2D{
+		P0: h.P0,
+		P1: h.P0.AddVec(h.M0.ScalerMulVec(1.0 / 3)),
+		P2: h.P1.SubVec(h.M1.ScalerMulVec(1.0 / 3)),
+		P3: h.P1,
+	}
+}
+
+func (h Hermite2D) Domain() (float64, float64) {
+	return 0, 1
+}
+
+func (h Hermite2D) At(t float64) Vec2D {
+	return h.ToBezier().At(t)
+}
+
+func (h Hermite2D) Derivative(t float64) Vec2D {
+	return h.ToBezier().Derivative(t)
+}
+
+func (h Hermite2D) SecondDerivative(t float64) Vec2D {
+	return h.ToBezier().SecondDerivative(t)
+}
+
+func (h Hermite2D) Bounds() AABB2D {
+	return h.ToBezier().Bounds()
+}
+
+// alpha is CatmullRomUniform, CatmullRomCentripetal, CatmullRomChordal or
+// anything in between
+func NewCatmullRom2D(points []Vec2D, alpha float64) (*CatmullRom2D, error) {
+	if len(points) < 2 {
+		return nil, ErrInvalidLen
+	}
+
+	pts := make([]Vec2D, len(points))
+	copy(pts, points)
+
+	return &CatmullRom2D{Points: pts, Alpha: alpha}, nil
+}
+
+func (c *CatmullRom2D) Segments() int {
+	return len(c.Points) - 1
+}
+
+// Barry-Goldman tangents for the segment between Points[i] and Points[i+1]
+// rescaled to a unit param range
+func (c *CatmullRom2D) Segment(i int) Hermite2D {
+	n := len(c.Points)
+	p1, p2 := c.Points[i], c.Points[i+1]
+
+	p0 := p1.ScalerMulVec(2).SubVec(p2)
+	if i > 0 {
+		p0 = c.Points[i-1]
+	}
+
+	p3 := p2.ScalerMulVec(2).SubVec(p1)
+	if i+2 < n {
+		p3 = c.Points[i+2]
+	}
+
+	t12 := math.Pow(p1.Dist(p2), c.Alpha)
+	if t12 == 0 {
+		return Hermite2D{P0: p1, P1: p2}
+	}
+
+	t01 := math.Pow(p0.Dist(p1), c.Alpha)
+	if t01 == 0 {
+		t01 = t12
+	}
+
+	t23 := math.Pow(p2.Dist(p3), c.Alpha)
+	if t23 == 0 {
+		t23 = t12
+	}
+
+	chord := p2.SubVec(p1)
+
+	m1 := p1.SubVec(p0).ScalerMulVec(1 / t01).SubVec(p2.SubVec(p0).ScalerMulVec(1 / (t01 + t12)))
+	m2 := p3.SubVec(p2).ScalerMulVec(1 / t23).SubVec(p3.SubVec(p1).ScalerMulVec(1 / (t12 + t23)))
+
+	return Hermite2D{
+		P0: p1,
+		M0: chord.AddVec(m1.ScalerMulVec(t12)),
+		P1: p2,
+		M1: chord.AddVec(m2.ScalerMulVec(t12)),
+	}
+}
+
+func (c *CatmullRom2D) Domain() (float64, float64) {
+	return 0, float64(c.Segments())
+}
+
+func (c *CatmullRom2D) At(t float64) Vec2D {
+	i, u := splineLocate(t, c.Segments())
+	return c.Segment(i).At(u)
+}
+
+func (c *CatmullRom2D) Derivative(t float64) Vec2D {
+	i, u := splineLocate(t, c.Segments())
+	return c.Segment(i).Derivative(u)
+}
+
+func (c *CatmullRom2D) SecondDerivative(t float64) Vec2D {
+	i, u := splineLocate(t, c.Segments())
+	return c.Segment(i).SecondDerivative(u)
+}
+
+func (c *CatmullRom2D) Bounds() AABB2D {
+	box := c.Segment(0).Bounds()
+	for i := 1; i < c.Segments(); i++ {
+		box = box.Union(c.Segment(i).Bounds())
+	}
+
+	return box
+}
+
+// an unclamped spline needs at least 4 points, a clamped one 2
+func NewBSpline2D(points []Vec2D, clamped bool) (*BSpline2D, error) {
+	if len(points) < 2 || (!clamped && len(points) < 4) {
+		return nil, ErrInvalidLen
+	}
+
+	pts := make([]Vec2D, len(points))
+	copy(pts, points)
+
+	return &BSpline2D{Points: pts, Clamped: clamped}, nil
+}
+
+// control point i including the phantom points of a clamped spline
+func (b *BSpline2D) control(i int) Vec2D {
+	if !b.Clamped {
+		return b.Points[i]
+	}
+
+	n := len(b.Points)
+	switch {
+	case i == 0:
+		return b.Points[0].ScalerMulVec(2).SubVec(b.Points[1])
+	case i == n+1:
+		return b.Points[n-1].ScalerMulVec(2).SubVec(b.Points[n-2])
+	default:
+		return b.Points[i-1]
+	}
+}
+
+func (b *BSpline2D) Segments() int {
+	if b.Clamped {
+		return len(b.Points) - 1
+	}
+
+	return len(b.Points) - 3
+}
+
+// Bezier form of segment i
+func (b *BSpline2D) Segment(i int) CubicBezier2D {
+	q0, q1, q2, q3 := b.control(i), b.control(i+1), b.control(i+2), b.control(i+3)
+
+	return CubicBezier2D{
+		P0: q0.AddVec(q1.ScalerMulVec(4)).AddVec(q2).ScalerMulVec(1.0 / 6),
+		P1: q1.ScalerMulVec(2).AddVec(q2).ScalerMulVec(1.0 / 3),
+		P2: q1.AddVec(q2.ScalerMulVec(2)).ScalerMulVec(1.0 / 3),
+		P3: q1.AddVec(q2.ScalerMulVec(4)).AddVec(q3).ScalerMulVec(1.0 / 6),
+	}
+}
+
+func (b *BSpline2D) Domain() (float64, float64) {
+	return 0, float64(b.Segments())
+}
+
+func (b *BSpline2D) At(t float64) Vec2D {
+	i, u := splineLocate(t, b.Segments())
+	return b.Segment(i).At(u)
+}
+
+func (b *BSpline2D) Derivative(t float64) Vec2D {
+	i, u := splineLocate(t, b.Segments())
+	return b.Segment(i).Derivative(u)
+}
+
+func (b *BSpline2D) SecondDerivative(t float64) Vec2D {
+	i, u := splineLocate(t, b.Segments())
+	return b.Segment(i).SecondDerivative(u)
+}
+
+func (b *BSpline2D) Bounds() AABB2D {
+	box := b.Segment(0).Bounds()
+	for i := 1; i < b.Segments(); i++ {
+		box = box.Union(b.Segment(i).Bounds())
+	}
+
+	return box
+}
+
+// Cubic segment from P0 to P1 leaving with tangent M0 and arriving with M1
+type Hermite3D struct {
+	P0, M0, P1, M1 Vec3D
+}
+
+// Passes through every point with one unit of domain per segment. The ends
+// are extended by mirroring the neighbouring point
+type CatmullRom3D struct {
+	Points []Vec3D
+	Alpha  float64
+}
+
+// Uniform cubic B-spline with one unit of domain per segment. A clamped
+// spline adds a mirrored phantom point past each end so the curve starts
+// and ends on the first and last points
+type BSpline3D struct {
+	Points  []Vec3D
+	Clamped bool
+}
+
+func (h Hermite3D) ToBezier() CubicBezier3D {
+	return CubicBezier3D{
+		P0: h.P0,
+		P1: h.P0.AddVec(h.M0.ScalerMulVec(1.0 / 3)),
+		P2: h.P1.SubVec(h.M1.ScalerMulVec(1.0 / 3)),
+		P3: h.P1,
+	}
+}
+
+func (h Hermite3D) Domain() (float64, float64) {
+	return 0, 1
+}
+
+func (h Hermite3D) At(t float64) Vec3D {
+	return h.ToBezier().At(t)
+}
+
+func (h Hermite3D) Derivative(t float64) Vec3D {
+	return h.ToBezier().Derivative(t)
+}
+
+func (h Hermite3D) SecondDerivative(t float64) Vec3D {
+	return h.ToBezier().SecondDerivative(t)
+}
+
+func (h Hermite3D) Bounds() AABB3D {
+	return h.ToBezier().Bounds()
+}
+
+// alpha is CatmullRomUniform, CatmullRomCentripetal, CatmullRomChordal or
+// anything in between
+func NewCatmullRom3D(points []Vec3D, alpha float64) (*CatmullRom3D, error) {
+	if len(points) < 2 {
+		return nil, ErrInvalidLen
+	}
+
+	pts := make([]Vec3D, len(points))
+	copy(pts, points)
+
+	return &CatmullRom3D{Points: pts, Alpha: alpha}, nil
+}
+
+func (c *CatmullRom3D) Segments() int {
+	return len(c.Points) - 1
+}
+
+// Barry-Goldman tangents for the segment between Points[i] and Points[i+1]
+// rescaled to a unit param range
+func (c *CatmullRom3D) Segment(i int) Hermite3D {
+	n := len(c.Points)
+	p1, p2 := c.Points[i], c.Points[i+1]
+
+	p0 := p1.ScalerMulVec(2).SubVec(p2)
+	if i > 0 {
+		p0 = c.Points[i-1]
+	}
+
+	p3 := p2.ScalerMulVec(2).SubVec(p1)
+	if i+2 < n {
+		p3 = c.Points[i+2]
+	}
+
+	t12 := math.Pow(p1.Dist(p2), c.Alpha)
+	if t12 == 0 {
+		return Hermite3D{P0: p1, P1: p2}
+	}
+
+	t01 := math.Pow(p0.Dist(p1), c.Alpha)
+	if t01 == 0 {
+		t01 = t12
+	}
+
+	t23 := math.Pow(p2.Dist(p3), c.Alpha)
+	if t23 == 0 {
+		t23 = t12
+	}
+
+	chord := p2.SubVec(p1)
+
+	m1 := p1.SubVec(p0).ScalerMulVec(1 / t01).SubVec(p2.SubVec(p0).ScalerMulVec(1 / (t01 + t12)))
+	m2 := p3.SubVec(p2).ScalerMulVec(1 / t23).SubVec(p3.SubVec(p1).ScalerMulVec(1 / (t12 + t23)))
+
+	return Hermite3D{
+		P0: p1,
+		M0: chord.AddVec(m1.ScalerMulVec(t12)),
+		P1: p2,
+		M1: chord.AddVec(m2.ScalerMulVec(t12)),
+	}
+}
+
+func (c *CatmullRom3D) Domain() (float64, float64) {
+	return 0, float64(c.Segments())
+}
+
+func (c *CatmullRom3D) At(t float64) Vec3D {
+	i, u := splineLocate(t, c.Segments())
+	return c.Segment(i).At(u)
+}
+
+func (c *CatmullRom3D) Derivative(t float64) Vec3D {
+	i, u := splineLocate(t, c.Segments())
+	return c.Segment(i).Derivative(u)
+}
+
+func (c *CatmullRom3D) SecondDerivative(t float64) Vec3D {
+	i, u := splineLocate(t, c.Segments())
+	return c.Segment(i).SecondDerivative(u)
+}
+
+func (c *CatmullRom3D) Bounds() AABB3D {
+	box := c.Segment(0).Bounds()
+	for i := 1; i < c.Segments(); i++ {
+		box = box.Union(c.Segment(i).Bounds())
+	}
+
+	return box
+}
+
+// an unclamped spline needs at least 4 points, a clamped one 2
+func NewBSpline3D(points []Vec3D, clamped bool) (*BSpline3D, error) {
+	if len(points) < 2 || (!clamped && len(points) < 4) {
+		return nil, ErrInvalidLen
+	}
+
+	pts := make([]Vec3D, len(points))
+	copy(pts, points)
+
+	return &BSpline3D{Points: pts, Clamped: clamped}, nil
+}
+
+// control point i including the phantom points of a clamped spline
+func (b *BSpline3D) control(i int) Vec3D {
+	if !b.Clamped {
+		return b.Points[i]
+	}
+
+	n := len(b.Points)
+	switch {
+	case i == 0:
+		return b.Points[0].ScalerMulVec(2).SubVec(b.Points[1])
+	case i == n+1:
+		return b.Points[n-1].ScalerMulVec(2).SubVec(b.Points[n-2])
+	default:
+		return b.Points[i-1]
+	}
+}
+
+func (b *BSpline3D) Segments() int {
+	if b.Clamped {
+		return len(b.Points) - 1
+	}
+
+	return len(b.Points) - 3
+}
+
+// Bezier form of segment i
+func (b *BSpline3D) Segment(i int) CubicBezier3D {
+	q0, q1, q2, q3 := b.control(i), b.control(i+1), b.control(i+2), b.control(i+3)
+
+	return CubicBezier3D{
+		P0: q0.AddVec(q1.ScalerMulVec(4)).AddVec(q2).ScalerMulVec(1.0 / 6),
+		P1: q1.ScalerMulVec(2).AddVec(q2).ScalerMulVec(1.0 / 3),
+		P2: q1.AddVec(q2.ScalerMulVec(2)).ScalerMulVec(1.0 / 3),
+		P3: q1.AddVec(q2.ScalerMulVec(4)).AddVec(q3).ScalerMulVec(1.0 / 6),
+	}
+}
+
+func (b *BSpline3D) Domain() (float64, float64) {
+	return 0, float64(b.Segments())
+}
+
+func (b *BSpline3D) At(t float64) Vec3D {
+	i, u := splineLocate(t, b.Segments())
+	return b.Segment(i).At(u)
+}
+
+func (b *BSpline3D) Derivative(t float64) Vec3D {
+	i, u := splineLocate(t, b.Segments())
+	return b.Segment(i).Derivative(u)
+}
+
+func (b *BSpline3D) SecondDerivative(t float64) Vec3D {
+	i, u := splineLocate(t, b.Segments())
+	return b.Segment(i).SecondDerivative(u)
+}
+
+func (b *BSpline3D) Bounds() AABB3D {
+	box := b.Segment(0).Bounds()
+	for i := 1; i < b.Segments(); i++ {
+		box = box.Union(b.Segment(i).Bounds())
+	}
+
+	return box
+}
+
+// segment holding t and the param inside it, t is clamped to the domain
+func splineLocate(t float64, segments int) (int, float64) {
+	t = Clamp(t, 0, float64(segments))
+
+	i := int(t)
+	if i >= segments {
+		i = segments - 1
+	}
+
+	return i, t - float64(i)
+}
@@ -0,0 +1,681 @@
+package golem
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Vehicle moved by steering behaviors. Forces are accelerations, the
+// agent has unit mass
+type Agent2D struct {
+	Position Vec2D
+	Velocity Vec2D
+	// direction of travel, kept while the agent stands still
+	Heading Vec2D
+
+	MaxSpeed float64
+	MaxForce float64
+	Radius   float64
+}
+
+type Agent3D struct {
+	Position Vec3D
+	Velocity Vec3D
+	Heading  Vec3D
+
+	MaxSpeed float64
+	MaxForce float64
+	Radius   float64
+}
+
+// Steering force wanted by a behavior for the agent
+type Behavior2D interface {
+	Steer(a *Agent2D) Vec2D
+}
+
+type Behavior3D interface {
+	Steer(a *Agent3D) Vec3D
+}
+
+func NewAgent2D(position Vec2D, maxSpeed, maxForce float64) *Agent2D {
+	return &Agent2D{Position: position, Heading: Vec2D{X: 1}, MaxSpeed: maxSpeed, MaxForce: maxForce}
+}
+
+func NewAgent3D(position Vec3D, maxSpeed, maxForce float64) *Agent3D {
+	return &Agent3D{Position: position, Heading: Vec3D{X: 1}, MaxSpeed: maxSpeed, MaxForce: maxForce}
+}
+
+// Applies the force of b clamped to MaxForce for dt, the speed is clamped
+// to MaxSpeed
+func (a *Agent2D) Update(b Behavior2D, dt float64) {
+	f := truncate2D(b.Steer(a), a.MaxForce)
+
+	a.Velocity = truncate2D(a.Velocity.AddVec(f.ScalerMulVec(dt)), a.MaxSpeed)
+	a.Position.Add(a.Velocity.ScalerMulVec(dt))
+
+	if h := a.Velocity; h.Length() > 1e-9 {
+		h.Normalize()
+		a.Heading = h
+	}
+}
+
+func (a *Agent3D) Update(b Behavior3D, dt float64) {
+	f := truncate3D(b.Steer(a), a.MaxForce)
+
+	a.Velocity = truncate3D(a.Velocity.AddVec(f.ScalerMulVec(dt)), a.MaxSpeed)
+	a.Position.Add(a.Velocity.ScalerMulVec(dt))
+
+	if h := a.Velocity; h.Length() > 1e-9 {
+		h.Normalize()
+		a.Heading = h
+	}
+}
+
+func truncate2D(v Vec2D, limit float64) Vec2D {
+	if l := v.Length(); l > limit {
+		return v.ScalerMulVec(limit / l)
+	}
+
+	return v
+}
+
+func truncate3D(v Vec3D, limit float64) Vec3D {
+	if l := v.Length(); l > limit {
+		return v.ScalerMulVec(limit / l)
+	}
+
+	return v
+}
+
+// force turning the velocity towards full speed at the target
+func seek2D(a *Agent2D, target Vec2D) Vec2D {
+	d := target.SubVec(a.Position)
+	if _, err := d.Normalize(); err != nil {
+		return a.Velocity.ScalerMulVec(-1)
+	}
+
+	return d.ScalerMulVec(a.MaxSpeed).SubVec(a.Velocity)
+}
+
+func seek3D(a *Agent3D, target Vec3D) Vec3D {
+	d := target.SubVec(a.Position)
+	if _, err := d.Normalize(); err != nil {
+		return a.Velocity.ScalerMulVec(-1)
+	}
+
+	return d.ScalerMulVec(a.MaxSpeed).SubVec(a.Velocity)
+}
+
+// seek slowing down linearly inside slow to stop on the target
+func arrive2D(a *Agent2D, target Vec2D, slow float64) Vec2D {
+	d := target.SubVec(a.Position)
+	dist, err := d.Normalize()
+	if err != nil {
+		return a.Velocity.ScalerMulVec(-1)
+	}
+
+	speed := a.MaxSpeed
+	if dist < slow {
+		speed *= dist / slow
+	}
+
+	return d.ScalerMulVec(speed).SubVec(a.Velocity)
+}
+
+func arrive3D(a *Agent3D, target Vec3D, slow float64) Vec3D {
+	d := target.SubVec(a.Position)
+	dist, err := d.Normalize()
+	if err != nil {
+		return a.Velocity.ScalerMulVec(-1)
+	}
+
+	speed := a.MaxSpeed
+	if dist < slow {
+		speed *= dist / slow
+	}
+
+	return d.ScalerMulVec(speed).SubVec(a.Velocity)
+}
+
+// time for the agent to cover dist, clamped to limit when it is positive
+func predictTime(dist, speed, limit float64) float64 {
+	t := 0.0
+	if speed > 0 {
+		t = dist / speed
+	}
+	if limit > 0 {
+		t = math.Min(t, limit)
+	}
+
+	return t
+}
+
+type Seek2D struct {
+	Target Vec2D
+}
+
+type Seek3D struct {
+	Target Vec3D
+}
+
+func (s Seek2D) Steer(a *Agent2D) Vec2D {
+	return seek2D(a, s.Target)
+}
+
+func (s Seek3D) Steer(a *Agent3D) Vec3D {
+	return seek3D(a, s.Target)
+}
+
+// Runs from the target, only within PanicDistance when that is positive
+type Flee2D struct {
+	Target        Vec2D
+	PanicDistance float64
+}
+
+type Flee3D struct {
+	Target        Vec3D
+	PanicDistance float64
+}
+
+func (f Flee2D) Steer(a *Agent2D) Vec2D {
+	if f.PanicDistance > 0 && a.Position.Dist(f.Target) > f.PanicDistance {
+		return Vec2D{}
+	}
+
+	return seek2D(a, a.Position.ScalerMulVec(2).SubVec(f.Target))
+}
+
+func (f Flee3D) Steer(a *Agent3D) Vec3D {
+	if f.PanicDistance > 0 && a.Position.Dist(f.Target) > f.PanicDistance {
+		return Vec3D{}
+	}
+
+	return seek3D(a, a.Position.ScalerMulVec(2).SubVec(f.Target))
+}
+
+// Seeks the target slowing down within SlowRadius to stop on it
+type Arrive2D struct {
+	Target     Vec2D
+	SlowRadius float64
+}
+
+type Arrive3D struct {
+	Target     Vec3D
+	SlowRadius float64
+}
+
+func (r Arrive2D) Steer(a *Agent2D) Vec2D {
+	return arrive2D(a, r.Target, r.SlowRadius)
+}
+
+func (r Arrive3D) Steer(a *Agent3D) Vec3D {
+	return arrive3D(a, r.Target, r.SlowRadius)
+}
+
+// Seeks where the target will be by the time the agent gets there, looking
+// at most MaxPrediction seconds ahead when that is positive
+type Pursue2D struct {
+	Target        *Agent2D
+	MaxPrediction float64
+}
+
+type Pursue3D struct {
+	Target        *Agent3D
+	MaxPrediction float64
+}
+
+func (p Pursue2D) Steer(a *Agent2D) Vec2D {
+	t := predictTime(a.Position.Dist(p.Target.Position), a.MaxSpeed, p.MaxPrediction)
+	return seek2D(a, p.Target.Position.AddVec(p.Target.Velocity.ScalerMulVec(t)))
+}
+
+func (p Pursue3D) Steer(a *Agent3D) Vec3D {
+	t := predictTime(a.Position.Dist(p.Target.Position), a.MaxSpeed, p.MaxPrediction)
+	return seek3D(a, p.Target.Position.AddVec(p.Target.Velocity.ScalerMulVec(t)))
+}
+
+// Flees from where the pursuer is heading
+type Evade2D struct {
+	Target        *Agent2D
+	MaxPrediction float64
+	PanicDistance float64
+}
+
+type Evade3D struct {
+	Target        *Agent3D
+	MaxPrediction float64
+	PanicDistance float64
+}
+
+func (e Evade2D) Steer(a *Agent2D) Vec2D {
+	dist := a.Position.Dist(e.Target.Position)
+	if e.PanicDistance > 0 && dist > e.PanicDistance {
+		return Vec2D{}
+	}
+
+	t := predictTime(dist, e.Target.MaxSpeed, e.MaxPrediction)
+	return Flee2D{Target: e.Target.Position.AddVec(e.Target.Velocity.ScalerMulVec(t))}.Steer(a)
+}
+
+func (e Evade3D) Steer(a *Agent3D) Vec3D {
+	dist := a.Position.Dist(e.Target.Position)
+	if e.PanicDistance > 0 && dist > e.PanicDistance {
+		return Vec3D{}
+	}
+
+	t := predictTime(dist, e.Target.MaxSpeed, e.MaxPrediction)
+	return Flee3D{Target: e.Target.Position.AddVec(e.Target.Velocity.ScalerMulVec(t))}.Steer(a)
+}
+
+// Seeks a point on a circle Distance ahead of the agent, the point drifts
+// by at most Jitter per call. Rand may be nil to use the global source
+type Wander2D struct {
+	Distance float64
+	Radius   float64
+	// radians
+	Jitter float64
+	Rand   *rand.Rand
+
+	angle float64
+}
+
+// the point drifts over a sphere, Jitter is a distance
+type Wander3D struct {
+	Distance float64
+	Radius   float64
+	Jitter   float64
+	Rand     *rand.Rand
+
+	offset Vec3D
+}
+
+func wanderRand(r *rand.Rand) float64 {
+	if r != nil {
+		return r.Float64()*2 - 1
+	}
+
+	return rand.Float64()*2 - 1
+}
+
+func (w *Wander2D) Steer(a *Agent2D) Vec2D {
+	w.angle += wanderRand(w.Rand) * w.Jitter
+
+	r := RotMat2D{}
+	r.Set(w.angle)
+	offset := r.RotateVec2D(a.Heading.ScalerMulVec(w.Radius))
+
+	return seek2D(a, a.Position.AddVec(a.Heading.ScalerMulVec(w.Distance)).AddVec(offset))
+}
+
+func (w *Wander3D) Steer(a *Agent3D) Vec3D {
+	if w.offset == (Vec3D{}) {
+		w.offset = a.Heading
+	}
+
+	w.offset.Add(Vec3D{X: wanderRand(w.Rand), Y: wanderRand(w.Rand), Z: wanderRand(w.Rand)}.ScalerMulVec(w.Jitter))
+	if _, err := w.offset.Normalize(); err != nil {
+		w.offset = a.Heading
+	}
+
+	return seek3D(a, a.Position.AddVec(a.Heading.ScalerMulVec(w.Distance)).AddVec(w.offset.ScalerMulVec(w.Radius)))
+}
+
+// Pushes sideways away from the nearest obstacle in the strip Lookahead
+// long and as wide as the agent in front of it, harder the closer it is.
+// No Lookahead sees nothing
+type ObstacleAvoidance2D struct {
+	Obstacles []Circle
+	Lookahead float64
+}
+
+type ObstacleAvoidance3D struct {
+	Obstacles []Sphere
+	Lookahead float64
+}
+
+func (o ObstacleAvoidance2D) Steer(a *Agent2D) Vec2D {
+	if o.Lookahead <= 0 {
+		return Vec2D{}
+	}
+
+	side := a.Heading.LeftPerpendicular()
+	nearest, push := math.Inf(1), Vec2D{}
+
+	for _, c := range o.Obstacles {
+		d := c.Center.SubVec(a.Position)
+		ahead, across := d.Dot(a.Heading), d.Dot(side)
+		if ahead < -c.Radius || ahead-c.Radius > o.Lookahead || math.Abs(across) >= c.Radius+a.Radius {
+			continue
+		}
+		if ahead >= nearest {
+			continue
+		}
+
+		nearest = ahead
+		away := side.ScalerMulVec(-1)
+		if across < 0 {
+			away = side
+		}
+		push = away.ScalerMulVec(a.MaxForce * (1 - math.Max(ahead-c.Radius, 0)/o.Lookahead))
+	}
+
+	return push
+}
+
+func (o ObstacleAvoidance3D) Steer(a *Agent3D) Vec3D {
+	if o.Lookahead <= 0 {
+		return Vec3D{}
+	}
+
+	nearest, push := math.Inf(1), Vec3D{}
+
+	for _, s := range o.Obstacles {
+		d := s.Center.SubVec(a.Position)
+		ahead := d.Dot(a.Heading)
+		across := d.SubVec(a.Heading.ScalerMulVec(ahead))
+		if ahead < -s.Radius || ahead-s.Radius > o.Lookahead || across.Length() >= s.Radius+a.Radius {
+			continue
+		}
+		if ahead >= nearest {
+			continue
+		}
+
+		// dead ahead any side will do
+		if _, err := across.Normalize(); err != nil {
+			across = anyPerpendicular(a.Heading)
+		}
+
+		nearest = ahead
+		push = across.ScalerMulVec(-a.MaxForce * (1 - math.Max(ahead-s.Radius, 0)/o.Lookahead))
+	}
+
+	return push
+}
+
+// Seeks a point Lookahead further along the path than the agent, arriving
+// at the end unless Loop starts over from the first point. A looping path
+// is closed by repeating its first point at the end
+type PathFollow2D struct {
+	Points    []Vec2D
+	Lookahead float64
+	Loop      bool
+}
+
+type PathFollow3D struct {
+	Path      *Path3D
+	Lookahead float64
+	Loop      bool
+}
+
+func (p PathFollow2D) Steer(a *Agent2D) Vec2D {
+	if len(p.Points) == 0 {
+		return Vec2D{}
+	}
+
+	// arc length of the closest point and the total length
+	along, best, total := 0.0, math.Inf(1), 0.0
+	for i := 0; i+1 < len(p.Points); i++ {
+		s, e := p.Points[i], p.Points[i+1]
+		ab := e.SubVec(s)
+		l := ab.Length()
+
+		u := 0.0
+		if l > 0 {
+			ap := a.Position.SubVec(s)
+			u = Clamp(ap.Dot(ab)/(l*l), 0, 1)
+		}
+		if d := a.Position.Dist(s.AddVec(ab.ScalerMulVec(u))); d < best {
+			along, best = total+u*l, d
+		}
+		total += l
+	}
+
+	s := along + p.Lookahead
+	if p.Loop && total > 0 {
+		s = math.Mod(s, total)
+	} else if s >= total {
+		return arrive2D(a, p.Points[len(p.Points)-1], p.Lookahead)
+	}
+
+	for i := 0; i+1 < len(p.Points); i++ {
+		ab := p.Points[i+1].SubVec(p.Points[i])
+		l := ab.Length()
+		if l > 0 && s <= l {
+			return seek2D(a, p.Points[i].AddVec(ab.ScalerMulVec(s/l)))
+		}
+		s -= l
+	}
+
+	return seek2D(a, p.Points[len(p.Points)-1])
+}
+
+func (p PathFollow3D) Steer(a *Agent3D) Vec3D {
+	t, _ := p.Path.ClosestPoint(a.Position)
+	s := p.Path.DistanceAtParam(t) + p.Lookahead
+
+	total := p.Path.Length()
+	if p.Loop && total > 0 {
+		s = math.Mod(s, total)
+	} else if s >= total {
+		return arrive3D(a, p.Path.Points[len(p.Path.Points)-1], p.Lookahead)
+	}
+
+	return seek3D(a, p.Path.PointAtDistance(s))
+}
+
+// Flocking rules over the agents of Flock within Radius of the agent, the
+// agent itself may be part of the Flock
+type Separation2D struct {
+	Flock  []*Agent2D
+	Radius float64
+}
+
+type Alignment2D struct {
+	Flock  []*Agent2D
+	Radius float64
+}
+
+type Cohesion2D struct {
+	Flock  []*Agent2D
+	Radius float64
+}
+
+type Separation3D struct {
+	Flock  []*Agent3D
+	Radius float64
+}
+
+type Alignment3D struct {
+	Flock  []*Agent3D
+	Radius float64
+}
+
+type Cohesion3D struct {
+	Flock  []*Agent3D
+	Radius float64
+}
+
+func neighbors2D(a *Agent2D, flock []*Agent2D, radius float64) []*Agent2D {
+	out := make([]*Agent2D, 0)
+	for _, n := range flock {
+		if n != a && a.Position.Dist(n.Position) < radius {
+			out = append(out, n)
+		}
+	}
+
+	return out
+}
+
+func neighbors3D(a *Agent3D, flock []*Agent3D, radius float64) []*Agent3D {
+	out := make([]*Agent3D, 0)
+	for _, n := range flock {
+		if n != a && a.Position.Dist(n.Position) < radius {
+			out = append(out, n)
+		}
+	}
+
+	return out
+}
+
+// away from each neighbor, the full MaxForce at no distance down to none
+// at Radius
+func (s Separation2D) Steer(a *Agent2D) Vec2D {
+	f := Vec2D{}
+	for _, n := range neighbors2D(a, s.Flock, s.Radius) {
+		away := a.Position.SubVec(n.Position)
+		dist, err := away.Normalize()
+		if err != nil {
+			away, dist = a.Heading.LeftPerpendicular(), 0
+		}
+		f.Add(away.ScalerMulVec(a.MaxForce * (1 - dist/s.Radius)))
+	}
+
+	return f
+}
+
+func (s Separation3D) Steer(a *Agent3D) Vec3D {
+	f := Vec3D{}
+	for _, n := range neighbors3D(a, s.Flock, s.Radius) {
+		away := a.Position.SubVec(n.Position)
+		dist, err := away.Normalize()
+		if err != nil {
+			away, dist = anyPerpendicular(a.Heading), 0
+		}
+		f.Add(away.ScalerMulVec(a.MaxForce * (1 - dist/s.Radius)))
+	}
+
+	return f
+}
+
+// towards the mean velocity of the neighbors
+func (l Alignment2D) Steer(a *Agent2D) Vec2D {
+	near := neighbors2D(a, l.Flock, l.Radius)
+	if len(near) == 0 {
+		return Vec2D{}
+	}
+
+	v := Vec2D{}
+	for _, n := range near {
+		v.Add(n.Velocity)
+	}
+
+	return v.ScalerMulVec(1 / float64(len(near))).SubVec(a.Velocity)
+}
+
+func (l Alignment3D) Steer(a *Agent3D) Vec3D {
+	near := neighbors3D(a, l.Flock, l.Radius)
+	if len(near) == 0 {
+		return Vec3D{}
+	}
+
+	v := Vec3D{}
+	for _, n := range near {
+		v.Add(n.Velocity)
+	}
+
+	return v.ScalerMulVec(1 / float64(len(near))).SubVec(a.Velocity)
+}
+
+// seeks the center of the neighbors
+func (c Cohesion2D) Steer(a *Agent2D) Vec2D {
+	near := neighbors2D(a, c.Flock, c.Radius)
+	if len(near) == 0 {
+		return Vec2D{}
+	}
+
+	center := Vec2D{}
+	for _, n := range near {
+		center.Add(n.Position)
+	}
+
+	return seek2D(a, center.ScalerMulVec(1/float64(len(near))))
+}
+
+func (c Cohesion3D) Steer(a *Agent3D) Vec3D {
+	near := neighbors3D(a, c.Flock, c.Radius)
+	if len(near) == 0 {
+		return Vec3D{}
+	}
+
+	center := Vec3D{}
+	for _, n := range near {
+		center.Add(n.Position)
+	}
+
+	return seek3D(a, center.ScalerMulVec(1/float64(len(near))))
+}
+
+type WeightedBehavior2D struct {
+	Behavior Behavior2D
+	Weight   float64
+}
+
+type WeightedBehavior3D struct {
+	Behavior Behavior3D
+	Weight   float64
+}
+
+// Weighted sum of the behaviors
+type Blend2D struct {
+	Behaviors []WeightedBehavior2D
+}
+
+type Blend3D struct {
+	Behaviors []WeightedBehavior3D
+}
+
+// Weighted forces summed in order until MaxForce is used up, what comes
+// first can crowd out what comes later
+type Priority2D struct {
+	Behaviors []WeightedBehavior2D
+}
+
+type Priority3D struct {
+	Behaviors []WeightedBehavior3D
+}
+
+func (b Blend2D) Steer(a *Agent2D) Vec2D {
+	f := Vec2D{}
+	for _, w := range b.Behaviors {
+		f.Add(w.Behavior.Steer(a).ScalerMulVec(w.Weight))
+	}
+
+	return f
+}
+
+func (b Blend3D) Steer(a *Agent3D) Vec3D {
+	f := Vec3D{}
+	for _, w := range b.Behaviors {
+		f.Add(w.Behavior.Steer(a).ScalerMulVec(w.Weight))
+	}
+
+	return f
+}
+
+func (p Priority2D) Steer(a *Agent2D) Vec2D {
+	f := Vec2D{}
+	for _, w := range p.Behaviors {
+		left := a.MaxForce - f.Length()
+		if left <= 0 {
+			break
+		}
+
+		f.Add(truncate2D(w.Behavior.Steer(a).ScalerMulVec(w.Weight), left))
+	}
+
+	return f
+}
+
+func (p Priority3D) Steer(a *Agent3D) Vec3D {
+	f := Vec3D{}
+	for _, w := range p.Behaviors {
+		left := a.MaxForce - f.Length()
+		if left <= 0 {
+			break
+		}
+
+		f.Add(truncate3D(w.Behavior.Steer(a).ScalerMulVec(w.Weight), left))
+	}
+
+	return f
+}
@@ -0,0 +1,238 @@
+package golem
+
+import "sort"
+
+// Sweep and prune broadphase. Boxes are kept sorted by their min along the
+// axis with the largest spread of centers, objects move little between
+// frames so the insertion sort stays close to linear
+type SweepAndPrune3D struct {
+	axis  int
+	added int
+	boxes map[int]AABB3D
+	order []int
+	pairs map[[2]int]bool
+}
+
+// 2D boxes are swept as flat 3D boxes
+type SweepAndPrune2D struct {
+	sap *SweepAndPrune3D
+}
+
+// Pair changes since the previous Update, every pair has the smaller id
+// first and each list is sorted
+type OverlapEvents struct {
+	Begin   [][2]int
+	Persist [][2]int
+	End     [][2]int
+}
+
+func NewSweepAndPrune3D() *SweepAndPrune3D {
+	return &SweepAndPrune3D{
+		boxes: make(map[int]AABB3D),
+		pairs: make(map[[2]int]bool),
+	}
+}
+
+func (s *SweepAndPrune3D) Len() int {
+	return len(s.boxes)
+}
+
+func (s *SweepAndPrune3D) Bounds(id int) (AABB3D, bool) {
+	b, ok := s.boxes[id]
+	return b, ok
+}
+
+func (s *SweepAndPrune3D) Insert(id int, box AABB3D) error {
+	if _, ok := s.boxes[id]; ok {
+		return ErrDuplicateID
+	}
+
+	s.boxes[id] = box
+	s.order = append(s.order, id)
+	s.added++
+
+	return nil
+}
+
+// pairs with the object are reported as ended by the next Update
+func (s *SweepAndPrune3D) Remove(id int) error {
+	if _, ok := s.boxes[id]; !ok {
+		return ErrUnknownID
+	}
+
+	delete(s.boxes, id)
+	for i, o := range s.order {
+		if o == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *SweepAndPrune3D) Move(id int, box AABB3D) error {
+	if _, ok := s.boxes[id]; !ok {
+		return ErrUnknownID
+	}
+
+	s.boxes[id] = box
+
+	return nil
+}
+
+// overlapping pairs found by the last Update
+func (s *SweepAndPrune3D) Pairs() [][2]int {
+	return sortedPairs(s.pairs)
+}
+
+// Sweeps the current boxes and compares the overlaps with the last frame
+func (s *SweepAndPrune3D) Update() OverlapEvents {
+	axis := s.spreadAxis()
+
+	less := func(a, b int) bool {
+		ma := vecAxis(s.boxes[a].Min, axis)
+		mb := vecAxis(s.boxes[b].Min, axis)
+		if ma != mb {
+			return ma < mb
+		}
+		return a < b
+	}
+
+	// many new objects or a new axis make a full sort cheaper
+	if axis != s.axis || s.added*8 > len(s.order) {
+		s.axis = axis
+		sort.Slice(s.order, func(i, j int) bool {
+			return less(s.order[i], s.order[j])
+		})
+	} else {
+		for i := 1; i < len(s.order); i++ {
+			for j := i; j > 0 && less(s.order[j], s.order[j-1]); j-- {
+				s.order[j], s.order[j-1] = s.order[j-1], s.order[j]
+			}
+		}
+	}
+
+	s.added = 0
+
+	current := make(map[[2]int]bool)
+	active := make([]int, 0)
+
+	for _, id := range s.order {
+		box := s.boxes[id]
+		lo := vecAxis(box.Min, axis)
+
+		// drop the boxes that end before this one starts
+		n := 0
+		for _, a := range active {
+			if vecAxis(s.boxes[a].Max, axis) >= lo {
+				active[n] = a
+				n++
+			}
+		}
+		active = active[:n]
+
+		for _, a := range active {
+			if s.boxes[a].Intersects(box) {
+				current[[2]int{min(a, id), max(a, id)}] = true
+			}
+		}
+
+		active = append(active, id)
+	}
+
+	begin := make(map[[2]int]bool)
+	persist := make(map[[2]int]bool)
+	for p := range current {
+		if s.pairs[p] {
+			persist[p] = true
+		} else {
+			begin[p] = true
+		}
+	}
+
+	end := make(map[[2]int]bool)
+	for p := range s.pairs {
+		if !current[p] {
+			end[p] = true
+		}
+	}
+
+	s.pairs = current
+
+	return OverlapEvents{
+		Begin:   sortedPairs(begin),
+		Persist: sortedPairs(persist),
+		End:     sortedPairs(end),
+	}
+}
+
+func (s *SweepAndPrune3D) spreadAxis() int {
+	if len(s.boxes) < 2 {
+		return s.axis
+	}
+
+	var sum, sum2 Vec3D
+	for _, b := range s.boxes {
+		c := b.Center()
+		sum.Add(c)
+		sum2.Add(Vec3D{X: c.X * c.X, Y: c.Y * c.Y, Z: c.Z * c.Z})
+	}
+
+	n := float64(len(s.boxes))
+	vx := sum2.X/n - (sum.X/n)*(sum.X/n)
+	vy := sum2.Y/n - (sum.Y/n)*(sum.Y/n)
+	vz := sum2.Z/n - (sum.Z/n)*(sum.Z/n)
+
+	if vy > vx && vy >= vz {
+		return 1
+	} else if vz > vx && vz > vy {
+		return 2
+	}
+
+	return 0
+}
+
+func NewSweepAndPrune2D() *SweepAndPrune2D {
+	return &SweepAndPrune2D{sap: NewSweepAndPrune3D()}
+}
+
+func flatAABB(b AABB2D) AABB3D {
+	return AABB3D{
+		Min: Vec3D{X: b.Min.X, Y: b.Min.Y},
+		Max: Vec3D{X: b.Max.X, Y: b.Max.Y},
+	}
+}
+
+func (s *SweepAndPrune2D) Len() int {
+	return s.sap.Len()
+}
+
+func (s *SweepAndPrune2D) Bounds(id int) (AABB2D, bool) {
+	b, ok := s.sap.Bounds(id)
+
+	return AABB2D{
+		Min: Vec2D{X: b.Min.X, Y: b.Min.Y},
+		Max: Vec2D{X: b.Max.X, Y: b.Max.Y},
+	}, ok
+}
+
+func (s *SweepAndPrune2D) Insert(id int, box AABB2D) error {
+	return s.sap.Insert(id, flatAABB(box))
+}
+
+func (s *SweepAndPrune2D) Remove(id int) error {
+	return s.sap.Remove(id)
+}
+
+func (s *SweepAndPrune2D) Move(id int, box AABB2D) error {
+	return s.sap.Move(id, flatAABB(box))
+}
+
+func (s *SweepAndPrune2D) Pairs() [][2]int {
+	return s.sap.Pairs()
+}
+
+func (s *SweepAndPrune2D) Update() OverlapEvents {
+	return s.sap.Update()
+}
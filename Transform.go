@@ -0,0 +1,72 @@
+package golem
+
+// Scale, then rotation, then translation
+type Transform struct {
+	Translation Vec3D
+	Rotation    Quaternion
+	Scale       Vec3D
+}
+
+func IdentityTransform() Transform {
+	return Transform{Rotation: Quaternion{W: 1}, Scale: Vec3D{X: 1, Y: 1, Z: 1}}
+}
+
+func (t Transform) Mat4D() Mat4D {
+	return TRSMat4D(t.Translation, t.Rotation, t.Scale)
+}
+
+func (t Transform) TransformPoint(p Vec3D) Vec3D {
+	p = Vec3D{X: p.X * t.Scale.X, Y: p.Y * t.Scale.Y, Z: p.Z * t.Scale.Z}
+	return t.Rotation.ToRotMat3D().RotateVec3D(p).AddVec(t.Translation)
+}
+
+// Transform of child expressed in the space of t. Exact for uniform
+// scale, with non uniform scale the shear of the matrix product is lost
+func (t Transform) Multiply(child Transform) Transform {
+	return Transform{
+		Translation: t.TransformPoint(child.Translation),
+		Rotation:    t.Rotation.MultiplyQt(child.Rotation),
+		Scale: Vec3D{
+			X: t.Scale.X * child.Scale.X,
+			Y: t.Scale.Y * child.Scale.Y,
+			Z: t.Scale.Z * child.Scale.Z,
+		},
+	}
+}
+
+// Blends towards o by w with nlerp on the hemisphere of t, w is clamped to
+// [0, 1] for every channel
+func (t Transform) Lerp(o Transform, w float64) Transform {
+	return t.blend(o, w, false)
+}
+
+// Blends towards o by w with slerp on the hemisphere of t, w is clamped to
+// [0, 1] for every channel
+func (t Transform) Slerp(o Transform, w float64) Transform {
+	return t.blend(o, w, true)
+}
+
+func (t Transform) blend(o Transform, w float64, slerp bool) Transform {
+	w = Clamp(w, 0, 1)
+
+	a, b := t.Rotation, o.Rotation
+	if a.Dot(b) < 0 {
+		b.Negate()
+	}
+
+	var r Quaternion
+	if slerp {
+		r, _ = a.SlerpQt(b, w)
+	} else {
+		r = a.ScaleByQt(1 - w).AddQt(b.ScaleByQt(w))
+		if _, err := r.Normalize(); err != nil {
+			r = a
+		}
+	}
+
+	return Transform{
+		Translation: t.Translation.AddVec(o.Translation.SubVec(t.Translation).ScalerMulVec(w)),
+		Rotation:    r,
+		Scale:       t.Scale.AddVec(o.Scale.SubVec(t.Scale).ScalerMulVec(w)),
+	}
+}
@@ -0,0 +1,353 @@
+package golem
+
+import "math"
+
+type Triangle2D struct {
+	A, B, C Vec2D
+}
+
+type Triangle3D struct {
+	A, B, C Vec3D
+}
+
+// positive for counter clockwise winding and negative for clockwise
+func (t Triangle2D) SignedArea() float64 {
+	ab := t.B.SubVec(t.A)
+	return ab.Cross2D(t.C.SubVec(t.A)) / 2
+}
+
+func (t Triangle2D) Area() float64 {
+	return math.Abs(t.SignedArea())
+}
+
+func (t Triangle2D) IsCCW() bool {
+	return t.SignedArea() > 0
+}
+
+func (t Triangle2D) Centroid() Vec2D {
+	return Vec2D{
+		X: (t.A.X + t.B.X + t.C.X) / 3,
+		Y: (t.A.Y + t.B.Y + t.C.Y) / 3,
+	}
+}
+
+// returns the weights (u, v, w) of A, B and C such that p = u*A + v*B + w*C
+func (t Triangle2D) Barycentric(p Vec2D) (float64, float64, float64, error) {
+	v0 := t.B.SubVec(t.A)
+	v1 := t.C.SubVec(t.A)
+	v2 := p.SubVec(t.A)
+
+	den := v0.Cross2D(v1)
+	if den == 0 {
+		return 0, 0, 0, ErrDegenerateTriangle
+	}
+
+	v := v2.Cross2D(v1) / den
+	w := v0.Cross2D(v2) / den
+
+	return 1 - v - w, v, w, nil
+}
+
+func (t Triangle2D) FromBarycentric(u, v, w float64) Vec2D {
+	return Vec2D{
+		X: (u * t.A.X) + (v * t.B.X) + (w * t.C.X),
+		Y: (u * t.A.Y) + (v * t.B.Y) + (w * t.C.Y),
+	}
+}
+
+// points on the edges are considered inside
+func (t Triangle2D) ContainsPoint(p Vec2D) bool {
+	u, v, w, err := t.Barycentric(p)
+	if err != nil {
+		return false
+	}
+
+	return u >= 0 && v >= 0 && w >= 0
+}
+
+// Voronoi region method from Ericson's Real-Time Collision Detection
+func (t Triangle2D) ClosestPoint(p Vec2D) Vec2D {
+	ab := t.B.SubVec(t.A)
+	ac := t.C.SubVec(t.A)
+	ap := p.SubVec(t.A)
+
+	d1 := ab.Dot(ap)
+	d2 := ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return t.A
+	}
+
+	bp := p.SubVec(t.B)
+	d3 := ab.Dot(bp)
+	d4 := ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return t.B
+	}
+
+	vc := (d1 * d4) - (d3 * d2)
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return t.A.AddVec(ab.ScalerMulVec(d1 / (d1 - d3)))
+	}
+
+	cp := p.SubVec(t.C)
+	d5 := ab.Dot(cp)
+	d6 := ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return t.C
+	}
+
+	vb := (d5 * d2) - (d1 * d6)
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return t.A.AddVec(ac.ScalerMulVec(d2 / (d2 - d6)))
+	}
+
+	va := (d3 * d6) - (d5 * d4)
+	if va <= 0 && (d4-d3) >= 0 && (d5-d6) >= 0 {
+		bc := t.C.SubVec(t.B)
+		return t.B.AddVec(bc.ScalerMulVec((d4 - d3) / ((d4 - d3) + (d5 - d6))))
+	}
+
+	den := 1 / (va + vb + vc)
+	v := vb * den
+	w := vc * den
+
+	return t.A.AddVec(ab.ScalerMulVec(v)).AddVec(ac.ScalerMulVec(w))
+}
+
+func (t Triangle2D) Circumcenter() (Vec2D, error) {
+	d := 2 * ((t.A.X * (t.B.Y - t.C.Y)) + (t.B.X * (t.C.Y - t.A.Y)) + (t.C.X * (t.A.Y - t.B.Y)))
+	if d == 0 {
+		return Vec2D{}, ErrDegenerateTriangle
+	}
+
+	a := t.A.Dot(t.A)
+	b := t.B.Dot(t.B)
+	c := t.C.Dot(t.C)
+
+	return Vec2D{
+		X: ((a * (t.B.Y - t.C.Y)) + (b * (t.C.Y - t.A.Y)) + (c * (t.A.Y - t.B.Y))) / d,
+		Y: ((a * (t.C.X - t.B.X)) + (b * (t.A.X - t.C.X)) + (c * (t.B.X - t.A.X))) / d,
+	}, nil
+}
+
+func (t Triangle2D) Circumradius() (float64, error) {
+	center, err := t.Circumcenter()
+	if err != nil {
+		return 0, err
+	}
+
+	return center.Dist(t.A), nil
+}
+
+func (t Triangle2D) Incenter() (Vec2D, error) {
+	a := t.B.Dist(t.C)
+	b := t.C.Dist(t.A)
+	c := t.A.Dist(t.B)
+
+	p := a + b + c
+	if p == 0 || t.SignedArea() == 0 {
+		return Vec2D{}, ErrDegenerateTriangle
+	}
+
+	return Vec2D{
+		X: ((a * t.A.X) + (b * t.B.X) + (c * t.C.X)) / p,
+		Y: ((a * t.A.Y) + (b * t.B.Y) + (c * t.C.Y)) / p,
+	}, nil
+}
+
+// Non Normalized normal, its length is twice the area
+func (t Triangle3D) FaceNormal() Vec3D {
+	return t.B.SubVec(t.A).CrossV(t.C.SubVec(t.A))
+}
+
+// Unit normal following the right hand rule for A -> B -> C
+func (t Triangle3D) Normal() (Vec3D, error) {
+	n := t.FaceNormal()
+	if _, err := n.Normalize(); err != nil {
+		return Vec3D{}, ErrDegenerateTriangle
+	}
+
+	return n, nil
+}
+
+func (t Triangle3D) Area() float64 {
+	n := t.FaceNormal()
+	return n.Length() / 2
+}
+
+func (t Triangle3D) Centroid() Vec3D {
+	return Vec3D{
+		X: (t.A.X + t.B.X + t.C.X) / 3,
+		Y: (t.A.Y + t.B.Y + t.C.Y) / 3,
+		Z: (t.A.Z + t.B.Z + t.C.Z) / 3,
+	}
+}
+
+// returns the weights (u, v, w) of A, B and C for p projected onto the plane of the triangle
+func (t Triangle3D) Barycentric(p Vec3D) (float64, float64, float64, error) {
+	v0 := t.B.SubVec(t.A)
+	v1 := t.C.SubVec(t.A)
+	v2 := p.SubVec(t.A)
+
+	d00 := v0.Dot(v0)
+	d01 := v0.Dot(v1)
+	d11 := v1.Dot(v1)
+	d20 := v2.Dot(v0)
+	d21 := v2.Dot(v1)
+
+	den := (d00 * d11) - (d01 * d01)
+	if den == 0 {
+		return 0, 0, 0, ErrDegenerateTriangle
+	}
+
+	v := ((d11 * d20) - (d01 * d21)) / den
+	w := ((d00 * d21) - (d01 * d20)) / den
+
+	return 1 - v - w, v, w, nil
+}
+
+func (t Triangle3D) FromBarycentric(u, v, w float64) Vec3D {
+	return Vec3D{
+		X: (u * t.A.X) + (v * t.B.X) + (w * t.C.X),
+		Y: (u * t.A.Y) + (v * t.B.Y) + (w * t.C.Y),
+		Z: (u * t.A.Z) + (v * t.B.Z) + (w * t.C.Z),
+	}
+}
+
+// checks the projection of p onto the plane of the triangle, edges are inside
+func (t Triangle3D) ContainsPoint(p Vec3D) bool {
+	u, v, w, err := t.Barycentric(p)
+	if err != nil {
+		return false
+	}
+
+	return u >= 0 && v >= 0 && w >= 0
+}
+
+// Voronoi region method from Ericson's Real-Time Collision Detection
+func (t Triangle3D) ClosestPoint(p Vec3D) Vec3D {
+	ab := t.B.SubVec(t.A)
+	ac := t.C.SubVec(t.A)
+	ap := p.SubVec(t.A)
+
+	d1 := ab.Dot(ap)
+	d2 := ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return t.A
+	}
+
+	bp := p.SubVec(t.B)
+	d3 := ab.Dot(bp)
+	d4 := ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return t.B
+	}
+
+	vc := (d1 * d4) - (d3 * d2)
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return t.A.AddVec(ab.ScalerMulVec(d1 / (d1 - d3)))
+	}
+
+	cp := p.SubVec(t.C)
+	d5 := ab.Dot(cp)
+	d6 := ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return t.C
+	}
+
+	vb := (d5 * d2) - (d1 * d6)
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return t.A.AddVec(ac.ScalerMulVec(d2 / (d2 - d6)))
+	}
+
+	va := (d3 * d6) - (d5 * d4)
+	if va <= 0 && (d4-d3) >= 0 && (d5-d6) >= 0 {
+		bc := t.C.SubVec(t.B)
+		return t.B.AddVec(bc.ScalerMulVec((d4 - d3) / ((d4 - d3) + (d5 - d6))))
+	}
+
+	den := 1 / (va + vb + vc)
+	v := vb * den
+	w := vc * den
+
+	return t.A.AddVec(ab.ScalerMulVec(v)).AddVec(ac.ScalerMulVec(w))
+}
+
+func (t Triangle3D) Circumcenter() (Vec3D, error) {
+	a := t.A.SubVec(t.C)
+	b := t.B.SubVec(t.C)
+
+	axb := a.CrossV(b)
+	den := 2 * axb.Dot(axb)
+	if den == 0 {
+		return Vec3D{}, ErrDegenerateTriangle
+	}
+
+	u := b.ScalerMulVec(a.Dot(a)).SubVec(a.ScalerMulVec(b.Dot(b)))
+	off := u.CrossV(axb)
+	off.ScalerDiv(den)
+
+	return t.C.AddVec(off), nil
+}
+
+func (t Triangle3D) Circumradius() (float64, error) {
+	center, err := t.Circumcenter()
+	if err != nil {
+		return 0, err
+	}
+
+	return center.Dist(t.A), nil
+}
+
+func (t Triangle3D) Incenter() (Vec3D, error) {
+	a := t.B.Dist(t.C)
+	b := t.C.Dist(t.A)
+	c := t.A.Dist(t.B)
+
+	p := a + b + c
+	if p == 0 || t.Area() == 0 {
+		return Vec3D{}, ErrDegenerateTriangle
+	}
+
+	return Vec3D{
+		X: ((a * t.A.X) + (b * t.B.X) + (c * t.C.X)) / p,
+		Y: ((a * t.A.Y) + (b * t.B.Y) + (c * t.C.Y)) / p,
+		Z: ((a * t.A.Z) + (b * t.B.Z) + (c * t.C.Z)) / p,
+	}, nil
+}
+
+// Moller-Trumbore, two sided. Returns the ray param t and the barycentric
+// weights u, v of B and C at the hit point
+func (t Triangle3D) IntersectRay(r Ray3D) (float64, float64, float64, bool) {
+	const eps = 1e-12
+
+	e1 := t.B.SubVec(t.A)
+	e2 := t.C.SubVec(t.A)
+
+	p := r.Dir.CrossV(e2)
+	det := e1.Dot(p)
+	if math.Abs(det) < eps {
+		return 0, 0, 0, false
+	}
+
+	invDet := 1 / det
+	s := r.Origin.SubVec(t.A)
+
+	u := s.Dot(p) * invDet
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+
+	q := s.CrossV(e1)
+	v := r.Dir.Dot(q) * invDet
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+
+	dist := e2.Dot(q) * invDet
+	if dist < 0 {
+		return 0, 0, 0, false
+	}
+
+	return dist, u, v, true
+}
@@ -0,0 +1,221 @@
+package golem
+
+import "math"
+
+// Plays eased progress from 0 to 1 over Duration seconds after Delay and
+// hands it to apply. Loops is the number of repeats after the first play
+// and -1 repeats forever, Yoyo plays every other repeat backwards
+type Tween struct {
+	Duration float64
+	Delay    float64
+	Ease     EasingFunc
+	Loops    int
+	Yoyo     bool
+
+	// eased progress after every update, loop index after every repeat
+	OnUpdate   func(t float64)
+	OnLoop     func(loop int)
+	OnComplete func()
+
+	apply   func(t float64)
+	elapsed float64
+	loop    int
+	done    bool
+}
+
+// A nil ease is Linear
+func NewTween(duration float64, ease EasingFunc, apply func(t float64)) (*Tween, error) {
+	if duration < 0 || math.IsNaN(duration) {
+		return nil, ErrInvalidInterPolParam
+	}
+	if ease == nil {
+		ease = Linear
+	}
+
+	return &Tween{Duration: duration, Ease: ease, apply: apply}, nil
+}
+
+func TweenFloat(target *float64, from, to, duration float64, ease EasingFunc) (*Tween, error) {
+	return NewTween(duration, ease, func(t float64) {
+		*target = from + (to-from)*t
+	})
+}
+
+func TweenVec2D(target *Vec2D, from, to Vec2D, duration float64, ease EasingFunc) (*Tween, error) {
+	return NewTween(duration, ease, func(t float64) {
+		*target = from.AddVec(to.SubVec(from).ScalerMulVec(t))
+	})
+}
+
+func TweenVec3D(target *Vec3D, from, to Vec3D, duration float64, ease EasingFunc) (*Tween, error) {
+	return NewTween(duration, ease, func(t float64) {
+		*target = from.AddVec(to.SubVec(from).ScalerMulVec(t))
+	})
+}
+
+// Slerps along the short arc. SlerpQt has no overshoot so the eased
+// progress is clamped to [0, 1]
+func TweenQuaternion(target *Quaternion, from, to Quaternion, duration float64, ease EasingFunc) (*Tween, error) {
+	return NewTween(duration, ease, func(t float64) {
+		if q, err := from.SlerpQt(to, Clamp(t, 0, 1)); err == nil {
+			*target = q
+		}
+	})
+}
+
+// turns through the smaller angle between the rotations
+func TweenRotMat2D(target *RotMat2D, from, to RotMat2D, duration float64, ease EasingFunc) (*Tween, error) {
+	a := from.EulerAngle()
+	d := NormalizeAngle(to.EulerAngle() - a)
+
+	return NewTween(duration, ease, func(t float64) {
+		target.Set(NormalizeAngle(a + d*t))
+	})
+}
+
+// Advances by dt seconds, returns false once the tween has completed
+func (tw *Tween) Update(dt float64) bool {
+	if tw.done {
+		return false
+	}
+
+	tw.elapsed += dt
+	active := tw.elapsed - tw.Delay
+	if active < 0 {
+		return true
+	}
+
+	loop := max(tw.Loops, 0)
+	if tw.Duration > 0 {
+		loop = int(active / tw.Duration)
+	}
+
+	tw.advance(loop, active, tw.Duration <= 0 || tw.Loops >= 0 && loop > tw.Loops)
+	return !tw.done
+}
+
+// Jumps to the end of the last play and completes, does nothing for
+// tweens that repeat forever
+func (tw *Tween) Finish() {
+	if tw.done || tw.Loops < 0 {
+		return
+	}
+
+	tw.elapsed = tw.Delay + float64(tw.Loops+1)*tw.Duration
+	tw.advance(tw.Loops, 0, true)
+}
+
+func (tw *Tween) advance(loop int, active float64, done bool) {
+	if done {
+		loop = max(tw.Loops, 0)
+	}
+
+	for tw.loop < loop {
+		tw.loop++
+		if tw.OnLoop != nil {
+			tw.OnLoop(tw.loop)
+		}
+	}
+
+	p := 1.0
+	if !done {
+		p = (active - float64(loop)*tw.Duration) / tw.Duration
+	}
+	if tw.Yoyo && loop%2 == 1 {
+		p = 1 - p
+	}
+
+	tw.done = done
+	tw.set(p)
+
+	if done && tw.OnComplete != nil {
+		tw.OnComplete()
+	}
+}
+
+// rewinds to the start of the delay without applying anything
+func (tw *Tween) Reset() {
+	tw.elapsed = 0
+	tw.loop = 0
+	tw.done = false
+}
+
+func (tw *Tween) Done() bool {
+	return tw.done
+}
+
+func (tw *Tween) Elapsed() float64 {
+	return tw.elapsed
+}
+
+func (tw *Tween) set(p float64) {
+	t := tw.Ease(p)
+
+	if tw.apply != nil {
+		tw.apply(t)
+	}
+	if tw.OnUpdate != nil {
+		tw.OnUpdate(t)
+	}
+}
+
+// Updates tweens in the order they were added and drops completed ones
+type TweenManager struct {
+	tweens []*Tween
+
+	// tweens not yet visited by the running update
+	pending []*Tween
+}
+
+func (tm *TweenManager) Add(tw *Tween) {
+	tm.tweens = append(tm.tweens, tw)
+}
+
+// returns whether tw was running
+func (tm *TweenManager) Remove(tw *Tween) bool {
+	for i, t := range tm.tweens {
+		if t == tw {
+			tm.tweens = append(tm.tweens[:i], tm.tweens[i+1:]...)
+			return true
+		}
+	}
+
+	for i, t := range tm.pending {
+		if t == tw {
+			tm.pending[i] = nil
+			return true
+		}
+	}
+
+	return false
+}
+
+func (tm *TweenManager) Len() int {
+	return len(tm.tweens)
+}
+
+func (tm *TweenManager) Clear() {
+	tm.tweens = tm.tweens[:0]
+	clear(tm.pending)
+}
+
+// Tweens added by callbacks during the update start on the next one
+func (tm *TweenManager) Update(dt float64) {
+	tm.pending = tm.tweens
+	tm.tweens = make([]*Tween, 0, len(tm.pending))
+
+	for i := range tm.pending {
+		tw := tm.pending[i]
+		if tw == nil {
+			continue
+		}
+
+		// callbacks may remove the tween while it updates
+		if tw.Update(dt) && tm.pending[i] != nil {
+			tm.tweens = append(tm.tweens, tw)
+		}
+		tm.pending[i] = nil
+	}
+
+	tm.pending = nil
+}
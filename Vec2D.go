@@ -43,6 +43,11 @@ func (v *Vec2D) ScalerMul(x float64) {
 	v.Y *= x
 }
 
+func (v Vec2D) ScalerMulVec(x float64) Vec2D {
+	v.ScalerMul(x)
+	return v
+}
+
 func (v *Vec2D) ScalerDiv(x float64) {
 	if x == 0 {
 		return
@@ -131,9 +136,9 @@ func (v Vec2D) RotateOf(theta, x, y float64) Vec2D {
 
 func (v Vec2D) Projection(vec Vec2D) Vec2D {
 	p := v.Dot(vec) / (math.Pow(vec.Length(), 2))
-	v.ScalerMul(p)
+	vec.ScalerMul(p)
 
-	return v
+	return vec
 }
 
 func (v Vec2D) Reflection(Nvec Vec2D) Vec2D {
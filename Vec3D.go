@@ -50,6 +50,11 @@ func (v *Vec3D) ScalerMul(x float64) {
 	v.Z *= x
 }
 
+func (v Vec3D) ScalerMulVec(x float64) Vec3D {
+	v.ScalerMul(x)
+	return v
+}
+
 func (v *Vec3D) ScalerDiv(x float64) {
 	if x == 0 {
 		return
@@ -123,9 +128,9 @@ func (v Vec3D) CrossV(vec Vec3D) Vec3D {
 
 func (v Vec3D) ProjectionOnto(vec Vec3D) Vec3D {
 	p := v.Dot(vec) / (math.Pow(vec.Length(), 2))
-	v.ScalerMul(p)
+	vec.ScalerMul(p)
 
-	return v
+	return vec
 }
 
 func (v Vec3D) Reflection(Nvec Vec3D) Vec3D {
@@ -0,0 +1,617 @@
+package golem
+
+import "math"
+
+// Position based dynamics on Verlet particles. Each substep moves the
+// particles by their implied velocity and the forces, then projects the
+// constraints and pushes particles out of the colliders a few times
+
+// Velocity is implied by the step from Previous to Position, a particle
+// with InvMass 0 is pinned
+type Particle3D struct {
+	Position Vec3D
+	Previous Vec3D
+	InvMass  float64
+}
+
+type Particle2D struct {
+	Position Vec2D
+	Previous Vec2D
+	InvMass  float64
+}
+
+// Moves particles to satisfy a constraint. Stiffness below 1 only closes
+// part of the error per projection, so it depends on the iteration count
+type ParticleConstraint3D interface {
+	Project(p []Particle3D)
+}
+
+type ParticleConstraint2D interface {
+	Project(p []Particle2D)
+}
+
+// Solid the particles stay out of, returns the position pushed out by
+// radius and the surface normal there
+type ParticleCollider3D interface {
+	PushOut(p Vec3D, radius float64) (Vec3D, Vec3D, bool)
+}
+
+type ParticleCollider2D interface {
+	PushOut(p Vec2D, radius float64) (Vec2D, Vec2D, bool)
+}
+
+type ParticleSystem3D struct {
+	Particles   []Particle3D
+	Constraints []ParticleConstraint3D
+	Colliders   []ParticleCollider3D
+
+	Gravity Vec3D
+	// velocity of the air, Drag pulls particles towards moving with it
+	Wind Vec3D
+	Drag float64
+	// rate the velocities decay at, per second
+	Damping float64
+
+	// collision radius of every particle, Friction from 0 to 1 is the part
+	// of the sliding motion lost on a collider each substep
+	Radius   float64
+	Friction float64
+
+	Substeps   int
+	Iterations int
+
+	// length of the last substep
+	h float64
+}
+
+type ParticleSystem2D struct {
+	Particles   []Particle2D
+	Constraints []ParticleConstraint2D
+	Colliders   []ParticleCollider2D
+
+	Gravity Vec2D
+	Wind    Vec2D
+	Drag    float64
+	Damping float64
+
+	Radius   float64
+	Friction float64
+
+	Substeps   int
+	Iterations int
+
+	// length of the last substep
+	h float64
+}
+
+func NewParticleSystem3D(gravity Vec3D) *ParticleSystem3D {
+	return &ParticleSystem3D{Gravity: gravity, Substeps: 4, Iterations: 4}
+}
+
+func NewParticleSystem2D(gravity Vec2D) *ParticleSystem2D {
+	return &ParticleSystem2D{Gravity: gravity, Substeps: 4, Iterations: 4}
+}
+
+// returns the index of the particle, a mass of 0 pins it
+func (s *ParticleSystem3D) AddParticle(pos Vec3D, mass float64) int {
+	inv := 0.0
+	if mass > 0 {
+		inv = 1 / mass
+	}
+
+	s.Particles = append(s.Particles, Particle3D{Position: pos, Previous: pos, InvMass: inv})
+	return len(s.Particles) - 1
+}
+
+func (s *ParticleSystem2D) AddParticle(pos Vec2D, mass float64) int {
+	inv := 0.0
+	if mass > 0 {
+		inv = 1 / mass
+	}
+
+	s.Particles = append(s.Particles, Particle2D{Position: pos, Previous: pos, InvMass: inv})
+	return len(s.Particles) - 1
+}
+
+// holds particle i still at pos
+func (s *ParticleSystem3D) Pin(i int, pos Vec3D) {
+	s.Particles[i] = Particle3D{Position: pos, Previous: pos}
+}
+
+func (s *ParticleSystem2D) Pin(i int, pos Vec2D) {
+	s.Particles[i] = Particle2D{Position: pos, Previous: pos}
+}
+
+// frees particle i with the mass, at rest
+func (s *ParticleSystem3D) Unpin(i int, mass float64) error {
+	if mass <= 0 {
+		return ErrInvalidMass
+	}
+
+	s.Particles[i].Previous = s.Particles[i].Position
+	s.Particles[i].InvMass = 1 / mass
+	return nil
+}
+
+func (s *ParticleSystem2D) Unpin(i int, mass float64) error {
+	if mass <= 0 {
+		return ErrInvalidMass
+	}
+
+	s.Particles[i].Previous = s.Particles[i].Position
+	s.Particles[i].InvMass = 1 / mass
+	return nil
+}
+
+// velocity of particle i over the last substep
+func (s *ParticleSystem3D) Velocity(i int) Vec3D {
+	if s.h == 0 {
+		return Vec3D{}
+	}
+
+	p := s.Particles[i]
+	return p.Position.SubVec(p.Previous).ScalerMulVec(1 / s.h)
+}
+
+func (s *ParticleSystem2D) Velocity(i int) Vec2D {
+	if s.h == 0 {
+		return Vec2D{}
+	}
+
+	p := s.Particles[i]
+	return p.Position.SubVec(p.Previous).ScalerMulVec(1 / s.h)
+}
+
+func (s *ParticleSystem3D) Step(dt float64) {
+	if dt <= 0 {
+		return
+	}
+
+	n := max(s.Substeps, 1)
+	h := dt / float64(n)
+	s.h = h
+
+	for sub := 0; sub < n; sub++ {
+		s.integrate(h)
+		for it := 0; it < max(s.Iterations, 1); it++ {
+			for _, c := range s.Constraints {
+				c.Project(s.Particles)
+			}
+			s.collide(it == max(s.Iterations, 1)-1)
+		}
+	}
+}
+
+func (s *ParticleSystem2D) Step(dt float64) {
+	if dt <= 0 {
+		return
+	}
+
+	n := max(s.Substeps, 1)
+	h := dt / float64(n)
+	s.h = h
+
+	for sub := 0; sub < n; sub++ {
+		s.integrate(h)
+		for it := 0; it < max(s.Iterations, 1); it++ {
+			for _, c := range s.Constraints {
+				c.Project(s.Particles)
+			}
+			s.collide(it == max(s.Iterations, 1)-1)
+		}
+	}
+}
+
+func (s *ParticleSystem3D) integrate(h float64) {
+	keep := math.Exp(-s.Damping * h)
+
+	for i := range s.Particles {
+		p := &s.Particles[i]
+		if p.InvMass == 0 {
+			p.Previous = p.Position
+			continue
+		}
+
+		step := p.Position.SubVec(p.Previous).ScalerMulVec(keep)
+		acc := s.Gravity
+		if s.Drag != 0 {
+			acc.Add(s.Wind.SubVec(step.ScalerMulVec(1 / h)).ScalerMulVec(s.Drag))
+		}
+
+		p.Previous = p.Position
+		p.Position.Add(step.AddVec(acc.ScalerMulVec(h * h)))
+	}
+}
+
+func (s *ParticleSystem2D) integrate(h float64) {
+	keep := math.Exp(-s.Damping * h)
+
+	for i := range s.Particles {
+		p := &s.Particles[i]
+		if p.InvMass == 0 {
+			p.Previous = p.Position
+			continue
+		}
+
+		step := p.Position.SubVec(p.Previous).ScalerMulVec(keep)
+		acc := s.Gravity
+		if s.Drag != 0 {
+			acc.Add(s.Wind.SubVec(step.ScalerMulVec(1 / h)).ScalerMulVec(s.Drag))
+		}
+
+		p.Previous = p.Position
+		p.Position.Add(step.AddVec(acc.ScalerMulVec(h * h)))
+	}
+}
+
+// pushes particles out of the colliders, friction is applied once per
+// substep on the last pass
+func (s *ParticleSystem3D) collide(friction bool) {
+	for i := range s.Particles {
+		p := &s.Particles[i]
+		if p.InvMass == 0 {
+			continue
+		}
+
+		for _, c := range s.Colliders {
+			pos, n, hit := c.PushOut(p.Position, s.Radius)
+			if !hit {
+				continue
+			}
+			p.Position = pos
+
+			if friction && s.Friction > 0 {
+				d := p.Position.SubVec(p.Previous)
+				slide := d.SubVec(n.ScalerMulVec(n.Dot(d)))
+				p.Position.Sub(slide.ScalerMulVec(Clamp(s.Friction, 0, 1)))
+			}
+		}
+	}
+}
+
+func (s *ParticleSystem2D) collide(friction bool) {
+	for i := range s.Particles {
+		p := &s.Particles[i]
+		if p.InvMass == 0 {
+			continue
+		}
+
+		for _, c := range s.Colliders {
+			pos, n, hit := c.PushOut(p.Position, s.Radius)
+			if !hit {
+				continue
+			}
+			p.Position = pos
+
+			if friction && s.Friction > 0 {
+				d := p.Position.SubVec(p.Previous)
+				slide := d.SubVec(n.ScalerMulVec(n.Dot(d)))
+				p.Position.Sub(slide.ScalerMulVec(Clamp(s.Friction, 0, 1)))
+			}
+		}
+	}
+}
+
+// Keeps particles A and B Length apart
+type DistanceConstraint3D struct {
+	A, B      int
+	Length    float64
+	Stiffness float64
+}
+
+type DistanceConstraint2D struct {
+	A, B      int
+	Length    float64
+	Stiffness float64
+}
+
+// rest length is the current distance
+func NewDistanceConstraint3D(p []Particle3D, a, b int, stiffness float64) *DistanceConstraint3D {
+	return &DistanceConstraint3D{A: a, B: b, Length: p[a].Position.Dist(p[b].Position), Stiffness: stiffness}
+}
+
+func NewDistanceConstraint2D(p []Particle2D, a, b int, stiffness float64) *DistanceConstraint2D {
+	return &DistanceConstraint2D{A: a, B: b, Length: p[a].Position.Dist(p[b].Position), Stiffness: stiffness}
+}
+
+func (c *DistanceConstraint3D) Project(p []Particle3D) {
+	projectDistance3D(&p[c.A], &p[c.B], c.Length, c.Stiffness)
+}
+
+func (c *DistanceConstraint2D) Project(p []Particle2D) {
+	projectDistance2D(&p[c.A], &p[c.B], c.Length, c.Stiffness)
+}
+
+func projectDistance3D(a, b *Particle3D, length, stiffness float64) {
+	w := a.InvMass + b.InvMass
+	if w == 0 {
+		return
+	}
+
+	d := b.Position.SubVec(a.Position)
+	l, err := d.Normalize()
+	if err != nil {
+		return
+	}
+
+	d.ScalerMul((l - length) / w * stiffness)
+	a.Position.Add(d.ScalerMulVec(a.InvMass))
+	b.Position.Sub(d.ScalerMulVec(b.InvMass))
+}
+
+func projectDistance2D(a, b *Particle2D, length, stiffness float64) {
+	w := a.InvMass + b.InvMass
+	if w == 0 {
+		return
+	}
+
+	d := b.Position.SubVec(a.Position)
+	l, err := d.Normalize()
+	if err != nil {
+		return
+	}
+
+	d.ScalerMul((l - length) / w * stiffness)
+	a.Position.Add(d.ScalerMulVec(a.InvMass))
+	b.Position.Sub(d.ScalerMulVec(b.InvMass))
+}
+
+// Keeps the angle at B between the edges to A and C, by holding A and C
+// at the distance the law of cosines gives for the current edge lengths
+type BendingConstraint3D struct {
+	A, B, C   int
+	Angle     float64
+	Stiffness float64
+}
+
+type BendingConstraint2D struct {
+	A, B, C   int
+	Angle     float64
+	Stiffness float64
+}
+
+// rest angle is the current angle
+func NewBendingConstraint3D(p []Particle3D, a, b, c int, stiffness float64) *BendingConstraint3D {
+	ba := p[a].Position.SubVec(p[b].Position)
+	bc := p[c].Position.SubVec(p[b].Position)
+	angle, _ := ba.AngleBetween(bc)
+
+	return &BendingConstraint3D{A: a, B: b, C: c, Angle: angle, Stiffness: stiffness}
+}
+
+func NewBendingConstraint2D(p []Particle2D, a, b, c int, stiffness float64) *BendingConstraint2D {
+	ba := p[a].Position.SubVec(p[b].Position)
+	bc := p[c].Position.SubVec(p[b].Position)
+	angle := math.Acos(Clamp(ba.CosAngleBetween(bc), -1, 1))
+
+	return &BendingConstraint2D{A: a, B: b, C: c, Angle: angle, Stiffness: stiffness}
+}
+
+func (c *BendingConstraint3D) Project(p []Particle3D) {
+	ab, cb := p[c.B].Position.Dist(p[c.A].Position), p[c.B].Position.Dist(p[c.C].Position)
+	projectDistance3D(&p[c.A], &p[c.C], cosineSide(ab, cb, c.Angle), c.Stiffness)
+}
+
+func (c *BendingConstraint2D) Project(p []Particle2D) {
+	ab, cb := p[c.B].Position.Dist(p[c.A].Position), p[c.B].Position.Dist(p[c.C].Position)
+	projectDistance2D(&p[c.A], &p[c.C], cosineSide(ab, cb, c.Angle), c.Stiffness)
+}
+
+// side opposite the angle between sides a and b
+func cosineSide(a, b, angle float64) float64 {
+	return math.Sqrt(math.Max(a*a+b*b-2*a*b*math.Cos(angle), 0))
+}
+
+// Keeps the volume of a closed mesh of particles, faces wound ccw seen
+// from outside. Pressure scales the rest volume to inflate or deflate it
+type VolumeConstraint3D struct {
+	Faces     [][3]int
+	Volume    float64
+	Pressure  float64
+	Stiffness float64
+
+	verts []int
+	slot  map[int]int
+	grad  []Vec3D
+}
+
+// Keeps the area of a ccw ring of particles, Pressure scales the rest area
+type AreaConstraint2D struct {
+	Ring      []int
+	Area      float64
+	Pressure  float64
+	Stiffness float64
+
+	grad []Vec2D
+}
+
+func NewVolumeConstraint3D(p []Particle3D, faces [][3]int, stiffness float64) *VolumeConstraint3D {
+	c := &VolumeConstraint3D{Faces: faces, Pressure: 1, Stiffness: stiffness}
+	c.Volume = c.volume(p)
+
+	return c
+}
+
+func NewAreaConstraint2D(p []Particle2D, ring []int, stiffness float64) *AreaConstraint2D {
+	c := &AreaConstraint2D{Ring: ring, Pressure: 1, Stiffness: stiffness}
+	c.Area = c.area(p)
+
+	return c
+}
+
+func (c *VolumeConstraint3D) volume(p []Particle3D) float64 {
+	v := 0.0
+	for _, f := range c.Faces {
+		a := p[f[0]].Position
+		v += a.Dot(p[f[1]].Position.CrossV(p[f[2]].Position))
+	}
+
+	return v / 6
+}
+
+func (c *AreaConstraint2D) area(p []Particle2D) float64 {
+	a := 0.0
+	for i, k := range c.Ring {
+		q := p[k].Position
+		a += q.Cross2D(p[c.Ring[(i+1)%len(c.Ring)]].Position)
+	}
+
+	return a / 2
+}
+
+func (c *VolumeConstraint3D) Project(p []Particle3D) {
+	if c.slot == nil {
+		c.index()
+	}
+
+	// gradient of the volume for every particle
+	for i := range c.grad {
+		c.grad[i] = Vec3D{}
+	}
+	for _, f := range c.Faces {
+		for k := 0; k < 3; k++ {
+			g := p[f[(k+1)%3]].Position.CrossV(p[f[(k+2)%3]].Position)
+			c.grad[c.slot[f[k]]].Add(g.ScalerMulVec(1.0 / 6))
+		}
+	}
+
+	w := 0.0
+	for k, i := range c.verts {
+		w += p[i].InvMass * c.grad[k].Dot(c.grad[k])
+	}
+	if w == 0 {
+		return
+	}
+
+	lambda := -(c.volume(p) - c.Pressure*c.Volume) / w * c.Stiffness
+	for k, i := range c.verts {
+		p[i].Position.Add(c.grad[k].ScalerMulVec(lambda * p[i].InvMass))
+	}
+}
+
+// particles of the faces in order of first use
+func (c *VolumeConstraint3D) index() {
+	c.slot = make(map[int]int)
+	c.verts = c.verts[:0]
+
+	for _, f := range c.Faces {
+		for _, i := range f {
+			if _, ok := c.slot[i]; !ok {
+				c.slot[i] = len(c.verts)
+				c.verts = append(c.verts, i)
+			}
+		}
+	}
+	c.grad = make([]Vec3D, len(c.verts))
+}
+
+func (c *AreaConstraint2D) Project(p []Particle2D) {
+	n := len(c.Ring)
+	if len(c.grad) != n {
+		c.grad = make([]Vec2D, n)
+	}
+	grad := c.grad
+
+	w := 0.0
+	for i, k := range c.Ring {
+		prev, next := p[c.Ring[(i+n-1)%n]].Position, p[c.Ring[(i+1)%n]].Position
+		grad[i] = Vec2D{X: (next.Y - prev.Y) / 2, Y: (prev.X - next.X) / 2}
+		w += p[k].InvMass * grad[i].Dot(grad[i])
+	}
+	if w == 0 {
+		return
+	}
+
+	lambda := -(c.area(p) - c.Pressure*c.Area) / w * c.Stiffness
+	for i, k := range c.Ring {
+		p[k].Position.Add(grad[i].ScalerMulVec(lambda * p[k].InvMass))
+	}
+}
+
+// Colliders
+
+func (pl Plane) PushOut(p Vec3D, radius float64) (Vec3D, Vec3D, bool) {
+	d := pl.SignedDistance(p)
+	if d >= radius {
+		return p, Vec3D{}, false
+	}
+
+	return p.AddVec(pl.Normal.ScalerMulVec(radius - d)), pl.Normal, true
+}
+
+func (s Sphere) PushOut(p Vec3D, radius float64) (Vec3D, Vec3D, bool) {
+	n := p.SubVec(s.Center)
+	d, err := n.Normalize()
+	if err != nil {
+		n, d = Vec3D{Y: 1}, 0
+	}
+	if d >= s.Radius+radius {
+		return p, Vec3D{}, false
+	}
+
+	return s.Center.AddVec(n.ScalerMulVec(s.Radius + radius)), n, true
+}
+
+func (c Circle) PushOut(p Vec2D, radius float64) (Vec2D, Vec2D, bool) {
+	n := p.SubVec(c.Center)
+	d, err := n.Normalize()
+	if err != nil {
+		n, d = Vec2D{Y: 1}, 0
+	}
+	if d >= c.Radius+radius {
+		return p, Vec2D{}, false
+	}
+
+	return c.Center.AddVec(n.ScalerMulVec(c.Radius + radius)), n, true
+}
+
+func (b AABB3D) PushOut(p Vec3D, radius float64) (Vec3D, Vec3D, bool) {
+	q := b.ClosestPoint(p)
+	if q != p {
+		n := p.SubVec(q)
+		d, _ := n.Normalize()
+		if d >= radius {
+			return p, Vec3D{}, false
+		}
+
+		return q.AddVec(n.ScalerMulVec(radius)), n, true
+	}
+
+	// inside, leave through the nearest face
+	faces := [6]float64{p.X - b.Min.X, b.Max.X - p.X, p.Y - b.Min.Y, b.Max.Y - p.Y, p.Z - b.Min.Z, b.Max.Z - p.Z}
+	normals := [6]Vec3D{{X: -1}, {X: 1}, {Y: -1}, {Y: 1}, {Z: -1}, {Z: 1}}
+
+	best := 0
+	for i := range faces {
+		if faces[i] < faces[best] {
+			best = i
+		}
+	}
+
+	return p.AddVec(normals[best].ScalerMulVec(faces[best] + radius)), normals[best], true
+}
+
+func (b AABB2D) PushOut(p Vec2D, radius float64) (Vec2D, Vec2D, bool) {
+	q := b.ClosestPoint(p)
+	if q != p {
+		n := p.SubVec(q)
+		d, _ := n.Normalize()
+		if d >= radius {
+			return p, Vec2D{}, false
+		}
+
+		return q.AddVec(n.ScalerMulVec(radius)), n, true
+	}
+
+	faces := [4]float64{p.X - b.Min.X, b.Max.X - p.X, p.Y - b.Min.Y, b.Max.Y - p.Y}
+	normals := [4]Vec2D{{X: -1}, {X: 1}, {Y: -1}, {Y: 1}}
+
+	best := 0
+	for i := range faces {
+		if faces[i] < faces[best] {
+			best = i
+		}
+	}
+
+	return p.AddVec(normals[best].ScalerMulVec(faces[best] + radius)), normals[best], true
+}
@@ -0,0 +1,119 @@
+package golem
+
+import "sort"
+
+// Cell of a site clipped to the bounding rectangle. Polygon is ccw and
+// Neighbors holds the sites sharing an edge with this cell inside the bounds
+type VoronoiCell struct {
+	Site      int
+	Polygon   []Vec2D
+	Neighbors []int
+}
+
+// Each cell is the bounding rectangle clipped by the bisectors between the
+// site and its Delaunay neighbors. Duplicate sites and sites whose cell lies
+// fully outside the bounds get an empty cell
+func NewVoronoi(points []Vec2D, min, max Vec2D) ([]VoronoiCell, error) {
+	if min.X >= max.X || min.Y >= max.Y {
+		return nil, ErrInvalidBounds
+	}
+
+	candidates := make([][]int, len(points))
+
+	d, err := NewDelaunay(points)
+	if err == nil {
+		candidates = d.VertexNeighbors()
+	} else {
+		// collinear or too few sites, every other site is a candidate
+		for i := range points {
+			for j := range points {
+				if i != j && points[i].IsNotEqual(points[j]) {
+					candidates[i] = append(candidates[i], j)
+				}
+			}
+		}
+	}
+
+	first := make(map[Vec2D]int, len(points))
+	cells := make([]VoronoiCell, len(points))
+
+	for i, p := range points {
+		cells[i].Site = i
+
+		if _, ok := first[p]; ok {
+			continue
+		}
+		first[p] = i
+
+		poly := []Vec2D{
+			{X: min.X, Y: min.Y},
+			{X: max.X, Y: min.Y},
+			{X: max.X, Y: max.Y},
+			{X: min.X, Y: max.Y},
+		}
+		labels := []int{-1, -1, -1, -1}
+
+		for _, j := range candidates[i] {
+			q := points[j]
+
+			nrm := q.SubVec(p)
+			mid := Vec2D{X: (p.X + q.X) / 2, Y: (p.Y + q.Y) / 2}
+
+			poly, labels = clipVoronoiCell(poly, labels, nrm, nrm.Dot(mid), j)
+			if len(poly) == 0 {
+				break
+			}
+		}
+
+		if len(poly) < 3 {
+			continue
+		}
+
+		cells[i].Polygon = poly
+
+		for k, l := range labels {
+			if l == -1 || poly[k].Dist(poly[(k+1)%len(poly)]) == 0 {
+				continue
+			}
+			cells[i].Neighbors = append(cells[i].Neighbors, l)
+		}
+
+		sort.Ints(cells[i].Neighbors)
+	}
+
+	return cells, nil
+}
+
+// keeps the part of the polygon where nrm.x <= off, labels[k] names the
+// site across the edge from poly[k] to poly[k+1] or -1 for the bounds
+func clipVoronoiCell(poly []Vec2D, labels []int, nrm Vec2D, off float64, label int) ([]Vec2D, []int) {
+	n := len(poly)
+	outPoly := make([]Vec2D, 0, n+1)
+	outLabels := make([]int, 0, n+1)
+
+	for k := 0; k < n; k++ {
+		cur := poly[k]
+		next := poly[(k+1)%n]
+
+		dc := nrm.Dot(cur) - off
+		dn := nrm.Dot(next) - off
+
+		if dc <= 0 {
+			outPoly = append(outPoly, cur)
+			outLabels = append(outLabels, labels[k])
+
+			if dn > 0 && dc < 0 {
+				// leaving, the new edge runs along the bisector
+				outPoly = append(outPoly, lineIntersect2D(cur, next, dc, dn))
+				outLabels = append(outLabels, label)
+			} else if dn > 0 {
+				outLabels[len(outLabels)-1] = label
+			}
+		} else if dn < 0 {
+			outPoly = append(outPoly, lineIntersect2D(cur, next, dc, dn))
+			outLabels = append(outLabels, labels[k])
+		}
+	}
+
+	return outPoly, outLabels
+}
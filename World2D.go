@@ -0,0 +1,537 @@
+package golem
+
+import "math"
+
+type BodyType2D int
+
+const (
+	BodyStatic BodyType2D = iota
+	BodyDynamic
+	// moved only by its velocity and pushes dynamic bodies like a wall
+	BodyKinematic
+)
+
+// Body of a World2D. Position is the center of mass and the origin of the
+// shape, the body turns about it
+type Body2D struct {
+	Type  BodyType2D
+	Shape Shape2D
+
+	Position        Vec2D
+	Angle           float64
+	LinearVelocity  Vec2D
+	AngularVelocity float64
+
+	LinearDamping  float64
+	AngularDamping float64
+
+	// contacts use the geometric mean of the frictions and the larger
+	// restitution of the two bodies
+	Friction    float64
+	Restitution float64
+
+	// two bodies collide when each has the Category of the other in its Mask
+	Category uint32
+	Mask     uint32
+
+	mass, invMass       float64
+	inertia, invInertia float64
+
+	force  Vec2D
+	torque float64
+
+	sleeping  bool
+	sleepTime float64
+
+	id    int
+	world *World2D
+}
+
+// Bodies touching in the last step, Normal points from A to B
+type Contact2D struct {
+	A, B *Body2D
+	Manifold2D
+}
+
+type RaycastHit2D struct {
+	Body     *Body2D
+	Point    Vec2D
+	Normal   Vec2D
+	Distance float64
+}
+
+// Rigid body simulation in the plane with a sweep and prune broadphase,
+// SAT contact manifolds and a sequential impulse solver. Pairs are handled
+// in order of the bodies so a replay reproduces a run exactly
+type World2D struct {
+	Gravity    Vec2D
+	Iterations int
+
+	// fraction of the penetration removed per step
+	Baumgarte float64
+	// penetration left alone to keep resting contacts from jittering
+	Slop float64
+	// approach speed below which contacts do not bounce
+	RestitutionThreshold float64
+	// reuse the impulses of the last step as a starting guess
+	WarmStarting bool
+
+	// a group of touching bodies slower than SleepLinear and SleepAngular
+	// for SleepTime seconds is put to sleep until something wakes it
+	AllowSleep   bool
+	SleepLinear  float64
+	SleepAngular float64
+	SleepTime    float64
+
+	bodies   []*Body2D
+	byID     map[int]*Body2D
+	nextID   int
+	broad    *SweepAndPrune2D
+	touching []Contact2D
+	points   []contactPoint2D
+	cache    map[contactKey2D]contactImpulse2D
+}
+
+type contactKey2D struct {
+	a, b *Body2D
+	id   int
+}
+
+type contactImpulse2D struct {
+	normal, tangent float64
+}
+
+type contactPoint2D struct {
+	a, b     *Body2D
+	normal   Vec2D
+	ra, rb   Vec2D
+	id       int
+	friction float64
+
+	normalMass, tangentMass float64
+	normalImpulse           float64
+	tangentImpulse          float64
+	bias                    float64
+}
+
+// Mass and inertia come from the shape and density, static and kinematic
+// bodies take neither
+func NewBody2D(kind BodyType2D, shape Shape2D, density float64) (*Body2D, error) {
+	b := &Body2D{
+		Type:     kind,
+		Shape:    shape,
+		Friction: 0.5,
+		Category: 1,
+		Mask:     math.MaxUint32,
+	}
+
+	if kind != BodyDynamic {
+		return b, nil
+	}
+
+	mass, inertia := shape.MassData(density)
+	if density <= 0 || mass <= 0 {
+		return nil, ErrInvalidMass
+	}
+
+	b.mass, b.invMass = mass, 1/mass
+	if inertia > 0 {
+		b.inertia, b.invInertia = inertia, 1/inertia
+	}
+
+	return b, nil
+}
+
+func (b *Body2D) Mass() float64 {
+	return b.mass
+}
+
+func (b *Body2D) InvMass() float64 {
+	return b.invMass
+}
+
+func (b *Body2D) Inertia() float64 {
+	return b.inertia
+}
+
+func (b *Body2D) Rotation() RotMat2D {
+	r := RotMat2D{}
+	r.Set(b.Angle)
+	return r
+}
+
+func (b *Body2D) Bounds() AABB2D {
+	return b.Shape.Bounds(b.Position, b.Rotation())
+}
+
+func (b *Body2D) ToWorld(local Vec2D) Vec2D {
+	return b.Position.AddVec(b.Rotation().RotateVec2D(local))
+}
+
+func (b *Body2D) VelocityAt(p Vec2D) Vec2D {
+	r := p.SubVec(b.Position)
+	return b.LinearVelocity.AddVec(r.LeftPerpendicular().ScalerMulVec(b.AngularVelocity))
+}
+
+func (b *Body2D) IsSleeping() bool {
+	return b.sleeping
+}
+
+// needed after moving a sleeping body by hand
+func (b *Body2D) Wake() {
+	b.sleeping = false
+	b.sleepTime = 0
+}
+
+func (b *Body2D) ApplyForce(f Vec2D) {
+	b.force.Add(f)
+	b.Wake()
+}
+
+func (b *Body2D) ApplyTorque(t float64) {
+	b.torque += t
+	b.Wake()
+}
+
+func (b *Body2D) ApplyForceAtPoint(f, p Vec2D) {
+	r := p.SubVec(b.Position)
+	b.force.Add(f)
+	b.torque += r.Cross2D(f)
+	b.Wake()
+}
+
+func (b *Body2D) ApplyImpulse(j Vec2D) {
+	b.LinearVelocity.Add(j.ScalerMulVec(b.invMass))
+	b.Wake()
+}
+
+func (b *Body2D) ApplyImpulseAtPoint(j, p Vec2D) {
+	r := p.SubVec(b.Position)
+	b.LinearVelocity.Add(j.ScalerMulVec(b.invMass))
+	b.AngularVelocity += b.invInertia * r.Cross2D(j)
+	b.Wake()
+}
+
+// static bodies never move and kinematic ones only when given a velocity
+func (b *Body2D) awake() bool {
+	switch b.Type {
+	case BodyDynamic:
+		return !b.sleeping
+	case BodyKinematic:
+		return b.LinearVelocity != (Vec2D{}) || b.AngularVelocity != 0
+	}
+
+	return false
+}
+
+func NewWorld2D(gravity Vec2D) *World2D {
+	return &World2D{
+		Gravity:              gravity,
+		Iterations:           10,
+		Baumgarte:            0.2,
+		Slop:                 0.005,
+		RestitutionThreshold: 1,
+		WarmStarting:         true,
+		AllowSleep:           true,
+		SleepLinear:          0.05,
+		SleepAngular:         0.05,
+		SleepTime:            0.5,
+		byID:                 make(map[int]*Body2D),
+		broad:                NewSweepAndPrune2D(),
+		cache:                make(map[contactKey2D]contactImpulse2D),
+	}
+}
+
+// a body belongs to one world at a time
+func (w *World2D) AddBody(b *Body2D) error {
+	if b.world != nil {
+		return ErrDuplicateID
+	}
+
+	b.id, b.world = w.nextID, w
+	w.nextID++
+
+	w.bodies = append(w.bodies, b)
+	w.byID[b.id] = b
+
+	return w.broad.Insert(b.id, b.Bounds())
+}
+
+// bodies that were touching it wake up
+func (w *World2D) RemoveBody(b *Body2D) error {
+	if b.world != w {
+		return ErrUnknownID
+	}
+
+	for i, o := range w.bodies {
+		if o == b {
+			w.bodies = append(w.bodies[:i], w.bodies[i+1:]...)
+			break
+		}
+	}
+	delete(w.byID, b.id)
+	b.world = nil
+
+	for _, c := range w.touching {
+		if c.A == b {
+			c.B.Wake()
+		} else if c.B == b {
+			c.A.Wake()
+		}
+	}
+
+	return w.broad.Remove(b.id)
+}
+
+func (w *World2D) Bodies() []*Body2D {
+	return w.bodies
+}
+
+func (w *World2D) Contacts() []Contact2D {
+	return w.touching
+}
+
+// Advances the world by dt
+func (w *World2D) Step(dt float64) {
+	if dt <= 0 {
+		return
+	}
+
+	for _, b := range w.bodies {
+		if b.Type == BodyDynamic && !b.sleeping {
+			b.LinearVelocity.Add(w.Gravity.AddVec(b.force.ScalerMulVec(b.invMass)).ScalerMulVec(dt))
+			b.AngularVelocity += b.torque * b.invInertia * dt
+
+			b.LinearVelocity.ScalerMul(1 / (1 + dt*b.LinearDamping))
+			b.AngularVelocity /= 1 + dt*b.AngularDamping
+		}
+
+		b.force, b.torque = Vec2D{}, 0
+	}
+
+	w.collide()
+	w.solve(dt)
+
+	for _, b := range w.bodies {
+		if b.Type != BodyStatic && !b.sleeping {
+			b.Position.Add(b.LinearVelocity.ScalerMulVec(dt))
+			b.Angle += b.AngularVelocity * dt
+		}
+	}
+
+	if w.AllowSleep {
+		w.sleep(dt)
+	}
+}
+
+func (w *World2D) collide() {
+	for _, b := range w.bodies {
+		w.broad.Move(b.id, b.Bounds())
+	}
+	w.broad.Update()
+
+	w.touching = w.touching[:0]
+	for _, p := range w.broad.Pairs() {
+		a, b := w.byID[p[0]], w.byID[p[1]]
+		if a.Type != BodyDynamic && b.Type != BodyDynamic {
+			continue
+		}
+		if !a.awake() && !b.awake() {
+			continue
+		}
+		if a.Category&b.Mask == 0 || b.Category&a.Mask == 0 {
+			continue
+		}
+
+		m, ok := Collide2D(a.Shape, a.Position, a.Rotation(), b.Shape, b.Position, b.Rotation())
+		if !ok {
+			continue
+		}
+
+		if a.sleeping {
+			a.Wake()
+		}
+		if b.sleeping {
+			b.Wake()
+		}
+		w.touching = append(w.touching, Contact2D{A: a, B: b, Manifold2D: m})
+	}
+}
+
+func (w *World2D) solve(dt float64) {
+	w.points = w.points[:0]
+	for _, c := range w.touching {
+		for _, p := range c.Points {
+			w.points = append(w.points, w.prepare(c, p, dt))
+		}
+	}
+
+	for it := 0; it < w.Iterations; it++ {
+		for i := range w.points {
+			w.points[i].solve()
+		}
+	}
+
+	clear(w.cache)
+	for _, p := range w.points {
+		w.cache[contactKey2D{p.a, p.b, p.id}] = contactImpulse2D{p.normalImpulse, p.tangentImpulse}
+	}
+}
+
+func (w *World2D) prepare(c Contact2D, p ContactPoint2D, dt float64) contactPoint2D {
+	a, b := c.A, c.B
+	cp := contactPoint2D{
+		a: a, b: b, normal: c.Normal, id: p.ID,
+		ra:       p.Point.SubVec(a.Position),
+		rb:       p.Point.SubVec(b.Position),
+		friction: math.Sqrt(a.Friction * b.Friction),
+	}
+
+	n, t := cp.normal, cp.normal.RightPerpendicular()
+	cp.normalMass = cp.effectiveMass(n)
+	cp.tangentMass = cp.effectiveMass(t)
+
+	// push out of penetration, or bounce when approaching fast enough
+	cp.bias = -w.Baumgarte / dt * max(p.Depth-w.Slop, 0)
+	dv := cp.relativeVelocity()
+	if vn := dv.Dot(n); vn < -w.RestitutionThreshold {
+		cp.bias = min(cp.bias, max(a.Restitution, b.Restitution)*vn)
+	}
+
+	if prev, ok := w.cache[contactKey2D{a, b, p.ID}]; ok && w.WarmStarting {
+		cp.normalImpulse, cp.tangentImpulse = prev.normal, prev.tangent
+		cp.apply(n.ScalerMulVec(cp.normalImpulse).AddVec(t.ScalerMulVec(cp.tangentImpulse)))
+	}
+
+	return cp
+}
+
+func (c *contactPoint2D) effectiveMass(dir Vec2D) float64 {
+	rna, rnb := c.ra.Cross2D(dir), c.rb.Cross2D(dir)
+	k := c.a.invMass + c.b.invMass + c.a.invInertia*rna*rna + c.b.invInertia*rnb*rnb
+	if k == 0 {
+		return 0
+	}
+
+	return 1 / k
+}
+
+func (c *contactPoint2D) relativeVelocity() Vec2D {
+	vb := c.b.LinearVelocity.AddVec(c.rb.LeftPerpendicular().ScalerMulVec(c.b.AngularVelocity))
+	va := c.a.LinearVelocity.AddVec(c.ra.LeftPerpendicular().ScalerMulVec(c.a.AngularVelocity))
+	return vb.SubVec(va)
+}
+
+// impulse j on B and -j on A
+func (c *contactPoint2D) apply(j Vec2D) {
+	c.a.LinearVelocity.Sub(j.ScalerMulVec(c.a.invMass))
+	c.a.AngularVelocity -= c.a.invInertia * c.ra.Cross2D(j)
+
+	c.b.LinearVelocity.Add(j.ScalerMulVec(c.b.invMass))
+	c.b.AngularVelocity += c.b.invInertia * c.rb.Cross2D(j)
+}
+
+func (c *contactPoint2D) solve() {
+	n, t := c.normal, c.normal.RightPerpendicular()
+
+	// friction bounded by the normal impulse of the last iteration
+	dv := c.relativeVelocity()
+	limit := c.friction * c.normalImpulse
+	old := c.tangentImpulse
+	c.tangentImpulse = Clamp(old-c.tangentMass*dv.Dot(t), -limit, limit)
+	c.apply(t.ScalerMulVec(c.tangentImpulse - old))
+
+	dv = c.relativeVelocity()
+	old = c.normalImpulse
+	c.normalImpulse = max(old-c.normalMass*(dv.Dot(n)+c.bias), 0)
+	c.apply(n.ScalerMulVec(c.normalImpulse - old))
+}
+
+// touching dynamic bodies sleep together once all of them have been slow
+// long enough
+func (w *World2D) sleep(dt float64) {
+	parent := make(map[*Body2D]*Body2D)
+	var find func(b *Body2D) *Body2D
+	find = func(b *Body2D) *Body2D {
+		if p, ok := parent[b]; ok && p != b {
+			parent[b] = find(p)
+			return parent[b]
+		}
+		return b
+	}
+
+	awake := make([]*Body2D, 0, len(w.bodies))
+	for _, b := range w.bodies {
+		if b.Type != BodyDynamic || b.sleeping {
+			continue
+		}
+
+		v := b.LinearVelocity
+		if v.Length() > w.SleepLinear || math.Abs(b.AngularVelocity) > w.SleepAngular {
+			b.sleepTime = 0
+		} else {
+			b.sleepTime += dt
+		}
+
+		parent[b] = b
+		awake = append(awake, b)
+	}
+
+	for _, c := range w.touching {
+		if c.A.Type == BodyDynamic && c.B.Type == BodyDynamic {
+			parent[find(c.A)] = find(c.B)
+		}
+	}
+
+	rested := make(map[*Body2D]float64)
+	for _, b := range awake {
+		r := find(b)
+		if t, ok := rested[r]; !ok || b.sleepTime < t {
+			rested[r] = b.sleepTime
+		}
+	}
+
+	for _, b := range awake {
+		if rested[find(b)] >= w.SleepTime {
+			b.sleeping = true
+			b.LinearVelocity, b.AngularVelocity = Vec2D{}, 0
+		}
+	}
+}
+
+// Closest body hit by the ray whose Category is in mask
+func (w *World2D) Raycast(origin, dir Vec2D, maxDist float64, mask uint32) (RaycastHit2D, bool) {
+	if _, err := dir.Normalize(); err != nil {
+		return RaycastHit2D{}, false
+	}
+
+	hit, found := RaycastHit2D{Distance: maxDist}, false
+	for _, b := range w.bodies {
+		if b.Category&mask == 0 {
+			continue
+		}
+
+		d, n, ok := b.Shape.Raycast(b.Position, b.Rotation(), origin, dir, hit.Distance)
+		if !ok {
+			continue
+		}
+
+		hit = RaycastHit2D{Body: b, Point: origin.AddVec(dir.ScalerMulVec(d)), Normal: n, Distance: d}
+		found = true
+	}
+
+	return hit, found
+}
+
+// bodies whose Category is in mask with bounds overlapping the box, in the
+// order they were added
+func (w *World2D) QueryAABB(box AABB2D, mask uint32) []*Body2D {
+	out := make([]*Body2D, 0)
+	for _, b := range w.bodies {
+		if b.Category&mask != 0 && b.Bounds().Intersects(box) {
+			out = append(out, b)
+		}
+	}
+
+	return out
+}
@@ -22,4 +22,27 @@ var (
 	ErrUnsupportedRotOrder = errors.New("unsupported rotation order: must include 'X', 'Y', and 'Z'")
 
 	ErrInvalidInterPolParam = errors.New("Invalid Interpolation Parameter")
+
+	ErrDegenerateTriangle = errors.New("Degenerate Triangle: Vertices are Collinear")
+	ErrDegeneratePolygon  = errors.New("Degenerate Polygon: Area is Zero")
+	ErrTriangulation      = errors.New("Cant Triangulate the Polygon")
+	ErrInvalidConstraint  = errors.New("Invalid Constraint Edge")
+	ErrInvalidBounds      = errors.New("Invalid Bounds: Min must be less than Max")
+	ErrDegenerateHull     = errors.New("Degenerate Hull: Points are Coplanar")
+
+	ErrDuplicateID = errors.New("ID Already Exists")
+	ErrUnknownID   = errors.New("Unknown ID")
+
+	ErrInvalidCellSize   = errors.New("Invalid Cell Size: must be positive")
+	ErrInvalidProjection = errors.New("Invalid Projection Parameters")
+	ErrUndefinedFrame    = errors.New("Undefined Frame: Curvature is Zero")
+
+	ErrInvalidKnots   = errors.New("Invalid Knots: must be non decreasing with a non empty domain")
+	ErrInvalidWeights = errors.New("Invalid Weights: must be positive")
+
+	ErrDuplicateKeyTime = errors.New("Keyframes Cannot Share a Time")
+	ErrInvalidParent    = errors.New("Invalid Parent: must come before the bone")
+
+	ErrInvalidMass = errors.New("Invalid Mass: must not be negative")
+	ErrZeroVolume  = errors.New("Volume is Zero")
 )
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAnimationCurve(t *testing.T) {
+	// flat tangents over a unit segment give smoothstep
+	c, err := m.NewAnimationCurve([]m.Keyframe{
+		{Time: 1, Value: 1},
+		{Time: 0, Value: 0, Interpolation: m.InterpolateCubic},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []float64{0, 0.2, 0.5, 0.9, 1} {
+		if v, exp := c.Evaluate(u), u*u*(3-2*u); math.Abs(v-exp) > 1e-12 {
+			t.Errorf("expected %f at %f, got %f", exp, u, v)
+		}
+	}
+
+	// explicit third weights are the unweighted curve
+	keys := []m.Keyframe{
+		{Time: 0, Value: 1, OutTangent: 2, Interpolation: m.InterpolateCubic},
+		{Time: 2, Value: -1, InTangent: 0.5, OutTangent: 0.5, Interpolation: m.InterpolateLinear},
+		{Time: 3, Value: 4, Interpolation: m.InterpolateStep},
+		{Time: 5, Value: 2},
+	}
+	plain, _ := m.NewAnimationCurve(keys)
+	keys[0].OutWeight, keys[1].InWeight = 1.0/3, 1.0/3
+	third, _ := m.NewAnimationCurve(keys)
+	keys[0].OutWeight, keys[1].InWeight = 0.8, 0.1
+	weighted, _ := m.NewAnimationCurve(keys)
+
+	for i := 0; i <= 50; i++ {
+		u := float64(i) / 10
+		if a, b := plain.Evaluate(u), third.Evaluate(u); math.Abs(a-b) > 1e-9 {
+			t.Fatalf("weights of 1/3 change the curve at %f, %f and %f", u, a, b)
+		}
+	}
+
+	// weights stretch the handles but keep the values and slopes at the keys
+	h := 1e-6
+	if v := weighted.Evaluate(2); math.Abs(v+1) > 1e-12 {
+		t.Errorf("expected -1 at the key, got %f", v)
+	}
+	if s := (weighted.Evaluate(h) - weighted.Evaluate(0)) / h; math.Abs(s-2) > 1e-3 {
+		t.Errorf("expected slope 2 at the start, got %f", s)
+	}
+	if s := (weighted.Evaluate(2) - weighted.Evaluate(2-h)) / h; math.Abs(s-0.5) > 1e-3 {
+		t.Errorf("expected slope 0.5 into the key, got %f", s)
+	}
+	if v := weighted.Evaluate(1); math.Abs(v-plain.Evaluate(1)) < 1e-3 {
+		t.Error("expected the weights to change the curve")
+	}
+
+	if v := plain.Evaluate(2.5); math.Abs(v-1.5) > 1e-12 {
+		t.Errorf("expected linear 1.5, got %f", v)
+	}
+	if v := plain.Evaluate(4.9); v != 4 {
+		t.Errorf("expected step 4, got %f", v)
+	}
+
+	// infinity modes
+	plain.PreInfinity = m.InfinityClamp
+	if v := plain.Evaluate(-3); v != 1 {
+		t.Errorf("expected the clamped 1, got %f", v)
+	}
+
+	for _, u := range []float64{0.3, 1.7, 2.2, 4.1} {
+		plain.PostInfinity = m.InfinityLoop
+		if a, b := plain.Evaluate(u+10), plain.Evaluate(u); math.Abs(a-b) > 1e-9 {
+			t.Errorf("loop: %f at %f, %f at %f", a, u+10, b, u)
+		}
+
+		plain.PostInfinity = m.InfinityOffset
+		if a, b := plain.Evaluate(u+10), plain.Evaluate(u)+2; math.Abs(a-b) > 1e-9 {
+			t.Errorf("offset: %f at %f, expected %f", a, u+10, b)
+		}
+
+		plain.PostInfinity = m.InfinityPingPong
+		if a, b := plain.Evaluate(10-u), plain.Evaluate(u); math.Abs(a-b) > 1e-9 {
+			t.Errorf("ping-pong: %f at %f, %f at %f", a, 10-u, b, u)
+		}
+
+		plain.PreInfinity = m.InfinityOffset
+		if a, b := plain.Evaluate(u-5), plain.Evaluate(u)-1; math.Abs(a-b) > 1e-9 {
+			t.Errorf("pre offset: %f at %f, expected %f", a, u-5, b)
+		}
+	}
+
+	// the cached lookup agrees with fresh curves in any order
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		u := r.Float64()*7 - 1
+		fresh, _ := m.NewAnimationCurve(keys)
+		if a, b := weighted.Evaluate(u), fresh.Evaluate(u); a != b {
+			t.Fatalf("cached lookup gives %f at %f, fresh curve %f", a, u, b)
+		}
+	}
+
+	if _, err := m.NewAnimationCurve([]m.Keyframe{{Time: 1}, {Time: 1}}); err != m.ErrDuplicateKeyTime {
+		t.Errorf("expected ErrDuplicateKeyTime, got %v", err)
+	}
+}
+
+func TestAnimationCurve3D(t *testing.T) {
+	c, err := m.NewAnimationCurve3D([]m.Keyframe3D{
+		{Time: 0, Value: m.Vec3D{}, Interpolation: m.InterpolateCubic},
+		{Time: 1, Value: m.Vec3D{X: 1, Y: 2}, Interpolation: m.InterpolateCubic},
+		{Time: 3, Value: m.Vec3D{X: 2, Z: -1}, Interpolation: m.InterpolateCubic},
+		{Time: 4, Value: m.Vec3D{X: 5}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SmoothTangents()
+
+	// smooth tangents make the curve C1 at the keys
+	h := 1e-6
+	for _, k := range []float64{1, 3} {
+		in := c.Evaluate(k).SubVec(c.Evaluate(k - h)).ScalerMulVec(1 / h)
+		out := c.Evaluate(k + h).SubVec(c.Evaluate(k)).ScalerMulVec(1 / h)
+		if !nearVec3D(in, out, 1e-4) {
+			t.Errorf("velocity jumps at %f from %v to %v", k, in, out)
+		}
+	}
+
+	c.PostInfinity = m.InfinityOffset
+	if got, exp := c.Evaluate(6), c.Evaluate(2).AddVec(m.Vec3D{X: 5}); !nearVec3D(got, exp, 1e-12) {
+		t.Errorf("expected %v after one cycle, got %v", exp, got)
+	}
+}
+
+func TestAnimationCurveQt(t *testing.T) {
+	about := func(axis m.Vec3D, angle float64) m.Quaternion {
+		s := math.Sin(angle / 2)
+		return m.Quaternion{W: math.Cos(angle / 2), X: axis.X * s, Y: axis.Y * s, Z: axis.Z * s}
+	}
+	same := func(a, b m.Quaternion) bool {
+		return math.Abs(math.Abs(a.Dot(b))-1) < 1e-9
+	}
+
+	z := m.Vec3D{Z: 1}
+	slerp, err := m.NewAnimationCurveQt([]m.KeyframeQt{
+		{Time: 0, Value: about(z, 0), Interpolation: m.InterpolateSlerp},
+		// the other hemisphere, the curve still takes the short way
+		{Time: 1, Value: about(z, math.Pi/2).NegateQt()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q := slerp.Evaluate(0.5); !same(q, about(z, math.Pi/4)) {
+		t.Errorf("expected an eighth turn halfway, got %v", q)
+	}
+
+	slerp.PostInfinity = m.InfinityOffset
+	if q := slerp.Evaluate(2.5); !same(q, about(z, math.Pi*5/4)) {
+		t.Errorf("expected five eighths of a turn, got %v", q)
+	}
+
+	r := rand.New(rand.NewSource(2))
+	keys := make([]m.KeyframeQt, 5)
+	for i := range keys {
+		keys[i] = m.KeyframeQt{Time: float64(i), Value: randomRotation(r), Interpolation: m.InterpolateSquad}
+	}
+	sq, err := m.NewAnimationCurveQt(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, k := range keys {
+		if q := sq.Evaluate(k.Time); !same(q, k.Value) {
+			t.Errorf("squad misses key %d: %v and %v", i, q, k.Value)
+		}
+	}
+
+	// squad keeps the angular velocity continuous at the inner keys
+	h := 1e-5
+	for _, k := range []float64{1, 2, 3} {
+		a, b, c := sq.Evaluate(k-h), sq.Evaluate(k), sq.Evaluate(k+h)
+		in := math.Acos(math.Min(1, math.Abs(a.Dot(b))))
+		out := math.Acos(math.Min(1, math.Abs(b.Dot(c))))
+		if math.Abs(in-out) > 1e-3*(in+out)+1e-9 {
+			t.Errorf("angular speed jumps at %f from %f to %f", k, in/h, out/h)
+		}
+	}
+
+	for i := 0; i <= 40; i++ {
+		if q := sq.Evaluate(float64(i) / 10); math.Abs(q.Magnitude()-1) > 1e-9 {
+			t.Fatalf("expected unit quaternions, got %v", q)
+		}
+	}
+}
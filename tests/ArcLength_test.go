@@ -0,0 +1,186 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestArcLength(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for n := 0; n < 10; n++ {
+		pts := randomCloud(r, 4, 10)
+		c := m.CubicBezier3D{P0: pts[0], P1: pts[1], P2: pts[2], P3: pts[3]}
+
+		table := m.NewArcLengthTable3D(c, 1e-9)
+
+		// a fine chord sum converges from below
+		chords := 0.0
+		prev := c.At(0)
+		for i := 1; i <= 100000; i++ {
+			p := c.At(float64(i) / 100000)
+			chords += prev.Dist(p)
+			prev = p
+		}
+		if math.Abs(table.Length()-chords) > 1e-6 {
+			t.Errorf("expected length %f, got %f", chords, table.Length())
+		}
+
+		for _, u := range []float64{0, 0.1, 0.37, 0.5, 0.9, 1} {
+			if got := table.ParamAt(table.LengthAt(u)); math.Abs(got-u) > 1e-9 {
+				t.Errorf("ParamAt(LengthAt(%f)) = %f", u, got)
+			}
+		}
+
+		ts := table.UniformParams(11)
+		step := table.Length() / 10
+		for i, u := range ts {
+			if s := table.LengthAt(u); math.Abs(s-step*float64(i)) > 1e-8 {
+				t.Errorf("sample %d is at %f, expected %f", i, s, step*float64(i))
+			}
+		}
+
+		if got := m.ResampleCurve3D(c, table, 11); !nearVec3D(got[10], c.P3, 1e-12) || !nearVec3D(got[0], c.P0, 1e-12) {
+			t.Errorf("resampling does not keep the end points")
+		}
+	}
+
+	// piecewise curves keep their joins
+	cr, _ := m.NewCatmullRom2D([]m.Vec2D{{}, {X: 1}, {X: 1, Y: 1}, {X: 3, Y: 1}}, m.CatmullRomCentripetal)
+	table := m.NewArcLengthTable2D(cr, 0)
+	for i := 1; i < 3; i++ {
+		if got := table.ParamAt(table.LengthAt(float64(i))); math.Abs(got-float64(i)) > 1e-9 {
+			t.Errorf("join %d maps back to %f", i, got)
+		}
+	}
+}
+
+func TestPath3D(t *testing.T) {
+	path, err := m.NewPath3D([]m.Vec3D{{}, {X: 3}, {X: 3, Y: 4}, {X: 3, Y: 4, Z: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path.Length() != 9 {
+		t.Errorf("expected length 9, got %f", path.Length())
+	}
+
+	if p := path.PointAtDistance(5); !nearVec3D(p, m.Vec3D{X: 3, Y: 2}, 1e-12) {
+		t.Errorf("expected (3, 2, 0) at 5, got %v", p)
+	}
+
+	table := path.ArcLengthTable()
+	for _, s := range []float64{0, 1, 3, 4.5, 7, 8.5, 9} {
+		if a, b := table.ParamAt(s), path.ParamAtDistance(s); math.Abs(a-b) > 1e-12 {
+			t.Errorf("table gives %f at %f, path gives %f", a, s, b)
+		}
+	}
+
+	adaptive := m.NewArcLengthTable3D(path, 0)
+	if math.Abs(adaptive.Length()-9) > 1e-9 {
+		t.Errorf("adaptive table gives length %f", adaptive.Length())
+	}
+
+	re, err := path.Resample(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// straight stretches keep the unit spacing
+	if d := re.Points[0].Dist(re.Points[1]); math.Abs(d-1) > 1e-12 {
+		t.Errorf("expected spacing 1, got %f", d)
+	}
+
+	u, cp := path.ClosestPoint(m.Vec3D{X: 5, Y: 1})
+	if !nearVec3D(cp, m.Vec3D{X: 3, Y: 1}, 1e-12) || math.Abs(u-1.25) > 1e-12 {
+		t.Errorf("expected (3, 1, 0) at 1.25, got %v at %f", cp, u)
+	}
+
+	if _, err := m.NewPath3D([]m.Vec3D{{}}); err != m.ErrInvalidLen {
+		t.Errorf("expected ErrInvalidLen, got %v", err)
+	}
+}
+
+func checkFrame(t *testing.T, f m.Frame3D) {
+	for _, v := range []m.Vec3D{f.Tangent, f.Normal, f.Binormal} {
+		if math.Abs(v.Length()-1) > 1e-9 {
+			t.Fatalf("frame axis %v is not unit", v)
+		}
+	}
+	if math.Abs(f.Tangent.Dot(f.Normal)) > 1e-9 || math.Abs(f.Tangent.Dot(f.Binormal)) > 1e-9 {
+		t.Fatalf("frame %v is not orthogonal", f)
+	}
+
+	rot := f.RotMat3D()
+	if got := rot.RotateVec3D(m.Vec3D{Z: -1}); !nearVec3D(got, f.Tangent, 1e-9) {
+		t.Fatalf("-Z maps to %v, expected the tangent %v", got, f.Tangent)
+	}
+
+	q := f.Quaternion()
+	if got := q.ToRotMat3D().RotateVec3D(m.Vec3D{Y: 1}); !nearVec3D(got, f.Normal, 1e-9) {
+		t.Fatalf("+Y maps to %v, expected the normal %v", got, f.Normal)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	helix := m.CubicBezier3D{P0: m.Vec3D{X: 1}, P1: m.Vec3D{X: 1, Y: 0.5, Z: 0.1}, P2: m.Vec3D{X: 0.5, Y: 1, Z: 0.2}, P3: m.Vec3D{Y: 1, Z: 0.3}}
+
+	for _, u := range []float64{0, 0.3, 0.7, 1} {
+		f, err := m.FrenetFrame3D(helix, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkFrame(t, f)
+
+		// the normal bends towards the second derivative
+		d2 := helix.SecondDerivative(u)
+		if f.Normal.Dot(d2) <= 0 {
+			t.Errorf("normal %v points away from the curvature at %f", f.Normal, u)
+		}
+	}
+
+	line := m.QuadBezier3D{P1: m.Vec3D{X: 1}, P2: m.Vec3D{X: 2}}
+	if _, err := m.FrenetFrame3D(line, 0.5); err != m.ErrUndefinedFrame {
+		t.Errorf("expected ErrUndefinedFrame, got %v", err)
+	}
+
+	table := m.NewArcLengthTable3D(helix, 0)
+	frames, err := m.ParallelTransportFrames3D(helix, table.UniformParams(200), m.Vec3D{Z: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		checkFrame(t, f)
+	}
+
+	// the frames twist less than Frenet frames, nothing turns faster than
+	// the tangent
+	for i := 1; i < len(frames); i++ {
+		a, b := frames[i-1], frames[i]
+		tangentTurn := math.Acos(math.Min(1, a.Tangent.Dot(b.Tangent)))
+		normalTurn := math.Acos(math.Min(1, a.Normal.Dot(b.Normal)))
+		if normalTurn > tangentTurn+1e-6 {
+			t.Fatalf("normal turns %f at %d while the tangent turns %f", normalTurn, i, tangentTurn)
+		}
+	}
+
+	// in a plane the plane normal is carried along unchanged
+	arc := m.QuadBezier3D{P1: m.Vec3D{X: 1, Y: 1}, P2: m.Vec3D{X: 2}}
+	frames, _ = m.ParallelTransportFrames3D(arc, []float64{0, 0.25, 0.5, 0.75, 1}, m.Vec3D{Z: 1})
+	for _, f := range frames {
+		if !nearVec3D(f.Normal, m.Vec3D{Z: 1}, 1e-9) {
+			t.Errorf("expected normal (0, 0, 1), got %v", f.Normal)
+		}
+	}
+
+	// a path with a straight start still gets frames
+	path, _ := m.NewPath3D([]m.Vec3D{{}, {Y: 1}, {Y: 2}, {X: 1, Y: 2}})
+	frames, err = m.ParallelTransportFrames3D(path, path.ArcLengthTable().UniformParams(7), m.Vec3D{Y: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		checkFrame(t, f)
+	}
+}
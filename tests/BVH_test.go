@@ -0,0 +1,163 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomBox(r *rand.Rand, size float64) m.AABB3D {
+	c := m.Vec3D{X: r.Float64() * size, Y: r.Float64() * size, Z: r.Float64() * size}
+	h := m.Vec3D{X: r.Float64() + 0.1, Y: r.Float64() + 0.1, Z: r.Float64() + 0.1}
+
+	return m.NewAABB3D(c, h)
+}
+
+func sameIDs(a, b []int) bool {
+	sort.Ints(a)
+	sort.Ints(b)
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkBVH(t *testing.T, r *rand.Rand, bvh *m.BVH, boxes map[int]m.AABB3D) {
+	for q := 0; q < 20; q++ {
+		query := randomBox(r, 50)
+		sphere := m.Sphere{Center: query.Center(), Radius: 3}
+
+		var expBox, expSphere []int
+		for id, b := range boxes {
+			if b.Intersects(query) {
+				expBox = append(expBox, id)
+			}
+			if b.IntersectsSphere(sphere) {
+				expSphere = append(expSphere, id)
+			}
+		}
+
+		if got := bvh.QueryAABB(query); !sameIDs(got, expBox) {
+			t.Fatalf("QueryAABB expected %v, Got %v", expBox, got)
+		}
+
+		if got := bvh.QuerySphere(sphere); !sameIDs(got, expSphere) {
+			t.Fatalf("QuerySphere expected %v, Got %v", expSphere, got)
+		}
+
+		dir := m.Vec3D{X: r.NormFloat64(), Y: r.NormFloat64(), Z: r.NormFloat64()}
+		ray, _ := m.NewRay3D(m.Vec3D{X: -10, Y: 25, Z: 25}, m.Vec3D{X: 1, Y: dir.Y * 0.3, Z: dir.Z * 0.3})
+
+		bestT := math.Inf(1)
+		hits := 0
+		for _, b := range boxes {
+			if tMin, _, ok := b.IntersectRay(ray); ok && tMin <= 100 {
+				bestT = math.Min(bestT, math.Max(tMin, 0))
+				hits++
+			}
+		}
+
+		hit, ok := bvh.RayCast(ray, 100, nil)
+		if ok != (hits > 0) || (ok && math.Abs(hit.T-bestT) > 1e-9) {
+			t.Fatalf("RayCast expected %v, Got %v (%v)", bestT, hit, ok)
+		}
+
+		if all := bvh.RayCastAll(ray, 100, nil); len(all) != hits {
+			t.Fatalf("RayCastAll expected %v hits, Got %v", hits, len(all))
+		}
+	}
+}
+
+func TestBVHDynamic(t *testing.T) {
+	r := rand.New(rand.NewSource(21))
+	bvh := m.NewBVH(0.5)
+	boxes := make(map[int]m.AABB3D)
+
+	for id := 0; id < 300; id++ {
+		b := randomBox(r, 50)
+		if err := bvh.Insert(id, b); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		boxes[id] = b
+	}
+
+	if err := bvh.Insert(0, randomBox(r, 50)); err != m.ErrDuplicateID {
+		t.Errorf("Expected %v, Got %v", m.ErrDuplicateID, err)
+	}
+
+	checkBVH(t, r, bvh, boxes)
+
+	for id := 0; id < 300; id += 3 {
+		bvh.Remove(id)
+		delete(boxes, id)
+	}
+
+	for id := 1; id < 300; id += 3 {
+		b := randomBox(r, 50)
+		bvh.Update(id, b)
+		boxes[id] = b
+	}
+
+	if bvh.Len() != len(boxes) {
+		t.Errorf("Expected %v objects, Got %v", len(boxes), bvh.Len())
+	}
+
+	checkBVH(t, r, bvh, boxes)
+
+	bvh.Rebuild()
+	checkBVH(t, r, bvh, boxes)
+
+	if err := bvh.Remove(0); err != m.ErrUnknownID {
+		t.Errorf("Expected %v, Got %v", m.ErrUnknownID, err)
+	}
+}
+
+func TestBVHBuild(t *testing.T) {
+	r := rand.New(rand.NewSource(22))
+	boxes := make(map[int]m.AABB3D)
+
+	ids := make([]int, 0, 500)
+	list := make([]m.AABB3D, 0, 500)
+	for id := 0; id < 500; id++ {
+		b := randomBox(r, 50)
+		ids = append(ids, id)
+		list = append(list, b)
+		boxes[id] = b
+	}
+
+	bvh, err := m.BuildBVH(ids, list, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	checkBVH(t, r, bvh, boxes)
+
+	sphereHit := func(id int, ray m.Ray3D) (float64, bool) {
+		b := boxes[id]
+		return m.Sphere{Center: b.Center(), Radius: 0.1}.IntersectRay(ray)
+	}
+
+	ray := m.Ray3D{Origin: boxes[7].Center().AddVec(m.Vec3D{X: 0, Y: 0, Z: -100}), Dir: m.Vec3D{X: 0, Y: 0, Z: 1}}
+	if hit, ok := bvh.RayCast(ray, 1000, sphereHit); !ok {
+		t.Errorf("Expected a narrow phase hit")
+	} else if hit.T > 100 {
+		t.Errorf("Expected the first hit before the target, Got %v", hit)
+	}
+
+	planes := []m.Plane{{Normal: m.Vec3D{X: 1, Y: 0, Z: 0}, D: -25}}
+	for _, id := range bvh.QueryFrustum(planes) {
+		if boxes[id].Max.X < 25 {
+			t.Errorf("Box %v is behind the plane", id)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+// world of boxes swept by conservative advancement
+type boxWorld []m.OBB
+
+// closest points of a segment and a box, the distance to a convex set is
+// convex along the segment
+func segmentBox(a, b m.Vec3D, box m.OBB) (m.Vec3D, m.Vec3D, float64) {
+	at := func(s float64) (m.Vec3D, m.Vec3D, float64) {
+		p := a.AddVec(b.SubVec(a).ScalerMulVec(s))
+		q := box.ClosestPoint(p)
+		return p, q, p.Dist(q)
+	}
+
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 60; i++ {
+		m1, m2 := lo+(hi-lo)/3, hi-(hi-lo)/3
+		_, _, d1 := at(m1)
+		_, _, d2 := at(m2)
+		if d1 < d2 {
+			hi = m2
+		} else {
+			lo = m1
+		}
+	}
+
+	return at((lo + hi) / 2)
+}
+
+func (w boxWorld) SweepCapsule(c m.Capsule, delta m.Vec3D) (m.SweepHit, bool) {
+	l := delta.Length()
+	hit, found := m.SweepHit{Fraction: math.Inf(1)}, false
+
+	for _, box := range w {
+		t := 0.0
+		for i := 0; i < 500 && t <= 1; i++ {
+			off := delta.ScalerMulVec(t)
+			p, q, d := segmentBox(c.A.AddVec(off), c.B.AddVec(off), box)
+			if gap := d - c.Radius; gap > 1e-7 {
+				if l == 0 {
+					break
+				}
+				t += gap / l
+				continue
+			}
+
+			if t < hit.Fraction {
+				n := p.SubVec(q)
+				n.Normalize()
+				hit, found = m.SweepHit{Fraction: t, Point: q, Normal: n}, true
+			}
+			break
+		}
+	}
+
+	return hit, found
+}
+
+var ground = m.NewOBB(m.Vec3D{Y: -1}, m.Vec3D{X: 50, Y: 1, Z: 50}, m.Quaternion{W: 1})
+
+// box whose top face rises along X from (x, 0) at the angle
+func ramp(x, angle float64) m.OBB {
+	q := m.Quaternion{W: math.Cos(angle / 2), Z: math.Sin(angle / 2)}
+	dir := m.Vec3D{X: math.Cos(angle), Y: math.Sin(angle)}
+	up := m.Vec3D{X: -math.Sin(angle), Y: math.Cos(angle)}
+	center := m.Vec3D{X: x}.AddVec(dir.ScalerMulVec(8)).SubVec(up)
+
+	return m.NewOBB(center, m.Vec3D{X: 8, Y: 1, Z: 5}, q)
+}
+
+// walks at v under gravity, grounded tracks OnGround every frame
+func walk(c *m.CharacterController, v m.Vec3D, frames int) (grounded bool) {
+	dt, fall := 1.0/60, 0.0
+	grounded = true
+	for i := 0; i < frames; i++ {
+		fall -= 9.8 * dt
+		c.Move(v.ScalerMulVec(dt).AddVec(m.Vec3D{Y: fall * dt}))
+		if c.OnGround {
+			fall = 0
+		}
+		grounded = grounded && c.OnGround
+	}
+
+	return grounded
+}
+
+func TestCharacterController(t *testing.T) {
+	stand := 0.9 + 0.01
+
+	// falls onto the ground and stands a skin above it
+	c := m.NewCharacterController(boxWorld{ground}, m.Vec3D{Y: 3}, 0.3, 1.8)
+	walk(c, m.Vec3D{}, 120)
+	if !c.OnGround || math.Abs(c.Position.Y-stand) > 1e-3 || !nearVec3D(c.GroundNormal, m.Vec3D{Y: 1}, 1e-6) {
+		t.Errorf("expected to stand on the ground, at %v on ground %v", c.Position, c.OnGround)
+	}
+
+	// stops at a wall, and slides along it when running in at an angle
+	wall := m.NewOBB(m.Vec3D{X: 3.5, Y: 1}, m.Vec3D{X: 0.5, Y: 1, Z: 50}, m.Quaternion{W: 1})
+	c = m.NewCharacterController(boxWorld{ground, wall}, m.Vec3D{Y: stand}, 0.3, 1.8)
+	walk(c, m.Vec3D{X: 2}, 120)
+	if math.Abs(c.Position.X-(3-0.3-0.01)) > 1e-3 || math.Abs(c.Position.Y-stand) > 1e-3 {
+		t.Errorf("expected to stop at the wall, at %v", c.Position)
+	}
+	walk(c, m.Vec3D{X: 2, Z: 2}, 60)
+	if math.Abs(c.Position.Z-2) > 0.01 || c.Position.X > 3-0.3 {
+		t.Errorf("expected to slide 2 along the wall, at %v", c.Position)
+	}
+
+	// climbs a step lower than StepHeight but not a taller one
+	for _, h := range []float64{0.25, 0.45} {
+		step := m.NewOBB(m.Vec3D{X: 6, Y: h / 2}, m.Vec3D{X: 3, Y: h / 2, Z: 50}, m.Quaternion{W: 1})
+		c = m.NewCharacterController(boxWorld{ground, step}, m.Vec3D{Y: stand}, 0.3, 1.8)
+		walk(c, m.Vec3D{X: 2}, 180)
+
+		if on := c.Position.X > 3; on != (h < c.StepHeight) {
+			t.Errorf("step %f: ended at %v", h, c.Position)
+		}
+		// rounding the edge may leave it inside the skin but never touching
+		if h < c.StepHeight && (math.Abs(c.Position.Y-(stand+h)) > c.SkinWidth || !c.OnGround) {
+			t.Errorf("expected to stand on the step, at %v", c.Position)
+		}
+	}
+
+	// walks up a gentle slope without sliding back, a steep one is a wall
+	for _, deg := range []float64{30, 60} {
+		slope := ramp(3, deg*math.Pi/180)
+		c = m.NewCharacterController(boxWorld{ground, slope}, m.Vec3D{Y: stand}, 0.3, 1.8)
+		walk(c, m.Vec3D{X: 2}, 180)
+
+		if deg == 60 {
+			if c.Position.X > 3 || c.Position.Y > stand+0.3 {
+				t.Errorf("climbed the steep slope to %v", c.Position)
+			}
+			continue
+		}
+
+		if c.Position.X < 4 || !c.OnGround || !nearVec3D(c.GroundNormal, m.Vec3D{X: -0.5, Y: math.Sqrt(3) / 2}, 1e-6) {
+			t.Errorf("expected to walk up the slope, at %v on ground %v", c.Position, c.OnGround)
+		}
+		rest := c.Position
+		walk(c, m.Vec3D{}, 120)
+		if !nearVec3D(c.Position, rest, 1e-3) {
+			t.Errorf("slid down the slope from %v to %v", rest, c.Position)
+		}
+	}
+
+	// running down a slope stays on the ground with snapping and takes off
+	// without it
+	for _, snap := range []float64{0.3, 0} {
+		c = m.NewCharacterController(boxWorld{ground, ramp(3, 20*math.Pi/180)}, m.Vec3D{X: 8, Y: 4}, 0.3, 1.8)
+		c.SnapDistance = 0
+		walk(c, m.Vec3D{}, 120)
+
+		top := c.Position
+		c.SnapDistance = snap
+		grounded := true
+		for i := 0; i < 60; i++ {
+			c.Move(m.Vec3D{X: -4.0 / 60})
+			grounded = grounded && c.OnGround
+		}
+
+		if snap > 0 && (!grounded || math.Abs(top.Y-c.Position.Y-4*math.Tan(20*math.Pi/180)) > 1e-3) {
+			t.Errorf("expected to run down the slope, from %v to %v grounded %v", top, c.Position, grounded)
+		}
+		if snap == 0 && grounded {
+			t.Error("expected to leave the slope without snapping")
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func cubePoints(r *rand.Rand, center m.Vec3D, half float64, inner int) []m.Vec3D {
+	points := make([]m.Vec3D, 0, 8+inner)
+	for _, x := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, z := range []float64{-1, 1} {
+				points = append(points, m.Vec3D{X: center.X + x*half, Y: center.Y + y*half, Z: center.Z + z*half})
+			}
+		}
+	}
+
+	for i := 0; i < inner; i++ {
+		points = append(points, m.Vec3D{
+			X: center.X + (r.Float64()*2-1)*half*0.99,
+			Y: center.Y + (r.Float64()*2-1)*half*0.99,
+			Z: center.Z + (r.Float64()*2-1)*half*0.99,
+		})
+	}
+
+	return points
+}
+
+func TestConvexHull3DCube(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	points := cubePoints(r, m.Vec3D{}, 1, 200)
+
+	hull, err := m.NewConvexHull3D(points, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(hull.Vertices) != 8 || len(hull.Faces) != 12 || len(hull.Edges) != 18 {
+		t.Errorf("Expected 8 vertices, 12 faces and 18 edges, Got %v, %v and %v", len(hull.Vertices), len(hull.Faces), len(hull.Edges))
+	}
+
+	if math.Abs(hull.Volume()-8) > 1e-9 {
+		t.Errorf("Expected volume 8, Got %v", hull.Volume())
+	}
+
+	for i, f := range hull.Faces {
+		tri := m.Triangle3D{A: points[f[0]], B: points[f[1]], C: points[f[2]]}
+		c := tri.Centroid()
+		if n, _ := tri.Normal(); n.Dot(c) <= 0 || n.Dist(hull.Normals[i]) > 1e-9 {
+			t.Errorf("Face %v is not wound outwards", f)
+		}
+	}
+
+	s := hull.Support(m.Vec3D{X: 1, Y: 1, Z: 1})
+	if s.IsNotEqual(m.Vec3D{X: 1, Y: 1, Z: 1}) {
+		t.Errorf("Expected support {1 1 1}, Got %v", s)
+	}
+
+	dist, hit := hull.IntersectRay(m.Ray3D{Origin: m.Vec3D{X: -5, Y: 0.2, Z: 0.3}, Dir: m.Vec3D{X: 1, Y: 0, Z: 0}})
+	if !hit || math.Abs(dist-4) > 1e-9 {
+		t.Errorf("Expected hit at 4, Got %v (%v)", dist, hit)
+	}
+
+	flat := []m.Vec3D{{X: 0, Y: 0, Z: 0}, {X: 1, Y: 0, Z: 0}, {X: 0, Y: 1, Z: 0}, {X: 1, Y: 1, Z: 0}}
+	if _, err := m.NewConvexHull3D(flat, 0); err != m.ErrDegenerateHull {
+		t.Errorf("Expected %v, Got %v", m.ErrDegenerateHull, err)
+	}
+}
+
+func TestConvexHull3DSphere(t *testing.T) {
+	r := rand.New(rand.NewSource(12))
+	points := make([]m.Vec3D, 500)
+	for i := range points {
+		points[i] = m.Vec3D{X: r.NormFloat64(), Y: r.NormFloat64(), Z: r.NormFloat64()}
+	}
+
+	hull, err := m.NewConvexHull3D(points, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if euler := len(hull.Vertices) - len(hull.Edges) + len(hull.Faces); euler != 2 {
+		t.Errorf("Expected euler characteristic 2, Got %v", euler)
+	}
+
+	for _, p := range points {
+		if !hull.ContainsPoint(p) {
+			t.Fatalf("Point %v is outside the hull", p)
+		}
+	}
+}
+
+func TestGJKIntersect(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	a, _ := m.NewConvexHull3D(cubePoints(r, m.Vec3D{}, 1, 0), 0)
+
+	tests := []struct {
+		name   string
+		center m.Vec3D
+		res    bool
+	}{
+		{"Overlapping", m.Vec3D{X: 1.5, Y: 0.5, Z: 0}, true},
+		{"Contained", m.Vec3D{X: 0, Y: 0, Z: 0}, true},
+		{"Separated", m.Vec3D{X: 2.5, Y: 0, Z: 0}, false},
+		{"Diagonal Gap", m.Vec3D{X: 2.1, Y: 2.1, Z: 2.1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := m.NewConvexHull3D(cubePoints(r, tt.center, 0.5, 0), 0)
+			if m.GJKIntersect(a, b) != tt.res {
+				t.Errorf("Expected %v", tt.res)
+			}
+		})
+	}
+
+	tri := m.Triangle3D{A: m.Vec3D{X: -3, Y: -3, Z: 0.5}, B: m.Vec3D{X: 3, Y: -3, Z: 0.5}, C: m.Vec3D{X: 0, Y: 3, Z: 0.5}}
+	if !m.GJKIntersect(a, tri) {
+		t.Errorf("Expected the triangle to cut the cube")
+	}
+}
@@ -0,0 +1,233 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func nearVec2D(a, b m.Vec2D, eps float64) bool {
+	return math.Abs(a.X-b.X) <= eps && math.Abs(a.Y-b.Y) <= eps
+}
+
+func randomVec2D(r *rand.Rand, size float64) m.Vec2D {
+	return m.Vec2D{X: r.Float64() * size, Y: r.Float64() * size}
+}
+
+// derivatives against central differences and bounds against samples
+func checkCurve2D(t *testing.T, name string, c m.Curve2D, bounds m.AABB2D) {
+	lo, hi := c.Domain()
+	h := 1e-6
+
+	box := bounds.Expand(1e-9)
+	var sampled m.AABB2D
+
+	for i := 0; i <= 200; i++ {
+		u := lo + (hi-lo)*float64(i)/200
+		p := c.At(u)
+
+		if i == 0 {
+			sampled = m.AABB2D{Min: p, Max: p}
+		}
+		sampled.AddPoint(p)
+
+		if !box.ContainsPoint(p) {
+			t.Fatalf("%s: point %v at %f outside bounds %v", name, p, u, bounds)
+		}
+
+		a, b := math.Max(lo, u-h), math.Min(hi, u+h)
+		fd := c.At(b).SubVec(c.At(a)).ScalerMulVec(1 / (b - a))
+		if d := c.Derivative(u); !nearVec2D(d, fd, 1e-4*(1+d.Length())) {
+			t.Fatalf("%s: derivative at %f is %v, differences give %v", name, u, d, fd)
+		}
+
+		fd2 := c.Derivative(b).SubVec(c.Derivative(a)).ScalerMulVec(1 / (b - a))
+		if d2 := c.SecondDerivative(u); !nearVec2D(d2, fd2, 1e-3*(1+d2.Length())) {
+			t.Fatalf("%s: second derivative at %f is %v, differences give %v", name, u, d2, fd2)
+		}
+	}
+
+	// the bounds are tight up to the sampling
+	if !nearVec2D(sampled.Min, bounds.Min, 1e-3) || !nearVec2D(sampled.Max, bounds.Max, 1e-3) {
+		t.Errorf("%s: bounds %v are loose, samples span %v", name, bounds, sampled)
+	}
+}
+
+func TestBezier2D(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for n := 0; n < 20; n++ {
+		q := m.QuadBezier2D{P0: randomVec2D(r, 10), P1: randomVec2D(r, 10), P2: randomVec2D(r, 10)}
+		c := m.CubicBezier2D{P0: randomVec2D(r, 10), P1: randomVec2D(r, 10), P2: randomVec2D(r, 10), P3: randomVec2D(r, 10)}
+
+		checkCurve2D(t, "quad", q, q.Bounds())
+		checkCurve2D(t, "cubic", c, c.Bounds())
+
+		if !nearVec2D(c.At(0), c.P0, 1e-12) || !nearVec2D(c.At(1), c.P3, 1e-12) {
+			t.Errorf("cubic does not start and end on its end points")
+		}
+
+		s := r.Float64()
+		a, b := c.Split(s)
+		qa, qb := q.Split(s)
+		for _, u := range []float64{0, 0.25, 0.5, 0.75, 1} {
+			if !nearVec2D(a.At(u), c.At(u*s), 1e-9) || !nearVec2D(b.At(u), c.At(s+u*(1-s)), 1e-9) {
+				t.Fatalf("split at %f does not match the cubic at %f", s, u)
+			}
+			if !nearVec2D(qa.At(u), q.At(u*s), 1e-9) || !nearVec2D(qb.At(u), q.At(s+u*(1-s)), 1e-9) {
+				t.Fatalf("split at %f does not match the quad at %f", s, u)
+			}
+			if !nearVec2D(q.ToCubic().At(u), q.At(u), 1e-9) {
+				t.Fatalf("ToCubic differs at %f", u)
+			}
+		}
+
+		p := randomVec2D(r, 10)
+		u, cp := m.ClosestPointOnCurve2D(c, p)
+		for i := 0; i <= 1000; i++ {
+			if d := p.Dist(c.At(float64(i) / 1000)); d < p.Dist(cp)-1e-9 {
+				t.Fatalf("closest point at %f is %f away, sample %d is %f away", u, p.Dist(cp), i, d)
+			}
+		}
+	}
+
+	// y = x^2 for x in [-1, 1], curvature 2 / (1 + 4x^2)^1.5
+	parabola := m.QuadBezier2D{P0: m.Vec2D{X: -1, Y: 1}, P1: m.Vec2D{Y: -1}, P2: m.Vec2D{X: 1, Y: 1}}
+	for _, u := range []float64{0, 0.3, 0.5, 1} {
+		x := 2*u - 1
+		exp := 2 / math.Pow(1+4*x*x, 1.5)
+		if c := m.Curvature2D(parabola, u); math.Abs(c-exp) > 1e-9 {
+			t.Errorf("expected curvature %f at %f, got %f", exp, u, c)
+		}
+	}
+
+	// turning clockwise gives negative curvature
+	flipped := m.QuadBezier2D{P0: parabola.P0, P1: m.Vec2D{Y: 3}, P2: parabola.P2}
+	if c := m.Curvature2D(flipped, 0.5); c >= 0 {
+		t.Errorf("expected negative curvature, got %f", c)
+	}
+
+	tan, err := m.Tangent2D(parabola, 0.5)
+	if err != nil || !nearVec2D(tan, m.Vec2D{X: 1}, 1e-12) {
+		t.Errorf("expected tangent (1, 0), got %v", tan)
+	}
+}
+
+func TestSplines2D(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	points := make([]m.Vec2D, 7)
+	for i := range points {
+		points[i] = m.Vec2D{X: float64(i) * 2, Y: r.Float64() * 6}
+	}
+	// a repeated point must not break the non uniform variants
+	points[4] = points[3]
+
+	for _, alpha := range []float64{m.CatmullRomUniform, m.CatmullRomCentripetal, m.CatmullRomChordal} {
+		cr, err := m.NewCatmullRom2D(points, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, p := range points {
+			if !nearVec2D(cr.At(float64(i)), p, 1e-9) {
+				t.Errorf("alpha %f: expected %v at %d, got %v", alpha, p, i, cr.At(float64(i)))
+			}
+		}
+
+		// C1 for uniform spacing, otherwise the segments are rescaled to a
+		// unit range and only the direction is continuous
+		for i := 1; i < cr.Segments(); i++ {
+			in := cr.Segment(i - 1).Derivative(1)
+			out := cr.Segment(i).Derivative(0)
+
+			if alpha == m.CatmullRomUniform {
+				if !nearVec2D(in, out, 1e-9) {
+					t.Errorf("tangent jumps at %d from %v to %v", i, in, out)
+				}
+				continue
+			}
+
+			if in.Length() == 0 || out.Length() == 0 {
+				continue
+			}
+			if cross := in.X*out.Y - in.Y*out.X; math.Abs(cross) > 1e-9*in.Length()*out.Length() || in.Dot(out) <= 0 {
+				t.Errorf("alpha %f: direction jumps at %d from %v to %v", alpha, i, in, out)
+			}
+		}
+
+		checkCurve2D(t, "catmull-rom", cr.Segment(1), cr.Segment(1).Bounds())
+	}
+
+	bs, err := m.NewBSpline2D(points, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lo, hi := bs.Domain()
+	if !nearVec2D(bs.At(lo), points[0], 1e-12) || !nearVec2D(bs.At(hi), points[len(points)-1], 1e-12) {
+		t.Errorf("clamped spline does not start and end on the end points")
+	}
+
+	// C2 at the joins
+	for i := 1; i < bs.Segments(); i++ {
+		a, b := bs.Segment(i-1), bs.Segment(i)
+		if !nearVec2D(a.Derivative(1), b.Derivative(0), 1e-9) || !nearVec2D(a.SecondDerivative(1), b.SecondDerivative(0), 1e-9) {
+			t.Errorf("spline is not C2 at %d", i)
+		}
+	}
+
+	checkCurve2D(t, "bspline", bs, bs.Bounds())
+
+	if _, err := m.NewBSpline2D(points[:3], false); err != m.ErrInvalidLen {
+		t.Errorf("expected ErrInvalidLen, got %v", err)
+	}
+
+	h := m.Hermite2D{P0: m.Vec2D{}, M0: m.Vec2D{X: 3}, P1: m.Vec2D{X: 1, Y: 1}, M1: m.Vec2D{Y: 3}}
+	if !nearVec2D(h.Derivative(0), h.M0, 1e-12) || !nearVec2D(h.Derivative(1), h.M1, 1e-12) {
+		t.Errorf("hermite end tangents are %v and %v", h.Derivative(0), h.Derivative(1))
+	}
+}
+
+func TestSplines3D(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	points := randomCloud(r, 6, 10)
+
+	cr, err := m.NewCatmullRom3D(points, m.CatmullRomCentripetal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, p := range points {
+		if !nearVec3D(cr.At(float64(i)), p, 1e-9) {
+			t.Errorf("expected %v at %d, got %v", p, i, cr.At(float64(i)))
+		}
+	}
+
+	box := cr.Bounds().Expand(1e-9)
+	for i := 0; i <= 500; i++ {
+		if p := cr.At(float64(i) / 100); !box.ContainsPoint(p) {
+			t.Fatalf("point %v outside bounds %v", p, box)
+		}
+	}
+
+	bs, _ := m.NewBSpline3D(points, false)
+	p := randomCloud(r, 1, 10)[0]
+	u, cp := m.ClosestPointOnCurve3D(bs, p)
+
+	lo, hi := bs.Domain()
+	for i := 0; i <= 1000; i++ {
+		s := lo + (hi-lo)*float64(i)/1000
+		q := bs.At(s)
+		if d := p.Dist(q); d < p.Dist(cp)-1e-9 {
+			t.Fatalf("closest point at %f is %f away, %f is %f away", u, p.Dist(cp), s, d)
+		}
+	}
+
+	// the curvature of a space curve has no sign
+	helix := m.CubicBezier3D{P0: m.Vec3D{X: 1}, P1: m.Vec3D{X: 1, Y: 0.5, Z: 0.1}, P2: m.Vec3D{X: 0.5, Y: 1, Z: 0.2}, P3: m.Vec3D{Y: 1, Z: 0.3}}
+	if c := m.Curvature3D(helix, 0.5); c <= 0 || c > 2 {
+		t.Errorf("unexpected curvature %f", c)
+	}
+}
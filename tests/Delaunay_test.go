@@ -0,0 +1,130 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomPoints(r *rand.Rand, n int, size float64) []m.Vec2D {
+	out := make([]m.Vec2D, n)
+	for i := range out {
+		out[i] = m.Vec2D{X: r.Float64() * size, Y: r.Float64() * size}
+	}
+
+	return out
+}
+
+func TestDelaunay(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	points := randomPoints(r, 300, 100)
+
+	d, err := m.NewDelaunay(points)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	area := 0.0
+	for ti, tri := range d.Triangles {
+		tr := m.Triangle2D{A: points[tri[0]], B: points[tri[1]], C: points[tri[2]]}
+		if !tr.IsCCW() {
+			t.Fatalf("Expected ccw triangle %v", tri)
+		}
+		area += tr.Area()
+
+		center, _ := tr.Circumcenter()
+		radius := center.Dist(tr.A)
+		for i, p := range points {
+			if i != tri[0] && i != tri[1] && i != tri[2] && center.Dist(p) < radius-1e-9 {
+				t.Fatalf("Point %v inside circumcircle of triangle %v", i, ti)
+			}
+		}
+
+		for i, nb := range d.Neighbors[ti] {
+			if nb == -1 {
+				continue
+			}
+
+			shared := 0
+			for _, v := range d.Triangles[nb] {
+				if v == tri[(i+1)%3] || v == tri[(i+2)%3] {
+					shared++
+				}
+			}
+			if shared != 2 {
+				t.Fatalf("Neighbor %v of triangle %v does not share the edge", nb, ti)
+			}
+		}
+	}
+
+	if hull := m.PolygonArea(m.ConvexHull2D(points)); math.Abs(area-hull) > 1e-6 {
+		t.Errorf("Expected triangles to cover the hull area %v, Got %v", hull, area)
+	}
+}
+
+func TestConstrainedDelaunay(t *testing.T) {
+	points := []m.Vec2D{
+		{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10},
+		{X: 2, Y: 5}, {X: 8, Y: 5}, {X: 5, Y: 4.5}, {X: 5, Y: 5.5}, {X: 5, Y: 1}, {X: 5, Y: 9},
+	}
+
+	constraints := [][2]int{{4, 5}, {8, 9}}
+	if _, err := m.NewConstrainedDelaunay(points, constraints); err != m.ErrInvalidConstraint {
+		t.Errorf("Expected %v for crossing constraints, Got %v", m.ErrInvalidConstraint, err)
+	}
+
+	d, err := m.NewConstrainedDelaunay(points, [][2]int{{4, 5}})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	found := false
+	for _, e := range d.Edges() {
+		if e == [2]int{4, 5} {
+			found = true
+		}
+	}
+
+	if !found || !d.IsConstrained(5, 4) {
+		t.Errorf("Expected constrained edge {4 5} in %v", d.Edges())
+	}
+}
+
+func TestVoronoi(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	points := randomPoints(r, 100, 50)
+	points = append(points, points[0])
+
+	cells, err := m.NewVoronoi(points, m.Vec2D{X: 0, Y: 0}, m.Vec2D{X: 50, Y: 50})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	area := 0.0
+	for i, c := range cells {
+		area += m.PolygonArea(c.Polygon)
+
+		if len(c.Polygon) > 0 && !m.PointInPolygon(points[i], c.Polygon) {
+			t.Errorf("Site %v is not inside its cell", i)
+		}
+
+		for _, nb := range c.Neighbors {
+			symmetric := false
+			for _, back := range cells[nb].Neighbors {
+				symmetric = symmetric || back == i
+			}
+			if !symmetric {
+				t.Errorf("Neighbors %v and %v are not symmetric", i, nb)
+			}
+		}
+	}
+
+	if len(cells[100].Polygon) != 0 {
+		t.Errorf("Expected an empty cell for the duplicate site")
+	}
+
+	if math.Abs(area-2500) > 1e-6 {
+		t.Errorf("Expected cells to cover the bounds, Got area %v", area)
+	}
+}
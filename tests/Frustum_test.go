@@ -0,0 +1,217 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomRotation(r *rand.Rand) m.Quaternion {
+	q := m.Quaternion{W: r.NormFloat64(), X: r.NormFloat64(), Y: r.NormFloat64(), Z: r.NormFloat64()}
+	q.Normalize()
+
+	return q
+}
+
+func nearVec3D(a, b m.Vec3D, eps float64) bool {
+	return math.Abs(a.X-b.X) <= eps && math.Abs(a.Y-b.Y) <= eps && math.Abs(a.Z-b.Z) <= eps
+}
+
+func TestMat4D(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for n := 0; n < 20; n++ {
+		tr := randomCloud(r, 1, 10)[0]
+		s := m.Vec3D{X: r.Float64() + 0.5, Y: r.Float64() + 0.5, Z: r.Float64() + 0.5}
+		q := randomRotation(r)
+
+		trs := m.TRSMat4D(tr, q, s)
+		if det := trs.Det(); math.Abs(det-s.X*s.Y*s.Z) > 1e-9 {
+			t.Errorf("expected det %f, got %f", s.X*s.Y*s.Z, det)
+		}
+
+		id := trs.Multiply(trs.InverseMat())
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				exp := 0.0
+				if i == j {
+					exp = 1
+				}
+				if math.Abs(id[i][j]-exp) > 1e-9 {
+					t.Fatalf("M * M^-1 is not the identity: %v", id)
+				}
+			}
+		}
+
+		p := randomCloud(r, 1, 5)[0]
+		rotated := q.ToRotMat3D().RotateVec3D(m.Vec3D{X: p.X * s.X, Y: p.Y * s.Y, Z: p.Z * s.Z})
+		if got := trs.TransformPoint(p); !nearVec3D(got, rotated.AddVec(tr), 1e-9) {
+			t.Errorf("TransformPoint: expected %v, got %v", rotated.AddVec(tr), got)
+		}
+	}
+
+	z := m.Mat4D{}
+	if err := z.Inverse(); err != m.ErrZeroDet {
+		t.Errorf("expected ErrZeroDet, got %v", err)
+	}
+}
+
+func TestFrustum(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	pos := m.Vec3D{X: 1, Y: 2, Z: 3}
+	rot := randomRotation(r)
+	fov, aspect, near, far := 1.0, 1.5, 0.5, 40.0
+
+	f, err := m.NewFrustum(pos, rot, fov, aspect, near, far)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proj, err := m.PerspectiveMat4D(fov, aspect, near, far)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	viewProj := proj.Multiply(m.ViewMat4D(pos, rot))
+	fm, err := m.NewFrustumFromMat4D(viewProj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range f.Planes {
+		if !nearVec3D(f.Planes[i].Normal, fm.Planes[i].Normal, 1e-9) || math.Abs(f.Planes[i].D-fm.Planes[i].D) > 1e-9 {
+			t.Errorf("plane %d: camera %v, matrix %v", i, f.Planes[i], fm.Planes[i])
+		}
+	}
+
+	// the corners are the clip space cube mapped back to world space
+	inv := viewProj.InverseMat()
+	ndc := [8]m.Vec3D{
+		{X: -1, Y: -1, Z: -1}, {X: 1, Y: -1, Z: -1}, {X: 1, Y: 1, Z: -1}, {X: -1, Y: 1, Z: -1},
+		{X: -1, Y: -1, Z: 1}, {X: 1, Y: -1, Z: 1}, {X: 1, Y: 1, Z: 1}, {X: -1, Y: 1, Z: 1},
+	}
+	corners := f.Corners()
+	for i, c := range ndc {
+		if exp := inv.TransformPoint(c); !nearVec3D(corners[i], exp, 1e-6) {
+			t.Errorf("corner %d: expected %v, got %v", i, exp, corners[i])
+		}
+	}
+
+	forward := rot.ToRotMat3D().RotateVec3D(m.Vec3D{Z: -1})
+	ahead := pos.AddVec(forward.ScalerMulVec(10))
+	behind := pos.AddVec(forward.ScalerMulVec(-10))
+
+	if f.ClassifyPoint(ahead) != m.CullInside || f.ClassifyPoint(behind) != m.CullOutside {
+		t.Error("expected the point ahead inside and the point behind outside")
+	}
+
+	if f.ClassifySphere(m.Sphere{Center: ahead, Radius: 1}) != m.CullInside {
+		t.Error("expected a small sphere ahead to be inside")
+	}
+	if f.ClassifySphere(m.Sphere{Center: pos, Radius: 1}) != m.CullIntersect {
+		t.Error("expected a sphere around the camera to intersect")
+	}
+	if f.ClassifySphere(m.Sphere{Center: behind, Radius: 1}) != m.CullOutside {
+		t.Error("expected a sphere behind the camera to be outside")
+	}
+
+	samples := randomCloud(r, 2000, 1)
+	for n := 0; n < 200; n++ {
+		c := pos.AddVec(randomCloud(r, 1, 60)[0].SubVec(m.Vec3D{X: 30, Y: 30, Z: 30}))
+		h := m.Vec3D{X: r.Float64()*3 + 0.1, Y: r.Float64()*3 + 0.1, Z: r.Float64()*3 + 0.1}
+
+		box := m.NewAABB3D(c, h)
+		obb := m.NewOBB(c, h, randomRotation(r))
+
+		bc := box.Corners()
+		checkCull(t, "AABB", f, f.ClassifyAABB(box), bc[:], func(u m.Vec3D) m.Vec3D {
+			return m.Vec3D{
+				X: box.Min.X + u.X*(box.Max.X-box.Min.X),
+				Y: box.Min.Y + u.Y*(box.Max.Y-box.Min.Y),
+				Z: box.Min.Z + u.Z*(box.Max.Z-box.Min.Z),
+			}
+		}, samples)
+
+		oc := obb.Corners()
+		checkCull(t, "OBB", f, f.ClassifyOBB(obb), oc[:], func(u m.Vec3D) m.Vec3D {
+			p := oc[0]
+			p.Add(oc[1].SubVec(oc[0]).ScalerMulVec(u.X))
+			p.Add(oc[2].SubVec(oc[0]).ScalerMulVec(u.Y))
+			p.Add(oc[4].SubVec(oc[0]).ScalerMulVec(u.Z))
+			return p
+		}, samples)
+	}
+
+	if _, err := m.NewFrustum(pos, rot, 0, aspect, near, far); err != m.ErrInvalidProjection {
+		t.Errorf("expected ErrInvalidProjection, got %v", err)
+	}
+}
+
+func checkCull(t *testing.T, name string, f m.Frustum, res m.CullResult, corners []m.Vec3D, at func(m.Vec3D) m.Vec3D, samples []m.Vec3D) {
+	switch res {
+	case m.CullInside:
+		for _, c := range corners {
+			if f.ClassifyPoint(c) == m.CullOutside {
+				t.Errorf("%s inside but corner %v is outside", name, c)
+			}
+		}
+
+	case m.CullOutside:
+		for _, u := range samples {
+			if p := at(u); f.ClassifyPoint(p) != m.CullOutside {
+				t.Errorf("%s outside but %v is inside", name, p)
+				return
+			}
+		}
+	}
+}
+
+func TestOBBIntersects(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+
+	for n := 0; n < 500; n++ {
+		a := m.NewOBB(randomCloud(r, 1, 6)[0], m.Vec3D{X: r.Float64() + 0.2, Y: r.Float64() + 0.2, Z: r.Float64() + 0.2}, randomRotation(r))
+		b := m.NewOBB(randomCloud(r, 1, 6)[0], m.Vec3D{X: r.Float64() + 0.2, Y: r.Float64() + 0.2, Z: r.Float64() + 0.2}, randomRotation(r))
+
+		if got, exp := a.Intersects(b), m.GJKIntersect(a, b); got != exp {
+			t.Errorf("case %d: SAT says %v, GJK says %v", n, got, exp)
+		}
+
+		p := randomCloud(r, 1, 6)[0]
+		cp := a.ClosestPoint(p)
+		if a.ContainsPoint(p) != nearVec3D(cp, p, 1e-12) {
+			t.Errorf("case %d: ClosestPoint and ContainsPoint disagree for %v", n, p)
+		}
+		if !a.Bounds().Expand(1e-9).ContainsPoint(cp) {
+			t.Errorf("case %d: closest point %v outside the bounds", n, cp)
+		}
+	}
+
+	// the frustum planes cull a BVH the same way ClassifyAABB does
+	f, _ := m.NewFrustum(m.Vec3D{}, m.Quaternion{W: 1}, 1.2, 1, 0.1, 30)
+
+	ids := make([]int, 0)
+	boxes := make([]m.AABB3D, 0)
+	exp := make([]int, 0)
+	for i := 0; i < 300; i++ {
+		b := randomBox(r, 60)
+		b = m.AABB3D{Min: b.Min.SubVec(m.Vec3D{X: 30, Y: 30, Z: 60}), Max: b.Max.SubVec(m.Vec3D{X: 30, Y: 30, Z: 60})}
+
+		ids = append(ids, i)
+		boxes = append(boxes, b)
+		if f.ClassifyAABB(b) != m.CullOutside {
+			exp = append(exp, i)
+		}
+	}
+
+	bvh, err := m.BuildBVH(ids, boxes, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := bvh.QueryFrustum(f.Planes[:]); !sameIDs(got, exp) {
+		t.Errorf("QueryFrustum: expected %v, got %v", exp, got)
+	}
+}
@@ -0,0 +1,168 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGrid3DTraverse(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	g, err := m.NewGrid3D(m.Vec3D{X: -1, Y: 0.5, Z: 2}, m.Vec3D{X: 1, Y: 0.5, Z: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.NewGrid3D(m.Vec3D{}, m.Vec3D{X: 1, Y: 0, Z: 1}); err != m.ErrInvalidCellSize {
+		t.Errorf("expected ErrInvalidCellSize, got %v", err)
+	}
+
+	for n := 0; n < 50; n++ {
+		origin := randomCloud(r, 1, 10)[0]
+		dir := randomCloud(r, 1, 2)[0].SubVec(m.Vec3D{X: 1, Y: 1, Z: 1})
+		ray, err := m.NewRay3D(origin, dir)
+		if err != nil {
+			continue
+		}
+
+		maxT := 8.0
+		hits := g.TraverseAll(ray, maxT)
+
+		if hits[0].Cell != g.Cell(origin) || hits[0].T != 0 {
+			t.Fatalf("expected the start cell first, got %v", hits[0])
+		}
+
+		prevT := 0.0
+		for i, h := range hits {
+			if h.T < prevT || h.T > maxT {
+				t.Fatalf("hit %d: param %f out of order", i, h.T)
+			}
+			prevT = h.T
+
+			tMin, tMax, ok := g.CellBounds(h.Cell).IntersectRay(ray)
+			if !ok || tMax < 0 || math.Abs(math.Max(tMin, 0)-h.T) > 1e-9 {
+				t.Fatalf("hit %d: cell %v entered at %f, box says %f", i, h.Cell, h.T, tMin)
+			}
+
+			if i > 0 {
+				// the normal faces back along the ray and points from the new cell to the old one
+				if h.Normal.Dot(ray.Dir) >= 0 {
+					t.Errorf("hit %d: normal %v faces away from the ray", i, h.Normal)
+				}
+
+				prev := hits[i-1].Cell
+				diff := m.Vec3D{
+					X: float64(prev[0] - h.Cell[0]),
+					Y: float64(prev[1] - h.Cell[1]),
+					Z: float64(prev[2] - h.Cell[2]),
+				}
+				if diff.IsNotEqual(h.Normal) {
+					t.Errorf("hit %d: normal %v does not match the step %v", i, h.Normal, diff)
+				}
+			}
+		}
+
+		// the walk must stop at the first cell boundary beyond maxT
+		last := hits[len(hits)-1]
+		_, exit, _ := g.CellBounds(last.Cell).IntersectRay(ray)
+		if exit < maxT-1e-9 {
+			t.Errorf("stopped early in cell %v leaving at %f", last.Cell, exit)
+		}
+	}
+
+	ray, _ := m.NewRay3D(m.Vec3D{X: 0.5, Y: 0.75, Z: 3}, m.Vec3D{X: 1})
+	count := 0
+	g.Traverse(ray, math.Inf(1), func(h m.VoxelHit) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Errorf("expected the walk to stop after 5 cells, got %d", count)
+	}
+}
+
+func TestGrid2DLines(t *testing.T) {
+	line := m.BresenhamLine(0, 0, 5, 2)
+	exp := [][2]int{{0, 0}, {1, 0}, {2, 1}, {3, 1}, {4, 2}, {5, 2}}
+	if len(line) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, line)
+	}
+	for i := range exp {
+		if line[i] != exp[i] {
+			t.Fatalf("expected %v, got %v", exp, line)
+		}
+	}
+
+	if line := m.BresenhamLine(3, -2, 3, -2); len(line) != 1 {
+		t.Errorf("expected a single cell, got %v", line)
+	}
+
+	g, _ := m.NewGrid2D(m.Vec2D{}, m.Vec2D{X: 1, Y: 1})
+
+	// passing exactly through corners picks up both side cells
+	cover := g.SupercoverLine(m.Vec2D{X: 0.5, Y: 0.5}, m.Vec2D{X: 2.5, Y: 2.5})
+	exp = [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {2, 1}, {1, 2}, {2, 2}}
+	if len(cover) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, cover)
+	}
+	for i := range exp {
+		if cover[i] != exp[i] {
+			t.Fatalf("expected %v, got %v", exp, cover)
+		}
+	}
+
+	r := rand.New(rand.NewSource(2))
+	g, _ = m.NewGrid2D(m.Vec2D{X: -3, Y: 1}, m.Vec2D{X: 0.5, Y: 2})
+
+	for n := 0; n < 100; n++ {
+		a := m.Vec2D{X: r.Float64()*20 - 10, Y: r.Float64()*20 - 10}
+		b := m.Vec2D{X: r.Float64()*20 - 10, Y: r.Float64()*20 - 10}
+
+		cover := g.SupercoverLine(a, b)
+		dda := g.DDALine(a, b)
+
+		if cover[0] != g.Cell(a) || cover[len(cover)-1] != g.Cell(b) {
+			t.Fatalf("supercover does not run from %v to %v: %v", g.Cell(a), g.Cell(b), cover)
+		}
+		if dda[0] != g.Cell(a) || dda[len(dda)-1] != g.Cell(b) {
+			t.Fatalf("dda does not run from %v to %v: %v", g.Cell(a), g.Cell(b), dda)
+		}
+
+		inCover := make(map[[2]int]bool)
+		for i, c := range cover {
+			inCover[c] = true
+
+			// 4-connected without corners in random lines
+			if i > 0 {
+				p := cover[i-1]
+				if d := abs(c[0]-p[0]) + abs(c[1]-p[1]); d != 1 {
+					t.Fatalf("supercover cells %v and %v are not adjacent", p, c)
+				}
+			}
+		}
+
+		// every sample of the segment lies in a covered cell
+		for s := 0; s <= 200; s++ {
+			p, _ := a.LerpV(b, float64(s)/200)
+			if !inCover[g.Cell(p)] {
+				t.Fatalf("point %v in cell %v is not covered", p, g.Cell(p))
+			}
+		}
+
+		for i := 1; i < len(dda); i++ {
+			p, c := dda[i-1], dda[i]
+			if abs(c[0]-p[0]) > 1 || abs(c[1]-p[1]) > 1 {
+				t.Fatalf("dda cells %v and %v are not 8-connected", p, c)
+			}
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,278 @@
+package tests
+
+import (
+	m "golem"
+	"math/rand"
+	"testing"
+)
+
+func bruteRectPairs(boxes map[int]m.AABB2D) [][2]int {
+	out := make([][2]int, 0)
+	for a := 0; a < 200; a++ {
+		for b := a + 1; b < 200; b++ {
+			ba, okA := boxes[a]
+			bb, okB := boxes[b]
+			if okA && okB && ba.Intersects(bb) {
+				out = append(out, [2]int{a, b})
+			}
+		}
+	}
+
+	return out
+}
+
+func samePairs(a, b [][2]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestHashGrid2D(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	if _, err := m.NewHashGrid2D(0); err != m.ErrInvalidCellSize {
+		t.Errorf("expected ErrInvalidCellSize, got %v", err)
+	}
+
+	g, err := m.NewHashGrid2D(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boxes := make(map[int]m.AABB2D)
+	for i := 0; i < 200; i++ {
+		// negative coordinates land in negative cells
+		b := randomRect(r, 40)
+		b.Min.X -= 20
+		b.Max.X -= 20
+
+		if err := g.Insert(i, b); err != nil {
+			t.Fatal(err)
+		}
+		boxes[i] = b
+	}
+
+	for i := 0; i < 200; i += 3 {
+		b := randomRect(r, 40)
+		if err := g.Move(i, b); err != nil {
+			t.Fatal(err)
+		}
+		boxes[i] = b
+	}
+
+	for i := 0; i < 200; i += 7 {
+		if err := g.Remove(i); err != nil {
+			t.Fatal(err)
+		}
+		delete(boxes, i)
+	}
+
+	if got := g.Cell(m.Vec2D{X: -0.5, Y: 3}); got != [2]int{-1, 1} {
+		t.Errorf("expected cell [-1 1], got %v", got)
+	}
+
+	for n := 0; n < 20; n++ {
+		query := randomRect(r, 40)
+		circle := m.Circle{Center: query.Center(), Radius: 3}
+
+		var expRect, expCircle []int
+		for id, b := range boxes {
+			if b.Intersects(query) {
+				expRect = append(expRect, id)
+			}
+			if b.IntersectsCircle(circle) {
+				expCircle = append(expCircle, id)
+			}
+		}
+
+		if got := g.QueryRect(query); !sameIDs(got, expRect) {
+			t.Errorf("QueryRect: expected %v, got %v", expRect, got)
+		}
+		if got := g.QueryCircle(circle); !sameIDs(got, expCircle) {
+			t.Errorf("QueryCircle: expected %v, got %v", expCircle, got)
+		}
+	}
+
+	// large enough to scan the objects instead of the cells
+	all := g.QueryRect(m.AABB2D{Min: m.Vec2D{X: -1000, Y: -1000}, Max: m.Vec2D{X: 1000, Y: 1000}})
+	if len(all) != len(boxes) {
+		t.Errorf("expected %d objects, got %d", len(boxes), len(all))
+	}
+
+	if got, exp := g.Pairs(), bruteRectPairs(boxes); !samePairs(got, exp) {
+		t.Errorf("Pairs: expected %v, got %v", exp, got)
+	}
+
+	// a ground plane millions of cells wide goes in the overflow list, the
+	// cell and scan paths both return ids in order
+	g, _ = m.NewHashGrid2D(0.01)
+	ground := m.AABB2D{Min: m.Vec2D{X: -1e6, Y: -1}, Max: m.Vec2D{X: 1e6}}
+	g.Insert(5, ground)
+	g.Insert(3, m.AABB2D{Min: m.Vec2D{X: -0.01, Y: -0.01}, Max: m.Vec2D{X: 0.01, Y: 0.01}})
+	g.Insert(1, m.AABB2D{Min: m.Vec2D{X: 10, Y: -0.01}, Max: m.Vec2D{X: 10.01, Y: 0.01}})
+	g.Insert(4, m.AABB2D{Min: m.Vec2D{X: 10, Y: 5}, Max: m.Vec2D{X: 10.01, Y: 5.01}})
+
+	near := m.AABB2D{Min: m.Vec2D{X: 0.001, Y: -0.005}, Max: m.Vec2D{X: 0.005, Y: -0.001}}
+	if got := g.QueryRect(near); len(got) != 2 || got[0] != 3 || got[1] != 5 {
+		t.Errorf("expected [3 5] from the cells, got %v", got)
+	}
+	if got := g.QueryRect(m.AABB2D{Min: m.Vec2D{X: -20, Y: -20}, Max: m.Vec2D{X: 20}}); len(got) != 3 || got[0] != 1 || got[1] != 3 || got[2] != 5 {
+		t.Errorf("expected [1 3 5] from the scan, got %v", got)
+	}
+	if got := g.Pairs(); !samePairs(got, [][2]int{{1, 5}, {3, 5}}) {
+		t.Errorf("expected the ground under 1 and 3, got %v", got)
+	}
+
+	// shrunk it moves back into the cells
+	g.Move(5, m.AABB2D{Min: m.Vec2D{X: 9.99, Y: 4.99}, Max: m.Vec2D{X: 10.02, Y: 5.02}})
+	if got := g.Pairs(); !samePairs(got, [][2]int{{4, 5}}) {
+		t.Errorf("expected the moved box on 4, got %v", got)
+	}
+	g.Move(5, ground)
+	if err := g.Remove(5); err != nil || len(g.Pairs()) != 0 || len(g.QueryRect(near)) != 1 {
+		t.Errorf("expected the ground gone, %v", err)
+	}
+}
+
+func TestHashGrid3D(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	g, err := m.NewHashGrid3D(2.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boxes := make(map[int]m.AABB3D)
+	for i := 0; i < 200; i++ {
+		b := randomBox(r, 30)
+		if err := g.Insert(i, b); err != nil {
+			t.Fatal(err)
+		}
+		boxes[i] = b
+	}
+
+	for i := 0; i < 200; i += 2 {
+		b := randomBox(r, 30)
+		if err := g.Move(i, b); err != nil {
+			t.Fatal(err)
+		}
+		boxes[i] = b
+	}
+
+	for n := 0; n < 20; n++ {
+		query := randomBox(r, 30)
+		sphere := m.Sphere{Center: query.Center(), Radius: 3}
+
+		var expBox, expSphere []int
+		for id, b := range boxes {
+			if b.Intersects(query) {
+				expBox = append(expBox, id)
+			}
+			if b.IntersectsSphere(sphere) {
+				expSphere = append(expSphere, id)
+			}
+		}
+
+		if got := g.QueryAABB(query); !sameIDs(got, expBox) {
+			t.Errorf("QueryAABB: expected %v, got %v", expBox, got)
+		}
+		if got := g.QuerySphere(sphere); !sameIDs(got, expSphere) {
+			t.Errorf("QuerySphere: expected %v, got %v", expSphere, got)
+		}
+	}
+
+	if err := g.Insert(1, boxes[1]); err != m.ErrDuplicateID {
+		t.Errorf("expected ErrDuplicateID, got %v", err)
+	}
+
+	// a slab too big for the cells is still found and paired
+	g, _ = m.NewHashGrid3D(0.01)
+	g.Insert(2, m.AABB3D{Min: m.Vec3D{X: -1e4, Y: -1, Z: -1e4}, Max: m.Vec3D{X: 1e4, Z: 1e4}})
+	g.Insert(1, m.AABB3D{Min: m.Vec3D{X: -0.01, Y: -0.01, Z: -0.01}, Max: m.Vec3D{X: 0.01, Y: 0.01, Z: 0.01}})
+	if got := g.QueryAABB(m.AABB3D{Min: m.Vec3D{X: 0.001, Y: -0.005, Z: 0.001}, Max: m.Vec3D{X: 0.005, Y: -0.001, Z: 0.005}}); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("expected [1 2], got %v", got)
+	}
+	if got := g.Pairs(); !samePairs(got, [][2]int{{1, 2}}) {
+		t.Errorf("expected the slab under 1, got %v", got)
+	}
+}
+
+func TestSweepAndPrune2D(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+
+	s := m.NewSweepAndPrune2D()
+
+	boxes := make(map[int]m.AABB2D)
+	for i := 0; i < 200; i++ {
+		b := randomRect(r, 40)
+		if err := s.Insert(i, b); err != nil {
+			t.Fatal(err)
+		}
+		boxes[i] = b
+	}
+
+	prev := [][2]int{}
+	for frame := 0; frame < 10; frame++ {
+		ev := s.Update()
+
+		exp := bruteRectPairs(boxes)
+		if got := s.Pairs(); !samePairs(got, exp) {
+			t.Fatalf("frame %d: expected %v, got %v", frame, exp, got)
+		}
+
+		was := make(map[[2]int]bool)
+		for _, p := range prev {
+			was[p] = true
+		}
+
+		is := make(map[[2]int]bool)
+		for _, p := range exp {
+			is[p] = true
+		}
+
+		for _, p := range ev.Begin {
+			if was[p] || !is[p] {
+				t.Errorf("frame %d: %v should not begin", frame, p)
+			}
+		}
+		for _, p := range ev.Persist {
+			if !was[p] || !is[p] {
+				t.Errorf("frame %d: %v should not persist", frame, p)
+			}
+		}
+		for _, p := range ev.End {
+			if !was[p] || is[p] {
+				t.Errorf("frame %d: %v should not end", frame, p)
+			}
+		}
+
+		if len(ev.Begin)+len(ev.Persist) != len(exp) || len(ev.Persist)+len(ev.End) != len(prev) {
+			t.Errorf("frame %d: events do not cover every pair", frame)
+		}
+
+		prev = exp
+
+		for id, b := range boxes {
+			d := m.Vec2D{X: r.Float64() - 0.5, Y: r.Float64() - 0.5}
+			b = m.AABB2D{Min: b.Min.AddVec(d), Max: b.Max.AddVec(d)}
+
+			if err := s.Move(id, b); err != nil {
+				t.Fatal(err)
+			}
+			boxes[id] = b
+		}
+
+		if err := s.Remove(frame); err != nil {
+			t.Fatal(err)
+		}
+		delete(boxes, frame)
+	}
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTwoBoneIK(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		p := randomCloud(r, 5, 2)
+		root, mid, end, target, pole := p[0], p[1], p[2], p[3], p[4]
+		a, b := root.Dist(mid), mid.Dist(end)
+
+		res, err := m.TwoBoneIK(root, mid, end, target, pole)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d := root.Dist(res.Mid); math.Abs(d-a) > 1e-9 {
+			t.Fatalf("upper bone changes length from %f to %f", a, d)
+		}
+		if d := res.Mid.Dist(res.End); math.Abs(d-b) > 1e-9 {
+			t.Fatalf("lower bone changes length from %f to %f", b, d)
+		}
+
+		dist := root.Dist(target)
+		if reach := dist >= math.Abs(a-b) && dist <= a+b; reach != res.Reached {
+			t.Fatalf("expected reached %t at distance %f", reach, dist)
+		}
+		if res.Reached && !nearVec3D(res.End, target, 1e-9) {
+			t.Fatalf("end at %v, expected the target %v", res.End, target)
+		}
+
+		// the mid joint lies in the plane of root, target and pole on the pole side
+		n := target.SubVec(root).CrossV(pole.SubVec(root))
+		n.Normalize()
+		if off := res.Mid.SubVec(root); math.Abs(off.Dot(n)) > 1e-9 {
+			t.Fatalf("mid joint leaves the pole plane by %f", off.Dot(n))
+		}
+
+		// the rotations carry the old bones onto the new ones
+		rm := res.RootRotation.ToRotMat3D()
+		if got := rm.RotateVec3D(mid.SubVec(root)); !nearVec3D(got, res.Mid.SubVec(root), 1e-9) {
+			t.Fatalf("root rotation gives %v, expected %v", got, res.Mid.SubVec(root))
+		}
+		mm := res.MidRotation.ToRotMat3D()
+		if got := mm.RotateVec3D(end.SubVec(mid)); !nearVec3D(got, res.End.SubVec(res.Mid), 1e-9) {
+			t.Fatalf("mid rotation gives %v, expected %v", got, res.End.SubVec(res.Mid))
+		}
+	}
+
+	// equal bones fold back onto a target on the root
+	res, err := m.TwoBoneIK(m.Vec3D{}, m.Vec3D{X: 1}, m.Vec3D{X: 2}, m.Vec3D{}, m.Vec3D{Y: 1})
+	if err != nil || !res.Reached || !nearVec3D(res.End, m.Vec3D{}, 1e-9) {
+		t.Errorf("expected to fold onto the root, got %v reached %t", res.End, res.Reached)
+	}
+
+	if _, err := m.TwoBoneIK(m.Vec3D{}, m.Vec3D{}, m.Vec3D{X: 1}, m.Vec3D{Y: 1}, m.Vec3D{Z: 1}); err != m.ErrZeroLen {
+		t.Errorf("expected ErrZeroLen, got %v", err)
+	}
+}
+
+func ikChain(t *testing.T) *m.IKChain {
+	joints := make([]m.Vec3D, 6)
+	for i := range joints {
+		joints[i] = m.Vec3D{Y: float64(i)}
+	}
+
+	c, err := m.NewIKChain(joints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func checkChain(t *testing.T, name string, c *m.IKChain, start []m.Vec3D) {
+	t.Helper()
+
+	for i := 0; i+1 < len(c.Joints); i++ {
+		if d := c.Joints[i].Dist(c.Joints[i+1]); math.Abs(d-1) > 1e-9 {
+			t.Fatalf("%s: bone %d has length %f", name, i, d)
+		}
+
+		// rotations follow the bones
+		rm := c.Rotations[i].ToRotMat3D()
+		old := start[i+1].SubVec(start[i])
+		if got, exp := rm.RotateVec3D(old), c.Joints[i+1].SubVec(c.Joints[i]); !nearVec3D(got, exp, 1e-9) {
+			t.Fatalf("%s: rotation %d takes the bone to %v, expected %v", name, i, got, exp)
+		}
+	}
+	if c.Joints[0] != start[0] {
+		t.Fatalf("%s: the root moved to %v", name, c.Joints[0])
+	}
+}
+
+func TestIKChain(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	solvers := []struct {
+		name  string
+		solve func(*m.IKChain, m.Vec3D) m.IKResult
+	}{
+		{"ccd", (*m.IKChain).SolveCCD},
+		{"fabrik", (*m.IKChain).SolveFABRIK},
+	}
+
+	for _, s := range solvers {
+		for i := 0; i < 50; i++ {
+			c := ikChain(t)
+			c.MaxIterations = 200
+			start := append([]m.Vec3D(nil), c.Joints...)
+
+			target := randomCloud(r, 1, 3)[0]
+			res := s.solve(c, target)
+			checkChain(t, s.name, c, start)
+
+			if !res.Converged || res.Error > c.Tolerance {
+				t.Fatalf("%s: no convergence towards %v, error %f after %d iterations", s.name, target, res.Error, res.Iterations)
+			}
+			if d := c.Joints[len(c.Joints)-1].Dist(target); math.Abs(d-res.Error) > 1e-12 {
+				t.Fatalf("%s: reported error %f, actual %f", s.name, res.Error, d)
+			}
+		}
+
+		// out of reach the chain stretches towards the target
+		c := ikChain(t)
+		start := append([]m.Vec3D(nil), c.Joints...)
+		res := s.solve(c, m.Vec3D{X: 20})
+		checkChain(t, s.name, c, start)
+		if res.Converged || math.Abs(res.Error-15) > 1e-2 {
+			t.Errorf("%s: expected an error of 15 out of reach, got %f", s.name, res.Error)
+		}
+	}
+}
+
+func TestIKLimits(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	limit := math.Pi / 6
+
+	for _, solve := range []func(*m.IKChain, m.Vec3D) m.IKResult{(*m.IKChain).SolveCCD, (*m.IKChain).SolveFABRIK} {
+		for i := 0; i < 20; i++ {
+			c := ikChain(t)
+			for k := range c.Limits {
+				c.Limits[k].MaxBend = limit
+			}
+			c.Limits[2].Hinge = m.Vec3D{X: 1}
+
+			solve(c, randomCloud(r, 1, 4)[0])
+
+			up := m.Vec3D{Y: 1}
+			for k := 0; k+1 < len(c.Joints); k++ {
+				dir := c.Joints[k+1].SubVec(c.Joints[k])
+				if a := math.Acos(math.Min(1, dir.Dot(up))); a > limit+1e-9 {
+					t.Fatalf("bone %d bends by %f over the limit %f", k, a, limit)
+				}
+
+				// the hinge turns with the bone before it
+				if k == 2 {
+					rm := c.Rotations[1].ToRotMat3D()
+					axis := rm.RotateVec3D(m.Vec3D{X: 1})
+					if d := axis.Dot(dir); math.Abs(d) > 1e-9 {
+						t.Fatalf("hinged bone leaves its plane by %f", d)
+					}
+				}
+				up = dir
+			}
+		}
+	}
+
+	if _, err := m.NewIKChain([]m.Vec3D{{}}); err != m.ErrInvalidLen {
+		t.Errorf("expected ErrInvalidLen, got %v", err)
+	}
+	if _, err := m.NewIKChain([]m.Vec3D{{}, {}}); err != m.ErrZeroLen {
+		t.Errorf("expected ErrZeroLen, got %v", err)
+	}
+}
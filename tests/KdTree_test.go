@@ -0,0 +1,186 @@
+package tests
+
+import (
+	m "golem"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomCloud(r *rand.Rand, n int, size float64) []m.Vec3D {
+	points := make([]m.Vec3D, n)
+	for i := range points {
+		points[i] = m.Vec3D{X: r.Float64() * size, Y: r.Float64() * size, Z: r.Float64() * size}
+	}
+
+	return points
+}
+
+func bruteNearest3D(points []m.Vec3D, p m.Vec3D, k int) []int {
+	ids := make([]int, len(points))
+	for i := range ids {
+		ids[i] = i
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		return p.Dist(points[ids[i]]) < p.Dist(points[ids[j]])
+	})
+
+	return ids[:min(k, len(ids))]
+}
+
+func TestKdTree2D(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// grid points give lots of equal coordinates and distances
+	points := make([]m.Vec2D, 0, 400)
+	for i := 0; i < 400; i++ {
+		points = append(points, m.Vec2D{X: float64(r.Intn(20)), Y: float64(r.Intn(20))})
+	}
+
+	tree := m.NewKdTree2D(points)
+	if tree.Len() != len(points) {
+		t.Fatalf("expected %d points, got %d", len(points), tree.Len())
+	}
+
+	for q := 0; q < 50; q++ {
+		p := m.Vec2D{X: r.Float64()*24 - 2, Y: r.Float64()*24 - 2}
+
+		ids := make([]int, len(points))
+		for i := range ids {
+			ids[i] = i
+		}
+		sort.SliceStable(ids, func(i, j int) bool {
+			return p.Dist(points[ids[i]]) < p.Dist(points[ids[j]])
+		})
+
+		got := tree.KNearest(p, 8)
+		for i := range got {
+			if got[i] != ids[i] {
+				t.Fatalf("KNearest: expected %v, got %v", ids[:8], got)
+			}
+		}
+
+		idx, dist, ok := tree.Nearest(p)
+		if !ok || idx != ids[0] || dist != p.Dist(points[ids[0]]) {
+			t.Errorf("Nearest: expected %d, got %d at %f", ids[0], idx, dist)
+		}
+
+		var exp []int
+		for i, pt := range points {
+			if p.Dist(pt) <= 3 {
+				exp = append(exp, i)
+			}
+		}
+		if got := tree.RadiusSearch(p, 3); !sameIDs(got, exp) {
+			t.Errorf("RadiusSearch: expected %v, got %v", exp, got)
+		}
+	}
+}
+
+func TestKdTree3D(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	tree := m.NewKdTree3D(nil)
+	if _, _, ok := tree.Nearest(m.Vec3D{}); ok {
+		t.Error("expected no nearest point in an empty tree")
+	}
+
+	for round := 0; round < 3; round++ {
+		points := randomCloud(r, 500+round*200, 10)
+		tree.Rebuild(points)
+
+		for q := 0; q < 30; q++ {
+			p := randomCloud(r, 1, 12)[0]
+
+			exp := bruteNearest3D(points, p, 6)
+			got := tree.KNearest(p, 6)
+			for i := range exp {
+				if got[i] != exp[i] {
+					t.Fatalf("KNearest: expected %v, got %v", exp, got)
+				}
+			}
+
+			var within []int
+			for i, pt := range points {
+				if p.Dist(pt) <= 1.5 {
+					within = append(within, i)
+				}
+			}
+			if got := tree.RadiusSearch(p, 1.5); !sameIDs(got, within) {
+				t.Errorf("RadiusSearch: expected %v, got %v", within, got)
+			}
+		}
+	}
+
+	if got := tree.KNearest(m.Vec3D{}, 5000); len(got) != tree.Len() {
+		t.Errorf("expected all %d points, got %d", tree.Len(), len(got))
+	}
+}
+
+func BenchmarkKdTree3DNearest(b *testing.B) {
+	r := rand.New(rand.NewSource(3))
+	points := randomCloud(r, 10000, 100)
+	queries := randomCloud(r, 1024, 100)
+
+	tree := m.NewKdTree3D(points)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Nearest(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkBruteForce3DNearest(b *testing.B) {
+	r := rand.New(rand.NewSource(3))
+	points := randomCloud(r, 10000, 100)
+	queries := randomCloud(r, 1024, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := queries[i%len(queries)]
+
+		best, bestDist := -1, 0.0
+		for j, pt := range points {
+			if d := p.Dist(pt); best == -1 || d < bestDist {
+				best, bestDist = j, d
+			}
+		}
+	}
+}
+
+func BenchmarkKdTree3DKNearest(b *testing.B) {
+	r := rand.New(rand.NewSource(3))
+	points := randomCloud(r, 10000, 100)
+	queries := randomCloud(r, 1024, 100)
+
+	tree := m.NewKdTree3D(points)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.KNearest(queries[i%len(queries)], 16)
+	}
+}
+
+func BenchmarkBruteForce3DKNearest(b *testing.B) {
+	r := rand.New(rand.NewSource(3))
+	points := randomCloud(r, 10000, 100)
+	queries := randomCloud(r, 1024, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bruteNearest3D(points, queries[i%len(queries)], 16)
+	}
+}
+
+func BenchmarkKdTree3DBuild(b *testing.B) {
+	r := rand.New(rand.NewSource(3))
+	points := randomCloud(r, 10000, 100)
+
+	tree := m.NewKdTree3D(points)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Rebuild(points)
+	}
+}
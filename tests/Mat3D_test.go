@@ -0,0 +1,40 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func TestMat3DInverse(t *testing.T) {
+	tests := []struct {
+		name string
+		mat  m.Mat3D
+		adj  m.Mat3D
+	}{
+		{"Diagonal Case", m.Mat3D{{2, 0, 0}, {0, 4, 0}, {0, 0, 5}}, m.Mat3D{{20, 0, 0}, {0, 10, 0}, {0, 0, 8}}},
+		{"Symmetric Case", m.Mat3D{{2, 1, 0}, {1, 3, 1}, {0, 1, 4}}, m.Mat3D{{11, -4, 1}, {-4, 8, -2}, {1, -2, 5}}},
+		{"Non Symmetric Case", m.Mat3D{{1, 2, 3}, {0, 1, 4}, {5, 6, 0}}, m.Mat3D{{-24, 18, 5}, {20, -15, -4}, {-5, 4, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if adj := tt.mat.AdjointMat(); !adj.IsEqual(tt.adj) {
+				t.Errorf("Expected adjugate %v, Got %v", tt.adj, adj)
+			}
+
+			id := tt.mat.Multiply(tt.mat.InverseMat())
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					exp := 0.0
+					if i == j {
+						exp = 1
+					}
+					if math.Abs(id[i][j]-exp) > 1e-12 {
+						t.Fatalf("Expected the identity, Got %v", id)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// quadratic unit circle in the xy plane from 9 control points
+func nurbsCircle() ([]m.Vec3D, []float64, []float64) {
+	h := math.Sqrt2 / 2
+
+	points := []m.Vec3D{
+		{X: 1}, {X: 1, Y: 1}, {Y: 1}, {X: -1, Y: 1}, {X: -1}, {X: -1, Y: -1}, {Y: -1}, {X: 1, Y: -1}, {X: 1},
+	}
+	weights := []float64{1, h, 1, h, 1, h, 1, h, 1}
+	knots := []float64{0, 0, 0, 0.25, 0.25, 0.5, 0.5, 0.75, 0.75, 1, 1, 1}
+
+	return points, weights, knots
+}
+
+func sameCurve(t *testing.T, name string, a, b *m.NURBSCurve) {
+	for i := 0; i <= 100; i++ {
+		u := float64(i) / 100
+		if !nearVec3D(a.At(u), b.At(u), 1e-9) {
+			t.Fatalf("%s: curves differ at %f, %v and %v", name, u, a.At(u), b.At(u))
+		}
+	}
+}
+
+func TestNURBSCurve(t *testing.T) {
+	points, weights, knots := nurbsCircle()
+	circle, err := m.NewNURBSCurve(2, points, weights, knots)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := 1e-6
+	for i := 0; i <= 100; i++ {
+		u := float64(i) / 100
+
+		p := circle.At(u)
+		if math.Abs(p.Length()-1) > 1e-12 {
+			t.Fatalf("point %v at %f is off the circle", p, u)
+		}
+
+		a, b := math.Max(0, u-h), math.Min(1, u+h)
+		fd := circle.At(b).SubVec(circle.At(a)).ScalerMulVec(1 / (b - a))
+		if d := circle.Derivative(u); !nearVec3D(d, fd, 1e-4*(1+d.Length())) {
+			t.Fatalf("derivative at %f is %v, differences give %v", u, d, fd)
+		}
+
+		// only C1 at the double knots
+		if i%25 == 0 {
+			continue
+		}
+		fd2 := circle.Derivative(b).SubVec(circle.Derivative(a)).ScalerMulVec(1 / (b - a))
+		if d2 := circle.SecondDerivative(u); !nearVec3D(d2, fd2, 1e-3*(1+d2.Length())) {
+			t.Fatalf("second derivative at %f is %v, differences give %v", u, d2, fd2)
+		}
+	}
+
+	// curvature of the unit circle is 1 everywhere
+	if c := m.Curvature3D(circle, 0.3); math.Abs(c-1) > 1e-9 {
+		t.Errorf("expected curvature 1, got %f", c)
+	}
+
+	// without weights and inner knots it is a Bezier curve
+	r := rand.New(rand.NewSource(1))
+	pts := randomCloud(r, 4, 10)
+	bez := m.CubicBezier3D{P0: pts[0], P1: pts[1], P2: pts[2], P3: pts[3]}
+	nc, _ := m.NewNURBSCurve(3, pts, nil, nil)
+	for _, u := range []float64{0, 0.2, 0.5, 0.9, 1} {
+		if !nearVec3D(nc.At(u), bez.At(u), 1e-12) || !nearVec3D(nc.Derivative(u), bez.Derivative(u), 1e-9) {
+			t.Errorf("expected the Bezier curve at %f", u)
+		}
+	}
+
+	// knot insertion and degree elevation keep the shape
+	ws := make([]float64, 8)
+	for i := range ws {
+		ws[i] = r.Float64() + 0.5
+	}
+	random, err := m.NewNURBSCurve(3, randomCloud(r, 8, 10), ws, []float64{0, 0, 0, 0, 0.2, 0.5, 0.5, 0.8, 1, 1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []*m.NURBSCurve{circle, random} {
+		ins, _ := m.NewNURBSCurve(c.Degree, c.Points, c.Weights, c.Knots)
+		if err := ins.InsertKnot(0.3, 2); err != nil {
+			t.Fatal(err)
+		}
+		if len(ins.Points) != len(c.Points)+2 {
+			t.Errorf("expected %d points, got %d", len(c.Points)+2, len(ins.Points))
+		}
+		sameCurve(t, "insert", c, ins)
+
+		// 0.5 already has multiplicity 2 in both curves
+		full, _ := m.NewNURBSCurve(c.Degree, c.Points, c.Weights, c.Knots)
+		full.InsertKnot(0.5, 5)
+		if added := len(full.Points) - len(c.Points); added != c.Degree-2 {
+			t.Errorf("expected %d knots added at 0.5, got %d", c.Degree-2, added)
+		}
+
+		for _, by := range []int{1, 2} {
+			el, _ := m.NewNURBSCurve(c.Degree, c.Points, c.Weights, c.Knots)
+			if err := el.ElevateDegree(by); err != nil {
+				t.Fatal(err)
+			}
+			if el.Degree != c.Degree+by || len(el.Knots) != len(el.Points)+el.Degree+1 {
+				t.Fatalf("elevated curve has degree %d, %d points and %d knots", el.Degree, len(el.Points), len(el.Knots))
+			}
+			sameCurve(t, "elevate", c, el)
+		}
+	}
+
+	if _, err := m.NewNURBSCurve(2, points, weights, knots[1:]); err != m.ErrInvalidLen {
+		t.Errorf("expected ErrInvalidLen, got %v", err)
+	}
+	weights[3] = 0
+	if _, err := m.NewNURBSCurve(2, points, weights, knots); err != m.ErrInvalidWeights {
+		t.Errorf("expected ErrInvalidWeights, got %v", err)
+	}
+	knots[4] = 0.1
+	if _, err := m.NewNURBSCurve(2, points, nil, knots); err != m.ErrInvalidKnots {
+		t.Errorf("expected ErrInvalidKnots, got %v", err)
+	}
+}
+
+// unit sphere as the semicircle in the xz plane revolved around z
+func nurbsSphere(t *testing.T) *m.NURBSSurface {
+	circle, cw, ck := nurbsCircle()
+
+	h := math.Sqrt2 / 2
+	profile := []m.Vec3D{{Z: -1}, {X: 1, Z: -1}, {X: 1}, {X: 1, Z: 1}, {Z: 1}}
+	pw := []float64{1, h, 1, h, 1}
+
+	points := make([][]m.Vec3D, len(profile))
+	weights := make([][]float64, len(profile))
+	for i, p := range profile {
+		points[i] = make([]m.Vec3D, len(circle))
+		weights[i] = make([]float64, len(circle))
+		for j, c := range circle {
+			points[i][j] = m.Vec3D{X: p.X * c.X, Y: p.X * c.Y, Z: p.Z}
+			weights[i][j] = pw[i] * cw[j]
+		}
+	}
+
+	s, err := m.NewNURBSSurface(2, 2, points, weights, []float64{0, 0, 0, 0.5, 0.5, 1, 1, 1}, ck)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func sameSurface(t *testing.T, name string, a, b *m.NURBSSurface) {
+	for i := 0; i <= 20; i++ {
+		for j := 0; j <= 20; j++ {
+			u, v := float64(i)/20, float64(j)/20
+			if !nearVec3D(a.At(u, v), b.At(u, v), 1e-9) {
+				t.Fatalf("%s: surfaces differ at (%f, %f)", name, u, v)
+			}
+		}
+	}
+}
+
+func TestNURBSSurface(t *testing.T) {
+	s := nurbsSphere(t)
+
+	h := 1e-6
+	for i := 1; i < 20; i++ {
+		for j := 0; j <= 20; j++ {
+			u, v := float64(i)/20, float64(j)/20
+
+			p := s.At(u, v)
+			if math.Abs(p.Length()-1) > 1e-12 {
+				t.Fatalf("point %v at (%f, %f) is off the sphere", p, u, v)
+			}
+
+			n, err := s.Normal(u, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !nearVec3D(n, p, 1e-9) && !nearVec3D(n, p.ScalerMulVec(-1), 1e-9) {
+				t.Fatalf("normal %v at %v is not radial", n, p)
+			}
+
+			du, dv := s.Partials(u, v)
+			a, b := math.Max(0, v-h), math.Min(1, v+h)
+			fdu := s.At(u+h, v).SubVec(s.At(u-h, v)).ScalerMulVec(1 / (2 * h))
+			fdv := s.At(u, b).SubVec(s.At(u, a)).ScalerMulVec(1 / (b - a))
+			if !nearVec3D(du, fdu, 1e-4) || !nearVec3D(dv, fdv, 1e-4) {
+				t.Fatalf("partials at (%f, %f) are %v %v, differences give %v %v", u, v, du, dv, fdu, fdv)
+			}
+		}
+	}
+
+	mesh, err := s.Tessellate(32, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mesh.Vertices) != 33*65 || len(mesh.Faces) != 2*32*64 {
+		t.Fatalf("unexpected mesh size %d vertices, %d faces", len(mesh.Vertices), len(mesh.Faces))
+	}
+	if area := mesh.Area(); math.Abs(area-4*math.Pi) > 0.05 {
+		t.Errorf("expected area near %f, got %f", 4*math.Pi, area)
+	}
+
+	// face normals agree with the vertex normals, even at the poles
+	for i, f := range mesh.Faces {
+		tri := mesh.Triangle(i)
+		fn, err := tri.Normal()
+		if err != nil {
+			continue
+		}
+		for _, v := range f {
+			if n := mesh.Normals[v]; n.Dot(fn) <= 0 {
+				t.Fatalf("face %d normal %v disagrees with vertex normal %v", i, fn, n)
+			}
+		}
+	}
+
+	ins := nurbsSphere(t)
+	if err := ins.InsertKnotU(0.3, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := ins.InsertKnotV(0.6, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(ins.Points) != 6 || len(ins.Points[0]) != 11 {
+		t.Errorf("expected 6 x 11 points, got %d x %d", len(ins.Points), len(ins.Points[0]))
+	}
+	sameSurface(t, "insert", s, ins)
+
+	el := nurbsSphere(t)
+	if err := el.ElevateDegreeU(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := el.ElevateDegreeV(2); err != nil {
+		t.Fatal(err)
+	}
+	if el.DegreeU != 3 || el.DegreeV != 4 {
+		t.Errorf("expected degrees 3 and 4, got %d and %d", el.DegreeU, el.DegreeV)
+	}
+	sameSurface(t, "elevate", s, el)
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func totalArea(polys []m.Polygon2D) float64 {
+	area := 0.0
+	for _, p := range polys {
+		area += p.Area()
+	}
+
+	return area
+}
+
+func TestPolygonBoolean(t *testing.T) {
+	a := []m.Polygon2D{{Outer: square(0, 0, 2)}}
+	b := []m.Polygon2D{{Outer: m.ReversePolygon(square(1, 1, 2))}}
+	adjacent := []m.Polygon2D{{Outer: square(2, 0, 2)}}
+	frame := []m.Polygon2D{{Outer: square(-1, -1, 4), Holes: [][]m.Vec2D{square(0, 0, 2)}}}
+
+	tests := []struct {
+		name   string
+		a, b   []m.Polygon2D
+		op     m.BooleanOp
+		area   float64
+		pieces int
+	}{
+		{"Union Overlap", a, b, m.BoolUnion, 7, 1},
+		{"Intersection Overlap", a, b, m.BoolIntersection, 1, 1},
+		{"Difference Overlap", a, b, m.BoolDifference, 3, 1},
+		{"Xor Overlap", a, b, m.BoolXor, 6, 2},
+		{"Union Adjacent", a, adjacent, m.BoolUnion, 8, 1},
+		{"Intersection Adjacent", a, adjacent, m.BoolIntersection, 0, 0},
+		{"Difference Adjacent", a, adjacent, m.BoolDifference, 4, 1},
+		{"Union Identical", a, a, m.BoolUnion, 4, 1},
+		{"Difference Identical", a, a, m.BoolDifference, 0, 0},
+		{"Xor Identical", a, a, m.BoolXor, 0, 0},
+		{"Union Fills Hole", frame, a, m.BoolUnion, 16, 1},
+		{"Difference Cuts Hole", frame, b, m.BoolDifference, 9, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := m.PolygonBoolean(tt.a, tt.b, tt.op)
+
+			if len(res) != tt.pieces {
+				t.Errorf("Expected %v polygons, Got %v", tt.pieces, res)
+			}
+
+			if area := totalArea(res); math.Abs(area-tt.area) > 1e-9 {
+				t.Errorf("Expected area %v, Got %v", tt.area, area)
+			}
+
+			for _, p := range res {
+				if !m.IsPolygonCCW(p.Outer) {
+					t.Errorf("Expected ccw outer, Got %v", p.Outer)
+				}
+				for _, h := range p.Holes {
+					if m.IsPolygonCCW(h) {
+						t.Errorf("Expected cw hole, Got %v", h)
+					}
+				}
+			}
+		})
+	}
+
+	hole := m.PolygonDifference(frame, []m.Polygon2D{{Outer: square(-2, 0.5, 1)}})
+	if len(hole) != 1 || len(hole[0].Holes) != 1 {
+		t.Errorf("Expected one polygon with one hole, Got %v", hole)
+	}
+}
+
+func TestClipPolygonConvex(t *testing.T) {
+	window := square(0, 0, 2)
+
+	tests := []struct {
+		name    string
+		subject []m.Vec2D
+		area    float64
+	}{
+		{"Overlap", square(1, 1, 2), 1},
+		{"Inside", square(0.5, 0.5, 1), 1},
+		{"Outside", square(3, 3, 1), 0},
+		{"Contains Window", m.ReversePolygon(square(-1, -1, 4)), 4},
+		{"Shared Edge", square(2, 0, 2), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := m.ClipPolygonConvex(tt.subject, window)
+			if area := m.PolygonArea(res); math.Abs(area-tt.area) > 1e-9 {
+				t.Errorf("Expected area %v, Got %v (%v)", tt.area, area, res)
+			}
+		})
+	}
+}
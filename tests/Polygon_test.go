@@ -0,0 +1,116 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func square(x, y, size float64) []m.Vec2D {
+	return []m.Vec2D{{X: x, Y: y}, {X: x + size, Y: y}, {X: x + size, Y: y + size}, {X: x, Y: y + size}}
+}
+
+func TestConvexHull2D(t *testing.T) {
+	points := []m.Vec2D{
+		{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 0, Y: 2},
+		{X: 1, Y: 0}, {X: 0.5, Y: 1.5}, {X: 2, Y: 1},
+	}
+
+	hull := m.ConvexHull2D(points)
+	if len(hull) != 4 {
+		t.Fatalf("Expected 4 hull points, Got %v", hull)
+	}
+
+	if !m.IsPolygonCCW(hull) || m.PolygonArea(hull) != 4 {
+		t.Errorf("Expected ccw hull with area 4, Got %v", hull)
+	}
+}
+
+func TestPolygonAreaCentroid(t *testing.T) {
+	poly := m.ReversePolygon(square(1, 1, 2))
+
+	if m.PolygonSignedArea(poly) != -4 {
+		t.Errorf("Expected signed area -4, Got %v", m.PolygonSignedArea(poly))
+	}
+
+	c, err := m.PolygonCentroid(poly)
+	if err != nil || c.IsNotEqual(m.Vec2D{X: 2, Y: 2}) {
+		t.Errorf("Expected centroid {2 2}, Got %v (%v)", c, err)
+	}
+
+	line := []m.Vec2D{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}}
+	if _, err := m.PolygonCentroid(line); err != m.ErrDegeneratePolygon {
+		t.Errorf("Expected %v, Got %v", m.ErrDegeneratePolygon, err)
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	// U shape
+	poly := []m.Vec2D{{X: 0, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 3}, {X: 2, Y: 3}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 3}, {X: 0, Y: 3}}
+
+	tests := []struct {
+		name   string
+		p      m.Vec2D
+		inside bool
+	}{
+		{"Left Arm", m.Vec2D{X: 0.5, Y: 2}, true},
+		{"Notch", m.Vec2D{X: 1.5, Y: 2}, false},
+		{"Base", m.Vec2D{X: 1.5, Y: 0.5}, true},
+		{"Outside", m.Vec2D{X: 4, Y: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m.PointInPolygon(tt.p, poly) != tt.inside {
+				t.Errorf("Expected %v for %v", tt.inside, tt.p)
+			}
+		})
+	}
+}
+
+func TestTriangulatePolygon(t *testing.T) {
+	outer := square(0, 0, 10)
+	holes := [][]m.Vec2D{square(2, 2, 2), m.ReversePolygon(square(6, 5, 3))}
+
+	tris, err := m.TriangulatePolygon(outer, holes)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	verts := append([]m.Vec2D{}, outer...)
+	for _, h := range holes {
+		verts = append(verts, h...)
+	}
+
+	area := 0.0
+	for _, tri := range tris {
+		tr := m.Triangle2D{A: verts[tri[0]], B: verts[tri[1]], C: verts[tri[2]]}
+		if !tr.IsCCW() {
+			t.Errorf("Expected ccw triangle, Got %v", tr)
+		}
+		area += tr.Area()
+	}
+
+	if math.Abs(area-87) > 1e-9 {
+		t.Errorf("Expected area 87, Got %v", area)
+	}
+}
+
+func TestSimplifyAndOffset(t *testing.T) {
+	poly := []m.Vec2D{{X: 0, Y: 0}, {X: 1, Y: 0.01}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 1, Y: 2.01}, {X: 0, Y: 2}}
+
+	simple := m.SimplifyPolygon(poly, 0.1)
+	if len(simple) != 4 {
+		t.Errorf("Expected 4 points, Got %v", simple)
+	}
+
+	grown := m.OffsetPolygon(square(0, 0, 2), 1, 4)
+	if math.Abs(m.PolygonArea(grown)-16) > 1e-9 {
+		t.Errorf("Expected area 16, Got %v", m.PolygonArea(grown))
+	}
+
+	shrunk := m.OffsetPolygon(m.ReversePolygon(square(0, 0, 4)), -1, 4)
+	if math.Abs(m.PolygonArea(shrunk)-4) > 1e-9 {
+		t.Errorf("Expected area 4, Got %v", m.PolygonArea(shrunk))
+	}
+}
@@ -0,0 +1,193 @@
+package tests
+
+import (
+	m "golem"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomRect(r *rand.Rand, size float64) m.AABB2D {
+	c := m.Vec2D{X: r.Float64() * size, Y: r.Float64() * size}
+	h := m.Vec2D{X: r.Float64() + 0.1, Y: r.Float64() + 0.1}
+
+	return m.NewAABB2D(c, h)
+}
+
+func nearestRects(boxes map[int]m.AABB2D, p m.Vec2D, k int) []int {
+	ids := make([]int, 0, len(boxes))
+	dist := make(map[int]float64, len(boxes))
+	for id, b := range boxes {
+		ids = append(ids, id)
+		dist[id] = p.Dist(b.ClosestPoint(p))
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if dist[ids[i]] != dist[ids[j]] {
+			return dist[ids[i]] < dist[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	return ids[:min(k, len(ids))]
+}
+
+func TestQuadtree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	world := m.AABB2D{Max: m.Vec2D{X: 50, Y: 50}}
+	q, err := m.NewQuadtree(world, 6, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boxes := make(map[int]m.AABB2D)
+	for i := 0; i < 300; i++ {
+		// a few items fall outside the world bounds
+		b := randomRect(r, 60)
+		if err := q.Insert(i, b); err != nil {
+			t.Fatal(err)
+		}
+		boxes[i] = b
+	}
+
+	if err := q.Insert(0, boxes[0]); err != m.ErrDuplicateID {
+		t.Errorf("expected ErrDuplicateID, got %v", err)
+	}
+
+	for step := 0; step < 3; step++ {
+		for i := 0; i < 300; i += 3 {
+			if _, ok := boxes[i]; !ok {
+				continue
+			}
+
+			b := randomRect(r, 60)
+			if err := q.Move(i, b); err != nil {
+				t.Fatal(err)
+			}
+			boxes[i] = b
+		}
+
+		for i := step; i < 300; i += 7 {
+			if _, ok := boxes[i]; ok {
+				if err := q.Remove(i); err != nil {
+					t.Fatal(err)
+				}
+				delete(boxes, i)
+			}
+		}
+
+		if q.Len() != len(boxes) {
+			t.Fatalf("expected %d items, got %d", len(boxes), q.Len())
+		}
+
+		for n := 0; n < 20; n++ {
+			query := randomRect(r, 50)
+			circle := m.Circle{Center: query.Center(), Radius: 4}
+
+			var expRect, expCircle []int
+			for id, b := range boxes {
+				if b.Intersects(query) {
+					expRect = append(expRect, id)
+				}
+				if b.IntersectsCircle(circle) {
+					expCircle = append(expCircle, id)
+				}
+			}
+
+			if got := q.QueryRect(query); !sameIDs(got, expRect) {
+				t.Errorf("QueryRect: expected %v, got %v", expRect, got)
+			}
+			if got := q.QueryCircle(circle); !sameIDs(got, expCircle) {
+				t.Errorf("QueryCircle: expected %v, got %v", expCircle, got)
+			}
+
+			exp := nearestRects(boxes, circle.Center, 5)
+			got := q.KNearest(circle.Center, 5)
+			if len(got) != len(exp) {
+				t.Fatalf("KNearest: expected %v, got %v", exp, got)
+			}
+			for i := range exp {
+				if got[i] != exp[i] {
+					t.Errorf("KNearest: expected %v, got %v", exp, got)
+					break
+				}
+			}
+		}
+	}
+
+	if err := q.Remove(-1); err != m.ErrUnknownID {
+		t.Errorf("expected ErrUnknownID, got %v", err)
+	}
+}
+
+func TestOctree(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	world := m.AABB3D{Max: m.Vec3D{X: 50, Y: 50, Z: 50}}
+	o, err := m.NewOctree(world, 5, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boxes := make(map[int]m.AABB3D)
+	for i := 0; i < 300; i++ {
+		b := randomBox(r, 60)
+		if err := o.Insert(i, b); err != nil {
+			t.Fatal(err)
+		}
+		boxes[i] = b
+	}
+
+	for i := 0; i < 300; i += 2 {
+		b := randomBox(r, 60)
+		if err := o.Move(i, b); err != nil {
+			t.Fatal(err)
+		}
+		boxes[i] = b
+	}
+
+	for i := 0; i < 300; i += 5 {
+		if err := o.Remove(i); err != nil {
+			t.Fatal(err)
+		}
+		delete(boxes, i)
+	}
+
+	for n := 0; n < 20; n++ {
+		query := randomBox(r, 50)
+		sphere := m.Sphere{Center: query.Center(), Radius: 4}
+
+		var expBox, expSphere []int
+		for id, b := range boxes {
+			if b.Intersects(query) {
+				expBox = append(expBox, id)
+			}
+			if b.IntersectsSphere(sphere) {
+				expSphere = append(expSphere, id)
+			}
+		}
+
+		if got := o.QueryAABB(query); !sameIDs(got, expBox) {
+			t.Errorf("QueryAABB: expected %v, got %v", expBox, got)
+		}
+		if got := o.QuerySphere(sphere); !sameIDs(got, expSphere) {
+			t.Errorf("QuerySphere: expected %v, got %v", expSphere, got)
+		}
+
+		got := o.KNearest(sphere.Center, 3)
+		if len(got) != 3 {
+			t.Fatalf("KNearest: expected 3 ids, got %v", got)
+		}
+
+		// nothing outside the result may be closer than the last hit
+		c := sphere.Center
+		b := boxes[got[2]]
+		worst := c.Dist(b.ClosestPoint(c))
+		for id, b := range boxes {
+			if id != got[0] && id != got[1] && id != got[2] && c.Dist(b.ClosestPoint(c)) < worst {
+				t.Errorf("KNearest: %d is closer than %v", id, got)
+			}
+		}
+	}
+}
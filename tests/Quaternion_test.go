@@ -0,0 +1,95 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func TestQuaternionDot(t *testing.T) {
+	tests := []struct {
+		name string
+		q1   m.Quaternion
+		q2   m.Quaternion
+		res  float64
+	}{
+		{"Identity Case", m.Quaternion{W: 1}, m.Quaternion{W: 1}, 1},
+		{"Only W Case", m.Quaternion{W: 2, X: 1}, m.Quaternion{W: 3, Y: 1}, 6},
+		{"All Parts Case", m.Quaternion{W: 1, X: 2, Y: 3, Z: 4}, m.Quaternion{W: -1, X: 1, Y: -2, Z: 0.5}, -3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dot := tt.q1.Dot(tt.q2)
+			if dot != tt.res {
+				t.Errorf("Expected %v, Got %v", tt.res, dot)
+			}
+		})
+	}
+}
+
+func TestQuaternionMagnitude(t *testing.T) {
+	tests := []struct {
+		name string
+		q    m.Quaternion
+		mag  float64
+	}{
+		{"Zero Case", m.Quaternion{}, 0},
+		{"Only W Case", m.Quaternion{W: -3}, 3},
+		{"All Parts Case", m.Quaternion{W: 1, X: 2, Y: 3, Z: 4}, math.Sqrt(30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mag := tt.q.Magnitude()
+			if mag != tt.mag || math.Abs(tt.q.Dot(tt.q)-mag*mag) > 1e-12 {
+				t.Errorf("Expected %v, Got %v", tt.mag, mag)
+			}
+		})
+	}
+}
+
+func TestQuaternionNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		q    m.Quaternion
+		mag  float64
+		res  m.Quaternion
+	}{
+		{"Only W Case", m.Quaternion{W: 2}, 2, m.Quaternion{W: 1}},
+		{"W And X Case", m.Quaternion{W: 3, X: 4}, 5, m.Quaternion{W: 0.6, X: 0.8}},
+		{"All Parts Case", m.Quaternion{W: 1, X: 1, Y: 1, Z: 1}, 2, m.Quaternion{W: 0.5, X: 0.5, Y: 0.5, Z: 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mag, err := tt.q.Normalize()
+			if err != nil || mag != tt.mag || !tt.q.IsEqual(tt.res) {
+				t.Errorf("Expected %v with magnitude %v, Got %v with magnitude %v", tt.res, tt.mag, tt.q, mag)
+			}
+		})
+	}
+
+	if _, err := (&m.Quaternion{}).Normalize(); err != m.ErrZeroMag {
+		t.Errorf("Expected ErrZeroMag, Got %v", err)
+	}
+}
+
+func TestQuaternionInverse(t *testing.T) {
+	tests := []struct {
+		name string
+		q    m.Quaternion
+	}{
+		{"Unit Case", m.Quaternion{W: 0.6, X: 0.8}},
+		{"Scaled Case", m.Quaternion{W: 2, X: -1, Y: 0.5, Z: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := tt.q.InverseQt()
+			if id := tt.q.MultiplyQt(inv); err != nil || !id.IsEqual(m.Quaternion{W: 1}) {
+				t.Errorf("Expected the identity, Got %v", id)
+			}
+		})
+	}
+}
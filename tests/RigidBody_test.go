@@ -0,0 +1,191 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func boxMesh(half, offset m.Vec3D, rot m.Quaternion) *m.TriangleMesh {
+	mesh := &m.TriangleMesh{
+		Faces: [][3]int{
+			{0, 2, 1}, {0, 3, 2}, // -z
+			{4, 5, 6}, {4, 6, 7}, // +z
+			{0, 1, 5}, {0, 5, 4}, // -y
+			{3, 7, 6}, {3, 6, 2}, // +y
+			{0, 4, 7}, {0, 7, 3}, // -x
+			{1, 2, 6}, {1, 6, 5}, // +x
+		},
+	}
+
+	r := rot.ToRotMat3D()
+	for _, z := range []float64{-1, 1} {
+		for _, c := range [][2]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+			p := m.Vec3D{X: c[0] * half.X, Y: c[1] * half.Y, Z: z * half.Z}
+			mesh.Vertices = append(mesh.Vertices, r.RotateVec3D(p).AddVec(offset))
+		}
+	}
+
+	return mesh
+}
+
+func nearMat3D(a, b m.Mat3D, eps float64) bool {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if math.Abs(a[i][j]-b[i][j]) > eps {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func TestInertia(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	half := m.Vec3D{X: 1, Y: 0.5, Z: 2}
+	box := m.BoxInertia(8, half)
+
+	// a unit density box of volume 8 has mass 8
+	rot := randomRotation(r)
+	offset := m.Vec3D{X: 3, Y: -1, Z: 2}
+	mass, center, inertia, err := m.MeshInertia(boxMesh(half, offset, rot), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(mass-8) > 1e-9 || !nearVec3D(center, offset, 1e-9) {
+		t.Errorf("expected mass 8 at %v, got %f at %v", offset, mass, center)
+	}
+	if exp := m.RotateInertia(box, rot.ToRotMat3D()); !nearMat3D(inertia, exp, 1e-9) {
+		t.Errorf("mesh inertia %v, expected %v", inertia, exp)
+	}
+
+	// the origin of the mesh does not matter, only its shape
+	shifted := m.ShiftInertia(box, 8, offset)
+	if _, _, i, _ := m.MeshInertia(boxMesh(half, m.Vec3D{}, m.Quaternion{W: 1}), 1); !nearMat3D(i, box, 1e-9) {
+		t.Errorf("centered mesh inertia %v, expected %v", i, box)
+	}
+	if math.Abs(shifted[0][0]-box[0][0]-8*(offset.Y*offset.Y+offset.Z*offset.Z)) > 1e-12 || math.Abs(shifted[0][1]+8*offset.X*offset.Y) > 1e-12 {
+		t.Errorf("parallel axis gives %v", shifted)
+	}
+
+	// a capsule without a cylinder is a sphere
+	if c, s := m.CapsuleInertia(3, 2, 0), m.SphereInertia(3, 2); !nearMat3D(c, s, 1e-12) {
+		t.Errorf("capsule %v, sphere %v", c, s)
+	}
+	c := m.CapsuleInertia(3, 0.5, 2)
+	if c[1][1] >= c[0][0] || c[0][0] != c[2][2] {
+		t.Errorf("expected a long capsule to turn easiest about Y, got %v", c)
+	}
+
+	flat := &m.TriangleMesh{Vertices: []m.Vec3D{{}, {X: 1}, {Y: 1}}, Faces: [][3]int{{0, 1, 2}, {0, 2, 1}}}
+	if _, _, _, err := m.MeshInertia(flat, 1); err != m.ErrZeroVolume {
+		t.Errorf("expected ErrZeroVolume, got %v", err)
+	}
+}
+
+func TestRigidBody(t *testing.T) {
+	g := m.Vec3D{Y: -9.8}
+	v0 := m.Vec3D{X: 2, Y: 5}
+	dt, steps := 1.0/60, 120
+
+	euler, _ := m.NewRigidBody(2, m.SphereInertia(2, 1))
+	rk4, _ := m.NewRigidBody(2, m.SphereInertia(2, 1))
+	euler.LinearVelocity, rk4.LinearVelocity = v0, v0
+
+	for i := 0; i < steps; i++ {
+		euler.ApplyForce(g.ScalerMulVec(2))
+		euler.IntegrateEuler(dt)
+		rk4.ApplyForce(g.ScalerMulVec(2))
+		rk4.IntegrateRK4(dt)
+	}
+
+	// RK4 is exact under constant acceleration, semi implicit Euler lags
+	// half a step of velocity each step
+	tt := dt * float64(steps)
+	exact := v0.ScalerMulVec(tt).AddVec(g.ScalerMulVec(tt * tt / 2))
+	if !nearVec3D(rk4.Position, exact, 1e-9) {
+		t.Errorf("rk4 at %v, expected %v", rk4.Position, exact)
+	}
+	if exp := exact.AddVec(g.ScalerMulVec(tt * dt / 2)); !nearVec3D(euler.Position, exp, 1e-9) {
+		t.Errorf("euler at %v, expected %v", euler.Position, exp)
+	}
+
+	// spinning about Z at a constant rate
+	spin, _ := m.NewRigidBody(1, m.SphereInertia(1, 1))
+	spin.AngularVelocity = m.Vec3D{Z: math.Pi}
+	for i := 0; i < 60; i++ {
+		spin.IntegrateRK4(dt)
+	}
+	if p := spin.ToWorld(m.Vec3D{X: 1}); !nearVec3D(p, m.Vec3D{X: -1}, 1e-6) {
+		t.Errorf("expected a half turn, got %v", p)
+	}
+	if p := spin.ToLocal(spin.ToWorld(m.Vec3D{X: 1, Y: 2, Z: 3})); !nearVec3D(p, m.Vec3D{X: 1, Y: 2, Z: 3}, 1e-12) {
+		t.Errorf("local and world do not round trip, got %v", p)
+	}
+
+	// a torque free box tumbling about an off axis keeps its momentum
+	for _, integrate := range []func(*m.RigidBody, float64){(*m.RigidBody).IntegrateEuler, (*m.RigidBody).IntegrateRK4} {
+		box, _ := m.NewRigidBody(3, m.BoxInertia(3, m.Vec3D{X: 1, Y: 0.3, Z: 0.6}))
+		box.AngularVelocity = m.Vec3D{X: 0.2, Y: 3, Z: 0.1}
+		l0, e0 := box.AngularMomentum(), box.KineticEnergy()
+
+		for i := 0; i < 600; i++ {
+			integrate(box, 1.0/240)
+		}
+
+		l, e := box.AngularMomentum(), box.KineticEnergy()
+		if d := l.SubVec(l0); d.Length() > 0.02*l0.Length() {
+			t.Errorf("angular momentum drifts from %v to %v", l0, l)
+		}
+		if math.Abs(e-e0) > 0.02*e0 {
+			t.Errorf("energy drifts from %f to %f", e0, e)
+		}
+	}
+
+	// Euler at 60 Hz spinning near the unstable intermediate axis, the
+	// gyroscopic term must not feed energy in as the body flips over
+	box, _ := m.NewRigidBody(3, m.BoxInertia(3, m.Vec3D{X: 1, Y: 0.5, Z: 0.2}))
+	box.AngularVelocity = m.Vec3D{X: 0.1, Y: 3, Z: 0.1}
+	l0, e0 := box.AngularMomentum(), box.KineticEnergy()
+	for i := 0; i < 60*40; i++ {
+		box.IntegrateEuler(1.0 / 60)
+	}
+	if l := box.AngularMomentum(); math.Abs(l.Length()-l0.Length()) > 0.02*l0.Length() {
+		t.Errorf("angular momentum drifts from %f to %f", l0.Length(), l.Length())
+	}
+	if e := box.KineticEnergy(); math.Abs(e-e0) > 0.02*e0 {
+		t.Errorf("energy drifts from %f to %f", e0, e)
+	}
+
+	// an off center push spins the body
+	b, _ := m.NewRigidBody(1, m.SphereInertia(1, 1))
+	b.ApplyForceAtPoint(m.Vec3D{Y: 1}, m.Vec3D{X: 1})
+	if tq := b.Torque(); !nearVec3D(tq, m.Vec3D{Z: 1}, 1e-12) {
+		t.Errorf("expected torque along Z, got %v", tq)
+	}
+	b.ApplyImpulseAtPoint(m.Vec3D{Y: 1}, m.Vec3D{X: 1})
+	if w := b.AngularVelocity; !nearVec3D(w, m.Vec3D{Z: 2.5}, 1e-12) {
+		t.Errorf("expected spin 2.5 about Z, got %v", w)
+	}
+	if v := b.VelocityAt(m.Vec3D{X: 1}); !nearVec3D(v, m.Vec3D{Y: 3.5}, 1e-12) {
+		t.Errorf("expected the point to move at 3.5, got %v", v)
+	}
+
+	// kinematic bodies ignore forces but keep their velocity
+	k, _ := m.NewRigidBody(0, m.Mat3D{})
+	k.LinearVelocity = m.Vec3D{X: 1}
+	k.ApplyForce(m.Vec3D{Y: 100})
+	k.IntegrateRK4(1)
+	if !k.IsKinematic() || !nearVec3D(k.Position, m.Vec3D{X: 1}, 1e-12) {
+		t.Errorf("kinematic body at %v", k.Position)
+	}
+
+	if _, err := m.NewRigidBody(-1, m.Mat3D{}); err != m.ErrInvalidMass {
+		t.Errorf("expected ErrInvalidMass, got %v", err)
+	}
+	if _, err := m.NewRigidBody(1, m.Mat3D{}); err != m.ErrZeroDet {
+		t.Errorf("expected ErrZeroDet, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomTransform(r *rand.Rand) m.Transform {
+	s := r.Float64() + 0.5
+	return m.Transform{
+		Translation: randomCloud(r, 1, 4)[0],
+		Rotation:    randomRotation(r),
+		Scale:       m.Vec3D{X: s, Y: s, Z: s},
+	}
+}
+
+func sameTransform(a, b m.Transform, eps float64) bool {
+	return nearVec3D(a.Translation, b.Translation, eps) && nearVec3D(a.Scale, b.Scale, eps) &&
+		math.Abs(math.Abs(a.Rotation.Dot(b.Rotation))-1) < eps
+}
+
+func TestSkeleton(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// spine with two arms
+	parents := []int{-1, 0, 1, 1, 3, 1, 5}
+	bones := make([]m.Bone, len(parents))
+	for i, p := range parents {
+		bones[i] = m.Bone{Parent: p, Bind: randomTransform(r)}
+	}
+	bones[3].Name = "leftArm"
+
+	s, err := m.NewSkeleton(bones)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	palette, err := s.SkinningPalette(s.BindPose(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range palette {
+		for a := 0; a < 4; a++ {
+			for b := 0; b < 4; b++ {
+				exp := 0.0
+				if a == b {
+					exp = 1
+				}
+				if math.Abs(p[a][b]-exp) > 1e-9 {
+					t.Fatalf("bind pose palette %d is not the identity: %v", i, p)
+				}
+			}
+		}
+	}
+
+	pose := make(m.Pose, len(bones))
+	for i := range pose {
+		pose[i] = randomTransform(r)
+	}
+
+	mats, _ := s.GlobalMatrices(pose, nil)
+	globals, _ := s.GlobalTransforms(pose, nil)
+	palette, _ = s.SkinningPalette(pose, palette)
+
+	for i := range bones {
+		p := randomCloud(r, 1, 2)[0]
+		if a, b := mats[i].TransformPoint(p), globals[i].TransformPoint(p); !nearVec3D(a, b, 1e-9) {
+			t.Errorf("bone %d: matrix gives %v, transform gives %v", i, a, b)
+		}
+
+		// a vertex bound rigidly to the bone follows it
+		bindGlobal := s.InverseBind[i].InverseMat()
+		v := bindGlobal.TransformPoint(p)
+		if a, b := palette[i].TransformPoint(v), mats[i].TransformPoint(p); !nearVec3D(a, b, 1e-9) {
+			t.Errorf("bone %d: skinned vertex at %v, expected %v", i, a, b)
+		}
+	}
+
+	if i, ok := s.BoneIndex("leftArm"); !ok || i != 3 {
+		t.Errorf("expected leftArm at 3, got %d", i)
+	}
+
+	bones[2].Parent = 4
+	if _, err := m.NewSkeleton(bones); err != m.ErrInvalidParent {
+		t.Errorf("expected ErrInvalidParent, got %v", err)
+	}
+	if _, err := s.GlobalMatrices(pose[1:], nil); err != m.ErrInvalidLen {
+		t.Errorf("expected ErrInvalidLen, got %v", err)
+	}
+}
+
+func TestPoseBlending(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	parents := []int{-1, 0, 1, 1, 3}
+	bones := make([]m.Bone, len(parents))
+	for i, p := range parents {
+		bones[i] = m.Bone{Parent: p, Bind: randomTransform(r)}
+	}
+	s, _ := m.NewSkeleton(bones)
+
+	a, b, c := make(m.Pose, 5), make(m.Pose, 5), make(m.Pose, 5)
+	for i := range a {
+		a[i], b[i], c[i] = randomTransform(r), randomTransform(r), randomTransform(r)
+	}
+
+	// flipping the sign of a rotation must not change the blend
+	flipped := append(m.Pose(nil), b...)
+	for i := range flipped {
+		flipped[i].Rotation.Negate()
+	}
+
+	// weights outside [0, 1] clamp every channel alike
+	for _, w := range []float64{-0.5, 0, 0.3, 1, 1.5} {
+		n, _ := m.BlendPoses(a, b, w, nil)
+		f, _ := m.BlendPoses(a, flipped, w, nil)
+		sl, _ := m.BlendPosesSlerp(a, flipped, w, nil)
+
+		for i := range n {
+			if !sameTransform(n[i], f[i], 1e-9) {
+				t.Errorf("weight %f: sign of the rotation changes bone %d", w, i)
+			}
+			if w <= 0 && !sameTransform(n[i], a[i], 1e-9) || w >= 1 && !sameTransform(sl[i], b[i], 1e-9) {
+				t.Errorf("weight %f: bone %d does not match the end pose", w, i)
+			}
+		}
+	}
+
+	// two equal weights are a half blend
+	half, _ := m.BlendPoses(a, flipped, 0.5, nil)
+	avg, err := m.BlendPosesWeighted([]m.Pose{a, flipped}, []float64{2, 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range half {
+		if !sameTransform(half[i], avg[i], 1e-9) {
+			t.Errorf("bone %d: weighted blend differs from the half blend", i)
+		}
+	}
+	if _, err := m.BlendPosesWeighted([]m.Pose{a, b}, []float64{0, 0}, nil); err != m.ErrZeroDiv {
+		t.Errorf("expected ErrZeroDiv, got %v", err)
+	}
+
+	// only the arm below bone 3 takes the layer
+	mask := s.Mask(3)
+	if exp := []float64{0, 0, 0, 1, 1}; !sameFloats(mask, exp) {
+		t.Fatalf("expected mask %v, got %v", exp, mask)
+	}
+	layered, _ := m.BlendPosesMasked(a, b, mask, 1, nil)
+	for i := range layered {
+		exp := a[i]
+		if mask[i] == 1 {
+			exp = b[i]
+		}
+		if !sameTransform(layered[i], exp, 1e-9) {
+			t.Errorf("bone %d: masked blend took the wrong pose", i)
+		}
+	}
+
+	// adding the difference of a pose to its own reference gives it back
+	added, _ := m.AddPoses(c, a, c, 1, nil)
+	none, _ := m.AddPoses(a, b, c, 0, nil)
+	for i := range added {
+		if !sameTransform(added[i], a[i], 1e-9) {
+			t.Errorf("bone %d: full additive gives %v, expected %v", i, added[i], a[i])
+		}
+		if !sameTransform(none[i], a[i], 1e-9) {
+			t.Errorf("bone %d: zero weight changes the base", i)
+		}
+	}
+}
+
+func sameFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
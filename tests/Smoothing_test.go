@@ -0,0 +1,123 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func TestSmoothDamp(t *testing.T) {
+	// the same second at 30 and 144 frames per second
+	run := func(fps int) (float64, float64) {
+		x, v := 0.0, 0.0
+		dt := 1 / float64(fps)
+
+		for i := 0; i < fps; i++ {
+			x = m.SmoothDamp(x, 10, &v, 0.3, 0, dt)
+			if x > 10 {
+				t.Fatalf("%d fps: overshot to %f", fps, x)
+			}
+		}
+		return x, v
+	}
+
+	x30, v30 := run(30)
+	x144, v144 := run(144)
+	if math.Abs(x30-x144) > 1e-2 || math.Abs(v30-v144) > 1e-1 {
+		t.Errorf("30 fps gives %f at %f/s, 144 fps gives %f at %f/s", x30, v30, x144, v144)
+	}
+	if x144 < 9.9 {
+		t.Errorf("expected to be nearly there after a second, got %f", x144)
+	}
+
+	// limited speed
+	x, v := 0.0, 0.0
+	for i := 0; i < 60; i++ {
+		prev := x
+		x = m.SmoothDamp(x, 100, &v, 0.5, 20, 1.0/60)
+		if (x-prev)*60 > 20+1e-9 {
+			t.Fatalf("moved at %f/s", (x-prev)*60)
+		}
+	}
+
+	p, pv := m.Vec3D{}, m.Vec3D{}
+	target := m.Vec3D{X: 3, Y: -4, Z: 12}
+	for i := 0; i < 120; i++ {
+		p = m.SmoothDampVec3D(p, target, &pv, 0.2, 0, 1.0/60)
+
+		// stays on the segment towards the target
+		if c := p.CrossV(target); c.Length() > 1e-9 || p.Length() > target.Length()+1e-12 {
+			t.Fatalf("left the straight path at %v", p)
+		}
+	}
+	if !nearVec3D(p, target, 1e-3) {
+		t.Errorf("expected to reach %v, got %v", target, p)
+	}
+
+	p2, pv2 := m.Vec2D{X: 1}, m.Vec2D{}
+	for i := 0; i < 120; i++ {
+		p2 = m.SmoothDampVec2D(p2, m.Vec2D{Y: 2}, &pv2, 0.2, 0, 1.0/60)
+	}
+	if !nearVec2D(p2, m.Vec2D{Y: 2}, 1e-3) {
+		t.Errorf("expected to reach (0, 2), got %v", p2)
+	}
+
+	// the short way from 170 to -170 degrees crosses 180
+	a, av := m.ToRadians(170), 0.0
+	for i := 0; i < 120; i++ {
+		a = m.SmoothDampAngle(a, m.ToRadians(-170), &av, 0.2, 0, 1.0/60)
+		if math.Abs(a) < m.ToRadians(170)-1e-9 {
+			t.Fatalf("went the long way through %f degrees", m.ToDegrees(a))
+		}
+	}
+	if math.Abs(a-m.ToRadians(-170)) > 1e-3 {
+		t.Errorf("expected -170 degrees, got %f", m.ToDegrees(a))
+	}
+
+	q, qv := m.Quaternion{W: 1}, m.Quaternion{}
+	goal := m.Quaternion{W: math.Cos(1), X: math.Sin(1)}
+	for i := 0; i < 120; i++ {
+		var err error
+		if q, err = m.SmoothDampQuaternion(q, goal, &qv, 0.2, 0, 1.0/60); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(q.Magnitude()-1) > 1e-12 || math.Abs(q.Dot(qv)) > 1e-9 {
+			t.Fatalf("expected a unit rotation with tangent velocity, got %v and %v", q, qv)
+		}
+	}
+	if math.Abs(q.Dot(goal)) < 1-1e-6 {
+		t.Errorf("expected to reach %v, got %v", goal, q)
+	}
+}
+
+func TestDamp(t *testing.T) {
+	x, y := 0.0, 0.0
+	for i := 0; i < 10; i++ {
+		x = m.Damp(x, 5, 3, 0.1)
+	}
+	y = m.Damp(y, 5, 3, 1)
+	if math.Abs(x-y) > 1e-12 {
+		t.Errorf("ten steps give %f, one step gives %f", x, y)
+	}
+
+	v := m.DampVec3D(m.Vec3D{}, m.Vec3D{X: 2}, math.Ln2, 1)
+	if !nearVec3D(v, m.Vec3D{X: 1}, 1e-12) {
+		t.Errorf("expected half way, got %v", v)
+	}
+
+	if f := m.DampFactor(0.25, 0.25); math.Abs(f-0.5) > 1e-12 {
+		t.Errorf("expected 0.5 after one half life, got %f", f)
+	}
+
+	if a := m.DampAngle(m.ToRadians(170), m.ToRadians(-170), math.Ln2, 1); math.Abs(math.Abs(m.ToDegrees(a))-180) > 1e-9 {
+		t.Errorf("expected 180 degrees, got %f", m.ToDegrees(a))
+	}
+
+	q, err := m.DampQuaternion(m.Quaternion{W: 1}, m.Quaternion{W: math.Cos(1), Z: math.Sin(1)}, math.Ln2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := (m.Quaternion{W: math.Cos(0.5), Z: math.Sin(0.5)}); math.Abs(q.Dot(exp)-1) > 1e-9 {
+		t.Errorf("expected %v, got %v", exp, q)
+	}
+}
@@ -0,0 +1,237 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func sphereBody(mass, radius float64, pos m.Vec3D) *m.RigidBody {
+	b, _ := m.NewRigidBody(mass, m.SphereInertia(mass, radius))
+	b.Position = pos
+	return b
+}
+
+// one step of gravity, solve and move
+func physicsStep(s *m.Solver, bodies []*m.RigidBody, contacts []m.Contact, joints []m.Joint, dt float64) {
+	for _, b := range bodies {
+		b.ApplyForce(m.Vec3D{Y: -9.8 * b.Mass()})
+		b.IntegrateVelocity(dt)
+	}
+	s.Solve(contacts, joints, dt)
+	for _, b := range bodies {
+		b.IntegratePosition(dt)
+	}
+}
+
+func TestContacts(t *testing.T) {
+	ground, _ := m.NewRigidBody(0, m.Mat3D{})
+	s := m.NewSolver()
+	up := m.Vec3D{Y: 1}
+
+	// a fast approach bounces back at the restitution
+	b := sphereBody(2, 1, m.Vec3D{Y: 1})
+	b.LinearVelocity = m.Vec3D{Y: -4}
+	s.Solve([]m.Contact{{A: ground, B: b, Point: m.Vec3D{}, Normal: up, Restitution: 0.5}}, nil, 1.0/60)
+	if !nearVec3D(b.LinearVelocity, m.Vec3D{Y: 2}, 1e-9) {
+		t.Errorf("expected to bounce at 2, got %v", b.LinearVelocity)
+	}
+
+	// friction takes at most mu times the normal impulse
+	b = sphereBody(1, 1, m.Vec3D{Y: 1})
+	b.LinearVelocity = m.Vec3D{X: 10, Y: -0.5}
+	c := []m.Contact{{A: ground, B: b, Point: b.Position, Normal: up, Friction: 0.4}}
+	s.Solve(c, nil, 1.0/60)
+	if !nearVec3D(b.LinearVelocity, m.Vec3D{X: 9.8}, 1e-9) {
+		t.Errorf("expected to slide on at 9.8, got %v", b.LinearVelocity)
+	}
+	if n, f := c[0].NormalImpulse(), c[0].TangentImpulse(); math.Abs(n-0.5) > 1e-9 || !nearVec3D(f, m.Vec3D{X: -0.2}, 1e-9) {
+		t.Errorf("expected impulses 0.5 and -0.2, got %f and %v", n, f)
+	}
+
+	// a sphere dropped on the ground settles into rolling without slipping,
+	// the same way every time it is replayed
+	var rest [2]m.Vec3D
+	for run := range rest {
+		s = m.NewSolver()
+		b = sphereBody(1, 0.5, m.Vec3D{X: 0.3, Y: 2})
+		b.LinearVelocity = m.Vec3D{X: 1}
+
+		var contacts []m.Contact
+		for i := 0; i < 600; i++ {
+			contacts = contacts[:0]
+			if d := 0.5 - b.Position.Y; d > -0.01 {
+				contacts = append(contacts, m.Contact{
+					A: ground, B: b, Point: b.Position.SubVec(m.Vec3D{Y: 0.5}), Normal: up, Depth: d,
+					Friction: 0.5, Restitution: 0.3,
+				})
+			}
+			physicsStep(s, []*m.RigidBody{b}, contacts, nil, 1.0/60)
+		}
+
+		if math.Abs(b.Position.Y-0.5) > 0.01 || math.Abs(b.LinearVelocity.Y) > 1e-3 {
+			t.Errorf("expected to rest on the ground, at %v moving %v", b.Position, b.LinearVelocity)
+		}
+		if v := b.VelocityAt(b.Position.SubVec(m.Vec3D{Y: 0.5})); v.Length() > 1e-3 {
+			t.Errorf("expected to roll without slipping, contact moves at %v", v)
+		}
+		if v := b.LinearVelocity.X; math.Abs(v-5.0/7) > 1e-3 {
+			t.Errorf("expected to roll on at 5/7, got %f", v)
+		}
+		rest[run] = b.Position
+	}
+	if rest[0] != rest[1] {
+		t.Errorf("replay ends at %v, first run at %v", rest[1], rest[0])
+	}
+}
+
+func TestWarmStarting(t *testing.T) {
+	ground, _ := m.NewRigidBody(0, m.Mat3D{})
+	dt := 1.0 / 60
+
+	// a body resting on two points needs half its weight on each, a single
+	// iteration per step only finds that split when impulses carry over
+	for _, warm := range []bool{false, true} {
+		s := m.NewSolver()
+		s.Iterations, s.WarmStarting = 1, warm
+
+		b := sphereBody(1, 1, m.Vec3D{Y: 1})
+		c := []m.Contact{
+			{A: ground, B: b, Point: m.Vec3D{X: -0.5}, Normal: m.Vec3D{Y: 1}, ID: 0},
+			{A: ground, B: b, Point: m.Vec3D{X: 0.5}, Normal: m.Vec3D{Y: 1}, ID: 1},
+		}
+
+		for i := 0; i < 100; i++ {
+			b.LinearVelocity, b.AngularVelocity = m.Vec3D{}, m.Vec3D{}
+			b.ApplyForce(m.Vec3D{Y: -9.8})
+			b.IntegrateVelocity(dt)
+			s.Solve(c, nil, dt)
+		}
+
+		still := b.LinearVelocity.Length() < 1e-9 && b.AngularVelocity.Length() < 1e-9
+		if warm {
+			for i := range c {
+				if got := c[i].NormalImpulse(); math.Abs(got-4.9*dt) > 1e-9 {
+					t.Errorf("contact %d takes %f, expected %f", i, got, 4.9*dt)
+				}
+			}
+			if !still {
+				t.Errorf("warm started body still moves at %v, %v", b.LinearVelocity, b.AngularVelocity)
+			}
+		} else if still {
+			t.Error("expected one cold iteration to leave the body moving")
+		}
+	}
+}
+
+func TestJoints(t *testing.T) {
+	anchor, _ := m.NewRigidBody(0, m.Mat3D{})
+	dt := 1.0 / 120
+
+	// pendulum on a ball socket and on a distance joint keep their length
+	for _, kind := range []string{"ball", "distance"} {
+		bob := sphereBody(1, 0.2, m.Vec3D{X: 2})
+		var j m.Joint = m.NewBallSocketJoint(anchor, bob, m.Vec3D{})
+		if kind == "distance" {
+			j = m.NewDistanceJoint(anchor, bob, m.Vec3D{}, bob.Position)
+		}
+
+		s := m.NewSolver()
+		for i := 0; i < 240; i++ {
+			physicsStep(s, []*m.RigidBody{bob}, nil, []m.Joint{j}, dt)
+		}
+
+		if d := bob.Position.Length(); math.Abs(d-2) > 0.02 {
+			t.Errorf("%s: pendulum length %f", kind, d)
+		}
+		if bob.Position.X > -1 {
+			t.Errorf("%s: expected the pendulum to swing over, at %v", kind, bob.Position)
+		}
+	}
+
+	// a wheel on a hinge only spins about its axle
+	wheel := sphereBody(1, 1, m.Vec3D{})
+	wheel.AngularVelocity = m.Vec3D{X: 1, Y: 2, Z: 5}
+	hinge, err := m.NewHingeJoint(anchor, wheel, m.Vec3D{}, m.Vec3D{Z: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := m.NewSolver()
+	for i := 0; i < 120; i++ {
+		physicsStep(s, []*m.RigidBody{wheel}, nil, []m.Joint{hinge}, dt)
+	}
+	if w := wheel.AngularVelocity; math.Abs(w.X) > 1e-6 || math.Abs(w.Y) > 1e-6 || math.Abs(w.Z-5) > 1e-6 {
+		t.Errorf("expected a spin of 5 about Z, got %v", w)
+	}
+	if wheel.Position.Length() > 1e-3 {
+		t.Errorf("the axle moved to %v", wheel.Position)
+	}
+
+	// a gyroscope on a hinge turning with its mount, once settled the warm
+	// started impulses alone keep it on the axle as the axle turns round
+	spinner, _ := m.NewRigidBody(0, m.Mat3D{})
+	spinner.AngularVelocity = m.Vec3D{Y: 1}
+	axle := m.Vec3D{X: 1, Y: 0.5}
+	axle.Normalize()
+	gyro, _ := m.NewRigidBody(1, m.BoxInertia(1, m.Vec3D{X: 0.1, Y: 1, Z: 1}))
+	gyro.Orientation.SetFromTo(m.Vec3D{X: 1}, axle)
+	gyro.AngularVelocity = axle.ScalerMulVec(20).AddVec(spinner.AngularVelocity)
+	hinge, _ = m.NewHingeJoint(spinner, gyro, m.Vec3D{}, axle)
+	s = m.NewSolver()
+	for i := 0; i < 600; i++ {
+		if i == 60 {
+			s.Iterations = 0
+		}
+		physicsStep(s, []*m.RigidBody{spinner, gyro}, nil, []m.Joint{hinge}, dt)
+
+		a := spinner.Orientation.ToRotMat3D().RotateVec3D(axle)
+		tip := a.CrossV(gyro.Orientation.ToRotMat3D().RotateVec3D(m.Vec3D{X: 1}))
+		if tip.Length() > 0.02 {
+			t.Fatalf("step %d: gyroscope tipped off its axle by %f", i, tip.Length())
+		}
+	}
+
+	// a slider drops along its axis without turning
+	block := sphereBody(1, 0.5, m.Vec3D{X: 1})
+	block.AngularVelocity = m.Vec3D{Z: 3}
+	diag := m.Vec3D{X: 1, Y: -1}
+	slider, _ := m.NewSliderJoint(anchor, block, diag)
+	s = m.NewSolver()
+	for i := 0; i < 120; i++ {
+		physicsStep(s, []*m.RigidBody{block}, nil, []m.Joint{slider}, dt)
+	}
+	diag.Normalize()
+	off := block.Position.SubVec(m.Vec3D{X: 1})
+	if across := off.SubVec(diag.ScalerMulVec(off.Dot(diag))); across.Length() > 1e-3 {
+		t.Errorf("slider left its axis by %v", across)
+	}
+	if tr := slider.Translation(); tr < 1 || math.Abs(tr-off.Dot(diag)) > 1e-9 {
+		t.Errorf("expected to slide down the axis, translation %f", tr)
+	}
+	if math.Abs(math.Abs(block.Orientation.W)-1) > 1e-6 {
+		t.Errorf("slider turned to %v", block.Orientation)
+	}
+
+	// a fixed joint carries a body along with a moving kinematic one
+	carrier, _ := m.NewRigidBody(0, m.Mat3D{})
+	carrier.LinearVelocity = m.Vec3D{X: 1}
+	carrier.AngularVelocity = m.Vec3D{Y: 1}
+	load := sphereBody(1, 0.5, m.Vec3D{X: 1, Z: 1})
+	fixed := m.NewFixedJoint(carrier, load)
+	local := carrier.ToLocal(load.Position)
+
+	s = m.NewSolver()
+	for i := 0; i < 120; i++ {
+		physicsStep(s, []*m.RigidBody{carrier, load}, nil, []m.Joint{fixed}, dt)
+	}
+	if p := carrier.ToWorld(local); !nearVec3D(load.Position, p, 0.01) {
+		t.Errorf("fixed load at %v, expected %v", load.Position, p)
+	}
+	if d := load.Orientation.Dot(carrier.Orientation); math.Abs(math.Abs(d)-1) > 1e-4 {
+		t.Errorf("fixed load turned apart from its carrier, %v and %v", load.Orientation, carrier.Orientation)
+	}
+
+	if _, err := m.NewHingeJoint(anchor, wheel, m.Vec3D{}, m.Vec3D{}); err != m.ErrZeroLen {
+		t.Errorf("expected ErrZeroLen, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func runAgent2D(a *m.Agent2D, b m.Behavior2D, steps int, check func()) {
+	for i := 0; i < steps; i++ {
+		a.Update(b, 1.0/30)
+		if check != nil {
+			check()
+		}
+	}
+}
+
+func TestSteering2D(t *testing.T) {
+	// seek gets there without going over the limits
+	a := m.NewAgent2D(m.Vec2D{}, 2, 4)
+	a.Update(m.Seek2D{Target: m.Vec2D{X: 10}}, 1)
+	if !nearVec2D(a.Velocity, m.Vec2D{X: 2}, 1e-9) {
+		t.Errorf("expected full speed after a second of full force, got %v", a.Velocity)
+	}
+	a = m.NewAgent2D(m.Vec2D{}, 2, 1)
+	a.Update(m.Seek2D{Target: m.Vec2D{X: 10}}, 0.25)
+	if !nearVec2D(a.Velocity, m.Vec2D{X: 0.25}, 1e-9) {
+		t.Errorf("expected the force clamped to 1, got %v", a.Velocity)
+	}
+
+	// arrive stops on the target
+	a = m.NewAgent2D(m.Vec2D{}, 2, 4)
+	runAgent2D(a, m.Arrive2D{Target: m.Vec2D{X: 5, Y: 5}, SlowRadius: 2}, 600, func() {
+		if a.Velocity.Length() > a.MaxSpeed+1e-9 {
+			t.Fatalf("speed %f over the limit", a.Velocity.Length())
+		}
+	})
+	if !nearVec2D(a.Position, m.Vec2D{X: 5, Y: 5}, 0.01) || a.Velocity.Length() > 0.01 {
+		t.Errorf("expected to stop on the target, at %v moving %v", a.Position, a.Velocity)
+	}
+
+	// flee only inside the panic distance
+	a = m.NewAgent2D(m.Vec2D{X: 1}, 2, 4)
+	if f := (m.Flee2D{Target: m.Vec2D{}, PanicDistance: 0.5}).Steer(a); f != (m.Vec2D{}) {
+		t.Errorf("expected no panic outside the distance, got %v", f)
+	}
+	runAgent2D(a, m.Flee2D{Target: m.Vec2D{}}, 30, nil)
+	if a.Position.X < 1.5 || math.Abs(a.Position.Y) > 1e-9 {
+		t.Errorf("expected to run away along X, at %v", a.Position)
+	}
+
+	// pursuit leads a crossing target and catches it sooner than seeking it
+	catch := func(pursue bool) int {
+		prey := m.NewAgent2D(m.Vec2D{X: 10}, 1, 1)
+		prey.Velocity = m.Vec2D{Y: 1}
+		hunter := m.NewAgent2D(m.Vec2D{}, 2, 4)
+
+		for i := 0; i < 600; i++ {
+			var b m.Behavior2D = m.Seek2D{Target: prey.Position}
+			if pursue {
+				b = m.Pursue2D{Target: prey}
+			}
+			hunter.Update(b, 1.0/30)
+			prey.Update(m.Seek2D{Target: prey.Position.AddVec(m.Vec2D{Y: 1})}, 1.0/30)
+			if hunter.Position.Dist(prey.Position) < 0.1 {
+				return i
+			}
+		}
+		return 600
+	}
+	if p, s := catch(true), catch(false); p >= s || p == 600 {
+		t.Errorf("expected pursuit to be quicker, %d against %d steps", p, s)
+	}
+
+	// evade keeps away from a pursuer
+	prey := m.NewAgent2D(m.Vec2D{X: 2}, 2, 4)
+	hunter := m.NewAgent2D(m.Vec2D{}, 1.5, 4)
+	for i := 0; i < 300; i++ {
+		prey.Update(m.Evade2D{Target: hunter}, 1.0/30)
+		hunter.Update(m.Pursue2D{Target: prey}, 1.0/30)
+	}
+	if d := prey.Position.Dist(hunter.Position); d < 2 {
+		t.Errorf("expected the prey to get away, %f apart", d)
+	}
+
+	// wander roams at full speed and replays the same with the same seed
+	var ends [2]m.Vec2D
+	for run := range ends {
+		a = m.NewAgent2D(m.Vec2D{}, 1, 2)
+		a.Velocity = m.Vec2D{X: 1}
+		w := &m.Wander2D{Distance: 2, Radius: 1, Jitter: 0.5, Rand: rand.New(rand.NewSource(7))}
+		runAgent2D(a, w, 300, nil)
+		ends[run] = a.Position
+		if math.Abs(a.Velocity.Length()-1) > 0.1 {
+			t.Errorf("expected to wander at full speed, moving %v", a.Velocity)
+		}
+	}
+	if ends[0] != ends[1] {
+		t.Errorf("replay ends at %v, first run at %v", ends[1], ends[0])
+	}
+
+	// avoidance takes priority over seeking a target behind an obstacle
+	rock := m.Circle{Center: m.Vec2D{X: 5, Y: 0.1}, Radius: 1}
+	a = m.NewAgent2D(m.Vec2D{}, 2, 6)
+	a.Radius = 0.3
+	steer := m.Priority2D{Behaviors: []m.WeightedBehavior2D{
+		{Behavior: m.ObstacleAvoidance2D{Obstacles: []m.Circle{rock}, Lookahead: 2}, Weight: 1},
+		{Behavior: m.Arrive2D{Target: m.Vec2D{X: 10}, SlowRadius: 1}, Weight: 1},
+	}}
+	runAgent2D(a, steer, 400, func() {
+		if d := a.Position.Dist(rock.Center); d < rock.Radius {
+			t.Fatalf("ran into the rock at %v", a.Position)
+		}
+	})
+	if !nearVec2D(a.Position, m.Vec2D{X: 10}, 0.1) {
+		t.Errorf("expected to get round to the target, at %v", a.Position)
+	}
+
+	// without a lookahead an overlapping obstacle gives no push
+	a = m.NewAgent2D(rock.Center, 2, 6)
+	if f := (m.ObstacleAvoidance2D{Obstacles: []m.Circle{rock}}).Steer(a); f != (m.Vec2D{}) {
+		t.Errorf("expected no push without a lookahead, got %v", f)
+	}
+	a3 := m.NewAgent3D(m.Vec3D{}, 2, 6)
+	if f := (m.ObstacleAvoidance3D{Obstacles: []m.Sphere{{Radius: 1}}}).Steer(a3); f != (m.Vec3D{}) {
+		t.Errorf("expected no push without a lookahead, got %v", f)
+	}
+
+	// priority spends MaxForce on the first behavior before the second
+	a = m.NewAgent2D(m.Vec2D{}, 2, 1)
+	both := []m.WeightedBehavior2D{
+		{Behavior: m.Seek2D{Target: m.Vec2D{X: 1}}, Weight: 1},
+		{Behavior: m.Seek2D{Target: m.Vec2D{Y: 1}}, Weight: 1},
+	}
+	if f := (m.Priority2D{Behaviors: both}).Steer(a); !nearVec2D(f, m.Vec2D{X: 1}, 1e-9) {
+		t.Errorf("expected only the first behavior, got %v", f)
+	}
+	if f := (m.Blend2D{Behaviors: both}).Steer(a); !nearVec2D(f, m.Vec2D{X: 2, Y: 2}, 1e-9) {
+		t.Errorf("expected the sum of both, got %v", f)
+	}
+
+	// path following runs along the corner and stops at the end
+	path := []m.Vec2D{{}, {X: 5}, {X: 5, Y: 5}}
+	a = m.NewAgent2D(m.Vec2D{Y: -0.5}, 2, 6)
+	runAgent2D(a, m.PathFollow2D{Points: path, Lookahead: 1}, 600, func() {
+		if d := math.Min(math.Abs(a.Position.Y), math.Abs(a.Position.X-5)); d > 0.75 {
+			t.Fatalf("strayed %f from the path at %v", d, a.Position)
+		}
+	})
+	if !nearVec2D(a.Position, m.Vec2D{X: 5, Y: 5}, 0.1) {
+		t.Errorf("expected to stop at the end of the path, at %v", a.Position)
+	}
+}
+
+func TestFlocking(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+
+	flock := make([]*m.Agent2D, 30)
+	for i := range flock {
+		flock[i] = m.NewAgent2D(m.Vec2D{X: r.Float64() * 6, Y: r.Float64() * 6}, 1, 2)
+		flock[i].Velocity = m.Vec2D{X: r.Float64()*2 - 1, Y: r.Float64()*2 - 1}
+	}
+
+	boids := m.Blend2D{Behaviors: []m.WeightedBehavior2D{
+		{Behavior: m.Separation2D{Flock: flock, Radius: 1}, Weight: 2},
+		{Behavior: m.Alignment2D{Flock: flock, Radius: 3}, Weight: 1},
+		{Behavior: m.Cohesion2D{Flock: flock, Radius: 3}, Weight: 0.5},
+	}}
+	for i := 0; i < 600; i++ {
+		for _, a := range flock {
+			a.Update(boids, 1.0/30)
+		}
+	}
+
+	// headed the same way without bunching up
+	mean := m.Vec2D{}
+	for _, a := range flock {
+		mean.Add(a.Heading)
+	}
+	if l := mean.Length() / float64(len(flock)); l < 0.9 {
+		t.Errorf("expected the flock to align, mean heading %f", l)
+	}
+
+	closest := math.Inf(1)
+	for i, a := range flock {
+		for _, b := range flock[i+1:] {
+			closest = math.Min(closest, a.Position.Dist(b.Position))
+		}
+	}
+	if closest < 0.2 {
+		t.Errorf("boids %f apart", closest)
+	}
+}
+
+func TestSteering3D(t *testing.T) {
+	// arrive through a ball in the way
+	ball := m.Sphere{Center: m.Vec3D{X: 5}, Radius: 1}
+	a := m.NewAgent3D(m.Vec3D{}, 2, 6)
+	a.Radius = 0.3
+	steer := m.Priority3D{Behaviors: []m.WeightedBehavior3D{
+		{Behavior: m.ObstacleAvoidance3D{Obstacles: []m.Sphere{ball}, Lookahead: 2}, Weight: 1},
+		{Behavior: m.Arrive3D{Target: m.Vec3D{X: 10}, SlowRadius: 1}, Weight: 1},
+	}}
+	for i := 0; i < 600; i++ {
+		a.Update(steer, 1.0/30)
+		if d := a.Position.Dist(ball.Center); d < ball.Radius {
+			t.Fatalf("ran into the ball at %v", a.Position)
+		}
+	}
+	if !nearVec3D(a.Position, m.Vec3D{X: 10}, 0.1) || a.Velocity.Length() > 0.05 {
+		t.Errorf("expected to stop at the target, at %v moving %v", a.Position, a.Velocity)
+	}
+
+	// a looping path keeps going round
+	square, _ := m.NewPath3D([]m.Vec3D{{}, {X: 4}, {X: 4, Z: 4}, {Z: 4}, {}})
+	a = m.NewAgent3D(m.Vec3D{}, 2, 8)
+	follow := m.PathFollow3D{Path: square, Lookahead: 0.5, Loop: true}
+	travelled := 0.0
+	for i := 0; i < 600; i++ {
+		p := a.Position
+		a.Update(follow, 1.0/30)
+		travelled += a.Position.Dist(p)
+
+		param, c := square.ClosestPoint(a.Position)
+		if d := a.Position.Dist(c); d > 0.75 {
+			t.Fatalf("strayed %f from the path at %v, param %f", d, a.Position, param)
+		}
+	}
+	if travelled < 1.5*square.Length() {
+		t.Errorf("expected to keep going round, travelled %f", travelled)
+	}
+
+	// evade and pursue mirror the 2D ones
+	prey := m.NewAgent3D(m.Vec3D{Y: 2}, 2, 4)
+	hunter := m.NewAgent3D(m.Vec3D{}, 1.5, 4)
+	for i := 0; i < 300; i++ {
+		prey.Update(m.Evade3D{Target: hunter}, 1.0/30)
+		hunter.Update(m.Pursue3D{Target: prey}, 1.0/30)
+	}
+	if d := prey.Position.Dist(hunter.Position); d < 2 {
+		t.Errorf("expected the prey to get away, %f apart", d)
+	}
+
+	// wander stays at speed, a flock of three aligns
+	a = m.NewAgent3D(m.Vec3D{}, 1, 2)
+	a.Velocity = m.Vec3D{X: 1}
+	w := &m.Wander3D{Distance: 2, Radius: 1, Jitter: 0.2, Rand: rand.New(rand.NewSource(5))}
+	for i := 0; i < 300; i++ {
+		a.Update(w, 1.0/30)
+	}
+	if math.Abs(a.Velocity.Length()-1) > 0.1 {
+		t.Errorf("expected to wander at full speed, moving %v", a.Velocity)
+	}
+
+	flock := []*m.Agent3D{
+		m.NewAgent3D(m.Vec3D{}, 1, 2),
+		m.NewAgent3D(m.Vec3D{X: 1}, 1, 2),
+		m.NewAgent3D(m.Vec3D{Y: 1}, 1, 2),
+	}
+	flock[0].Velocity, flock[1].Velocity, flock[2].Velocity = m.Vec3D{X: 1}, m.Vec3D{Y: 1}, m.Vec3D{Z: 1}
+	boids := m.Blend3D{Behaviors: []m.WeightedBehavior3D{
+		{Behavior: m.Separation3D{Flock: flock, Radius: 0.5}, Weight: 2},
+		{Behavior: m.Alignment3D{Flock: flock, Radius: 5}, Weight: 1},
+		{Behavior: m.Cohesion3D{Flock: flock, Radius: 5}, Weight: 0.5},
+	}}
+	for i := 0; i < 300; i++ {
+		for _, b := range flock {
+			b.Update(boids, 1.0/30)
+		}
+	}
+	for _, b := range flock[1:] {
+		if d := b.Heading.Dot(flock[0].Heading); d < 0.99 {
+			t.Errorf("expected the flock to align, headings %v and %v", b.Heading, flock[0].Heading)
+		}
+	}
+}
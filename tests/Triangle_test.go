@@ -0,0 +1,124 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func TestTriangleBarycentric(t *testing.T) {
+	tri := m.Triangle2D{A: m.Vec2D{X: 0, Y: 0}, B: m.Vec2D{X: 4, Y: 0}, C: m.Vec2D{X: 0, Y: 4}}
+
+	tests := []struct {
+		name    string
+		p       m.Vec2D
+		u, v, w float64
+		inside  bool
+	}{
+		{"Vertex A", m.Vec2D{X: 0, Y: 0}, 1, 0, 0, true},
+		{"Mid Edge", m.Vec2D{X: 2, Y: 2}, 0, 0.5, 0.5, true},
+		{"Inside", m.Vec2D{X: 1, Y: 1}, 0.5, 0.25, 0.25, true},
+		{"Outside", m.Vec2D{X: 4, Y: 4}, -1, 1, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, v, w, err := tri.Barycentric(tt.p)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if math.Abs(u-tt.u) > 1e-9 || math.Abs(v-tt.v) > 1e-9 || math.Abs(w-tt.w) > 1e-9 {
+				t.Errorf("Expected (%v, %v, %v), Got (%v, %v, %v)", tt.u, tt.v, tt.w, u, v, w)
+			}
+
+			if tri.ContainsPoint(tt.p) != tt.inside {
+				t.Errorf("Expected inside %v for %v", tt.inside, tt.p)
+			}
+		})
+	}
+
+	degenerate := m.Triangle2D{A: m.Vec2D{X: 0, Y: 0}, B: m.Vec2D{X: 1, Y: 1}, C: m.Vec2D{X: 2, Y: 2}}
+	if _, _, _, err := degenerate.Barycentric(m.Vec2D{}); err != m.ErrDegenerateTriangle {
+		t.Errorf("Expected %v, Got %v", m.ErrDegenerateTriangle, err)
+	}
+}
+
+func TestTriangleClosestPoint(t *testing.T) {
+	tri := m.Triangle3D{A: m.Vec3D{X: 0, Y: 0, Z: 0}, B: m.Vec3D{X: 2, Y: 0, Z: 0}, C: m.Vec3D{X: 0, Y: 2, Z: 0}}
+
+	tests := []struct {
+		name string
+		p    m.Vec3D
+		res  m.Vec3D
+	}{
+		{"Above Face", m.Vec3D{X: 0.5, Y: 0.5, Z: 3}, m.Vec3D{X: 0.5, Y: 0.5, Z: 0}},
+		{"Vertex Region", m.Vec3D{X: -1, Y: -1, Z: 0}, m.Vec3D{X: 0, Y: 0, Z: 0}},
+		{"Edge Region", m.Vec3D{X: 1, Y: -2, Z: 1}, m.Vec3D{X: 1, Y: 0, Z: 0}},
+		{"Hypotenuse Region", m.Vec3D{X: 2, Y: 2, Z: 0}, m.Vec3D{X: 1, Y: 1, Z: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := tri.ClosestPoint(tt.p)
+			if res.Dist(tt.res) > 1e-9 {
+				t.Errorf("Expected %v, Got %v", tt.res, res)
+			}
+		})
+	}
+}
+
+func TestTriangleProperties(t *testing.T) {
+	tri := m.Triangle2D{A: m.Vec2D{X: 0, Y: 0}, B: m.Vec2D{X: 3, Y: 0}, C: m.Vec2D{X: 0, Y: 4}}
+
+	if tri.SignedArea() != 6 {
+		t.Errorf("Expected area 6, Got %v", tri.SignedArea())
+	}
+
+	cc, err := tri.Circumcenter()
+	if err != nil || cc.Dist(m.Vec2D{X: 1.5, Y: 2}) > 1e-9 {
+		t.Errorf("Expected circumcenter {1.5 2}, Got %v (%v)", cc, err)
+	}
+
+	in, err := tri.Incenter()
+	if err != nil || in.Dist(m.Vec2D{X: 1, Y: 1}) > 1e-9 {
+		t.Errorf("Expected incenter {1 1}, Got %v (%v)", in, err)
+	}
+
+	tri3 := m.Triangle3D{A: m.Vec3D{X: 0, Y: 0, Z: 0}, B: m.Vec3D{X: 3, Y: 0, Z: 0}, C: m.Vec3D{X: 0, Y: 4, Z: 0}}
+	n, err := tri3.Normal()
+	if err != nil || n.IsNotEqual(m.Vec3D{X: 0, Y: 0, Z: 1}) {
+		t.Errorf("Expected normal {0 0 1}, Got %v (%v)", n, err)
+	}
+
+	cc3, err := tri3.Circumcenter()
+	if err != nil || cc3.Dist(m.Vec3D{X: 1.5, Y: 2, Z: 0}) > 1e-9 {
+		t.Errorf("Expected circumcenter {1.5 2 0}, Got %v (%v)", cc3, err)
+	}
+}
+
+func TestTriangleIntersectRay(t *testing.T) {
+	tri := m.Triangle3D{A: m.Vec3D{X: 0, Y: 0, Z: 0}, B: m.Vec3D{X: 2, Y: 0, Z: 0}, C: m.Vec3D{X: 0, Y: 2, Z: 0}}
+
+	tests := []struct {
+		name string
+		ray  m.Ray3D
+		hit  bool
+		t    float64
+	}{
+		{"Hit From Above", m.Ray3D{Origin: m.Vec3D{X: 0.5, Y: 0.5, Z: 5}, Dir: m.Vec3D{X: 0, Y: 0, Z: -1}}, true, 5},
+		{"Hit From Below", m.Ray3D{Origin: m.Vec3D{X: 0.5, Y: 0.5, Z: -2}, Dir: m.Vec3D{X: 0, Y: 0, Z: 1}}, true, 2},
+		{"Miss Outside", m.Ray3D{Origin: m.Vec3D{X: 2, Y: 2, Z: 5}, Dir: m.Vec3D{X: 0, Y: 0, Z: -1}}, false, 0},
+		{"Pointing Away", m.Ray3D{Origin: m.Vec3D{X: 0.5, Y: 0.5, Z: 5}, Dir: m.Vec3D{X: 0, Y: 0, Z: 1}}, false, 0},
+		{"Parallel", m.Ray3D{Origin: m.Vec3D{X: -1, Y: 0.5, Z: 0}, Dir: m.Vec3D{X: 1, Y: 0, Z: 0}}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dist, _, _, hit := tri.IntersectRay(tt.ray)
+			if hit != tt.hit || (hit && math.Abs(dist-tt.t) > 1e-9) {
+				t.Errorf("Expected (%v, %v), Got (%v, %v)", tt.hit, tt.t, hit, dist)
+			}
+		})
+	}
+}
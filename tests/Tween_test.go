@@ -0,0 +1,155 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func TestEasing(t *testing.T) {
+	families := map[string][3]m.EasingFunc{
+		"quad":    {m.EaseInQuad, m.EaseOutQuad, m.EaseInOutQuad},
+		"cubic":   {m.EaseInCubic, m.EaseOutCubic, m.EaseInOutCubic},
+		"quart":   {m.EaseInQuart, m.EaseOutQuart, m.EaseInOutQuart},
+		"quint":   {m.EaseInQuint, m.EaseOutQuint, m.EaseInOutQuint},
+		"sine":    {m.EaseInSine, m.EaseOutSine, m.EaseInOutSine},
+		"expo":    {m.EaseInExpo, m.EaseOutExpo, m.EaseInOutExpo},
+		"circ":    {m.EaseInCirc, m.EaseOutCirc, m.EaseInOutCirc},
+		"back":    {m.EaseInBack, m.EaseOutBack, m.EaseInOutBack},
+		"elastic": {m.EaseInElastic, m.EaseOutElastic, m.EaseInOutElastic},
+		"bounce":  {m.EaseInBounce, m.EaseOutBounce, m.EaseInOutBounce},
+	}
+
+	for name, f := range families {
+		for i, ease := range f {
+			if a, b := ease(0), ease(1); math.Abs(a) > 1e-12 || math.Abs(b-1) > 1e-12 {
+				t.Errorf("%s %d: expected 0 and 1 at the ends, got %f and %f", name, i, a, b)
+			}
+		}
+
+		if v := f[2](0.5); math.Abs(v-0.5) > 1e-12 {
+			t.Errorf("%s: in-out is %f halfway", name, v)
+		}
+
+		for _, u := range []float64{0.1, 0.3, 0.6, 0.9} {
+			if in, out := f[0](u), f[1](1-u); math.Abs(in-(1-out)) > 1e-12 {
+				t.Errorf("%s: out is not the mirrored in at %f", name, u)
+			}
+		}
+	}
+
+	if m.EaseInBack(0.2) >= 0 || m.EaseOutBack(0.8) <= 1 {
+		t.Error("expected back easing to overshoot")
+	}
+	for i := 0; i <= 100; i++ {
+		if v := m.EaseOutBounce(float64(i) / 100); v < 0 || v > 1+1e-12 {
+			t.Errorf("bounce leaves [0, 1] with %f", v)
+		}
+	}
+
+	// CSS ease
+	ease, err := m.CubicBezierEasing(0.25, 0.1, 0.25, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := ease(0.5); math.Abs(v-0.8024033877399112) > 1e-9 {
+		t.Errorf("expected 0.8024 halfway, got %f", v)
+	}
+
+	linear, _ := m.CubicBezierEasing(0, 0, 1, 1)
+	for _, u := range []float64{0, 0.25, 0.7, 1} {
+		if v := linear(u); math.Abs(v-u) > 1e-9 {
+			t.Errorf("expected %f, got %f", u, v)
+		}
+	}
+
+	if _, err := m.CubicBezierEasing(-0.1, 0, 1, 1); err != m.ErrInvalidInterPolParam {
+		t.Errorf("expected ErrInvalidInterPolParam, got %v", err)
+	}
+}
+
+func TestTween(t *testing.T) {
+	x := 0.0
+	tw, err := m.TweenFloat(&x, 10, 20, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw.Delay = 0.5
+	tw.Loops = 2
+	tw.Yoyo = true
+
+	loops, completed := 0, 0
+	tw.OnLoop = func(int) { loops++ }
+	tw.OnComplete = func() { completed++ }
+
+	steps := []struct {
+		dt, x   float64
+		running bool
+	}{
+		{0.25, 0, true},   // still waiting
+		{0.5, 12.5, true}, // 0.25 into the first play
+		{1, 17.5, true},   // backwards on the second
+		{1, 12.5, true},   // forwards again
+		{1, 20, false},    // done on the end of the third play
+		{1, 20, false},
+	}
+
+	for i, s := range steps {
+		if running := tw.Update(s.dt); running != s.running || math.Abs(x-s.x) > 1e-12 {
+			t.Fatalf("step %d: expected %f running %v, got %f running %v", i, s.x, s.running, x, running)
+		}
+	}
+	if loops != 2 || completed != 1 {
+		t.Errorf("expected 2 loops and 1 completion, got %d and %d", loops, completed)
+	}
+
+	tw.Reset()
+	tw.Finish()
+	if !tw.Done() || x != 20 || completed != 2 {
+		t.Errorf("Finish left %f, done %v", x, tw.Done())
+	}
+
+	// quarter turn about z, halfway is an eighth
+	var q m.Quaternion
+	end := m.Quaternion{W: math.Cos(math.Pi / 4), Z: math.Sin(math.Pi / 4)}
+	qt, _ := m.TweenQuaternion(&q, m.Quaternion{W: 1}, end, 2, m.EaseInOutSine)
+	qt.Update(1)
+	if exp := (m.Quaternion{W: math.Cos(math.Pi / 8), Z: math.Sin(math.Pi / 8)}); math.Abs(q.Dot(exp)-1) > 1e-9 {
+		t.Errorf("expected %v halfway, got %v", exp, q)
+	}
+
+	// the short way round crosses pi
+	var r, from, to m.RotMat2D
+	from.Set(m.ToRadians(170))
+	to.Set(m.ToRadians(-170))
+	rt, _ := m.TweenRotMat2D(&r, from, to, 1, nil)
+	rt.Update(0.5)
+	if a := math.Abs(r.EulerAngleDeg()); math.Abs(a-180) > 1e-9 {
+		t.Errorf("expected 180 degrees halfway, got %f", r.EulerAngleDeg())
+	}
+
+	var v m.Vec2D
+	mgr := m.TweenManager{}
+	short, _ := m.TweenVec2D(&v, m.Vec2D{}, m.Vec2D{X: 4, Y: 2}, 1, m.EaseOutQuad)
+	forever, _ := m.TweenFloat(&x, 0, 1, 1, nil)
+	forever.Loops = -1
+	mgr.Add(short)
+	mgr.Add(forever)
+
+	mgr.Update(0.5)
+	if exp := (m.Vec2D{X: 3, Y: 1.5}); !nearVec2D(v, exp, 1e-12) {
+		t.Errorf("expected %v, got %v", exp, v)
+	}
+
+	mgr.Update(0.6)
+	if mgr.Len() != 1 || !nearVec2D(v, m.Vec2D{X: 4, Y: 2}, 1e-12) {
+		t.Errorf("expected the finished tween dropped at its end, %d left at %v", mgr.Len(), v)
+	}
+
+	// a tween removing itself from a callback
+	forever.OnLoop = func(int) { mgr.Remove(forever) }
+	mgr.Update(1)
+	if mgr.Len() != 0 {
+		t.Errorf("expected no tweens left, got %d", mgr.Len())
+	}
+}
@@ -165,3 +165,26 @@ func TestRotate(t *testing.T) {
 		})
 	}
 }
+
+func TestProjection(t *testing.T) {
+	tests := []struct {
+		name string
+		v1   m.Vec2D
+		v2   m.Vec2D
+		res  m.Vec2D
+	}{
+		{"Parallel Case", m.Vec2D{X: 2, Y: 0}, m.Vec2D{X: 4, Y: 0}, m.Vec2D{X: 2, Y: 0}},
+		{"Perpendicular Case", m.Vec2D{X: 0, Y: 3}, m.Vec2D{X: 2, Y: 0}, m.Vec2D{X: 0, Y: 0}},
+		{"Non Parallel Case", m.Vec2D{X: 3, Y: 4}, m.Vec2D{X: 2, Y: 0}, m.Vec2D{X: 3, Y: 0}},
+		{"Diagonal Case", m.Vec2D{X: 1, Y: 3}, m.Vec2D{X: 1, Y: 1}, m.Vec2D{X: 2, Y: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.v1.Projection(tt.v2)
+			if !nearVec2D(p, tt.res, 1e-12) {
+				t.Errorf("Expected %v, Got %v", tt.res, p)
+			}
+		})
+	}
+}
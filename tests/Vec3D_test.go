@@ -0,0 +1,29 @@
+package tests
+
+import (
+	m "golem"
+	"testing"
+)
+
+func TestProjectionOnto(t *testing.T) {
+	tests := []struct {
+		name string
+		v1   m.Vec3D
+		v2   m.Vec3D
+		res  m.Vec3D
+	}{
+		{"Parallel Case", m.Vec3D{X: 0, Y: 0, Z: 2}, m.Vec3D{X: 0, Y: 0, Z: 5}, m.Vec3D{X: 0, Y: 0, Z: 2}},
+		{"Perpendicular Case", m.Vec3D{X: 1, Y: 0, Z: 0}, m.Vec3D{X: 0, Y: 3, Z: 0}, m.Vec3D{X: 0, Y: 0, Z: 0}},
+		{"Non Parallel Case", m.Vec3D{X: 3, Y: 4, Z: 5}, m.Vec3D{X: 0, Y: 2, Z: 0}, m.Vec3D{X: 0, Y: 4, Z: 0}},
+		{"Diagonal Case", m.Vec3D{X: 1, Y: 2, Z: 3}, m.Vec3D{X: 1, Y: 1, Z: 1}, m.Vec3D{X: 2, Y: 2, Z: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.v1.ProjectionOnto(tt.v2)
+			if !nearVec3D(p, tt.res, 1e-12) {
+				t.Errorf("Expected %v, Got %v", tt.res, p)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func TestParticles3D(t *testing.T) {
+	g := m.Vec3D{Y: -9.8}
+
+	// free fall, Verlet starting at rest trails the exact fall by half a step
+	s := m.NewParticleSystem3D(g)
+	s.Substeps = 1
+	s.AddParticle(m.Vec3D{}, 1)
+	for i := 0; i < 60; i++ {
+		s.Step(1.0 / 60)
+	}
+	if y, exp := s.Particles[0].Position.Y, -9.8/2*(1+1.0/60); math.Abs(y-exp) > 1e-9 {
+		t.Errorf("expected to fall to %f, got %f", exp, y)
+	}
+	if v := s.Velocity(0); math.Abs(v.Y+9.8) > 1e-9 {
+		t.Errorf("expected falling at 9.8, got %v", v)
+	}
+
+	// a rope hanging from a pin keeps its links and settles below the pin
+	s = m.NewParticleSystem3D(g)
+	s.Damping, s.Iterations = 2, 20
+	for i := 0; i <= 10; i++ {
+		s.AddParticle(m.Vec3D{X: float64(i) * 0.2}, 0.1)
+	}
+	s.Pin(0, m.Vec3D{})
+	for i := 0; i < 10; i++ {
+		s.Constraints = append(s.Constraints, m.NewDistanceConstraint3D(s.Particles, i, i+1, 1))
+	}
+	for i := 0; i < 600; i++ {
+		s.Step(1.0 / 60)
+	}
+
+	for i := 0; i < 10; i++ {
+		if d := s.Particles[i].Position.Dist(s.Particles[i+1].Position); math.Abs(d-0.2) > 0.01 {
+			t.Errorf("link %d stretched to %f", i, d)
+		}
+	}
+	if p := s.Particles[0].Position; p != (m.Vec3D{}) {
+		t.Errorf("pinned end moved to %v", p)
+	}
+	if p := s.Particles[10].Position; math.Abs(p.X) > 0.05 || math.Abs(p.Y+2) > 0.05 {
+		t.Errorf("expected the free end 2 below the pin, got %v", p)
+	}
+
+	// cloth dropped over a ball drapes without going through it
+	s = m.NewParticleSystem3D(g)
+	s.Radius, s.Friction = 0.02, 0.5
+	ball := m.Sphere{Center: m.Vec3D{Y: -1}, Radius: 0.5}
+	s.Colliders = append(s.Colliders, ball, m.Plane{Normal: m.Vec3D{Y: 1}, D: 3})
+	n := 12
+	for i := 0; i < n; i++ {
+		for k := 0; k < n; k++ {
+			s.AddParticle(m.Vec3D{X: float64(i)/float64(n-1)*2 - 1, Z: float64(k)/float64(n-1)*2 - 1}, 0.01)
+		}
+	}
+	for i := 0; i < n; i++ {
+		for k := 0; k < n; k++ {
+			if i+1 < n {
+				s.Constraints = append(s.Constraints, m.NewDistanceConstraint3D(s.Particles, i*n+k, (i+1)*n+k, 1))
+			}
+			if k+1 < n {
+				s.Constraints = append(s.Constraints, m.NewDistanceConstraint3D(s.Particles, i*n+k, i*n+k+1, 1))
+			}
+		}
+	}
+	for i := 0; i < 120; i++ {
+		s.Step(1.0 / 60)
+	}
+
+	top := math.Inf(-1)
+	for i, p := range s.Particles {
+		if d := p.Position.Dist(ball.Center); d < ball.Radius+s.Radius-1e-9 {
+			t.Fatalf("particle %d is %f inside the ball", i, ball.Radius+s.Radius-d)
+		}
+		if p.Position.Y < -3+s.Radius-1e-9 {
+			t.Fatalf("particle %d went through the floor to %v", i, p.Position)
+		}
+		top = math.Max(top, p.Position.Y)
+	}
+	if math.Abs(top-(-0.5+s.Radius)) > 0.05 {
+		t.Errorf("expected the cloth to rest on top of the ball, top at %f", top)
+	}
+}
+
+func cubeBalloon(s *m.ParticleSystem3D, center m.Vec3D, half float64) *m.VolumeConstraint3D {
+	first := len(s.Particles)
+	mesh := boxMesh(m.Vec3D{X: half, Y: half, Z: half}, center, m.Quaternion{W: 1})
+	for _, v := range mesh.Vertices {
+		s.AddParticle(v, 0.1)
+	}
+
+	faces := make([][3]int, len(mesh.Faces))
+	for i, f := range mesh.Faces {
+		faces[i] = [3]int{first + f[0], first + f[1], first + f[2]}
+		for k := 0; k < 3; k++ {
+			s.Constraints = append(s.Constraints, m.NewDistanceConstraint3D(s.Particles, faces[i][k], faces[i][(k+1)%3], 0.5))
+		}
+	}
+
+	v := m.NewVolumeConstraint3D(s.Particles, faces, 1)
+	s.Constraints = append(s.Constraints, v)
+	return v
+}
+
+func balloonVolume(s *m.ParticleSystem3D, v *m.VolumeConstraint3D) float64 {
+	vol := 0.0
+	for _, f := range v.Faces {
+		a, b, c := s.Particles[f[0]].Position, s.Particles[f[1]].Position, s.Particles[f[2]].Position
+		vol += a.Dot(b.CrossV(c)) / 6
+	}
+
+	return vol
+}
+
+func TestVolumeConstraint(t *testing.T) {
+	s := m.NewParticleSystem3D(m.Vec3D{Y: -9.8})
+	s.Damping = 1
+	s.Colliders = append(s.Colliders, m.Plane{Normal: m.Vec3D{Y: 1}})
+	v := cubeBalloon(s, m.Vec3D{Y: 1}, 0.5)
+
+	if math.Abs(v.Volume-1) > 1e-9 {
+		t.Fatalf("expected a rest volume of 1, got %f", v.Volume)
+	}
+
+	// it lands on the floor squashed but with its volume
+	for i := 0; i < 300; i++ {
+		s.Step(1.0 / 60)
+	}
+	if vol := balloonVolume(s, v); math.Abs(vol-1) > 0.05 {
+		t.Errorf("expected to keep the volume of 1, got %f", vol)
+	}
+	for i, p := range s.Particles {
+		if p.Position.Y < -1e-9 {
+			t.Errorf("particle %d below the floor at %v", i, p.Position)
+		}
+	}
+
+	v.Pressure = 1.5
+	for i := 0; i < 300; i++ {
+		s.Step(1.0 / 60)
+	}
+	if vol := balloonVolume(s, v); vol < 1.3 {
+		t.Errorf("expected pressure to inflate the balloon, volume %f", vol)
+	}
+}
+
+func TestParticles2D(t *testing.T) {
+	g := m.Vec2D{Y: -9.8}
+
+	// a ring of particles holds its area when dropped on the ground
+	s := m.NewParticleSystem2D(g)
+	s.Damping, s.Friction = 1, 0.3
+	s.Colliders = append(s.Colliders, m.AABB2D{Min: m.Vec2D{X: -10, Y: -1}, Max: m.Vec2D{X: 10}})
+
+	n := 16
+	ring := make([]int, n)
+	for i := range ring {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		ring[i] = s.AddParticle(m.Vec2D{X: math.Cos(a), Y: 2 + math.Sin(a)}, 0.1)
+	}
+	for i := range ring {
+		s.Constraints = append(s.Constraints, m.NewDistanceConstraint2D(s.Particles, ring[i], ring[(i+1)%n], 0.5))
+	}
+	area := m.NewAreaConstraint2D(s.Particles, ring, 1)
+	s.Constraints = append(s.Constraints, area)
+
+	exp := float64(n) / 2 * math.Sin(2*math.Pi/float64(n))
+	if math.Abs(area.Area-exp) > 1e-9 {
+		t.Fatalf("expected a rest area of %f, got %f", exp, area.Area)
+	}
+
+	for i := 0; i < 300; i++ {
+		s.Step(1.0 / 60)
+	}
+
+	a := 0.0
+	for i, k := range ring {
+		p, q := s.Particles[k].Position, s.Particles[ring[(i+1)%n]].Position
+		a += (p.X*q.Y - p.Y*q.X) / 2
+		if p.Y < -1e-9 {
+			t.Errorf("particle %d below the ground at %v", k, p)
+		}
+	}
+	if math.Abs(a-exp) > 0.05*exp {
+		t.Errorf("expected to keep the area %f, got %f", exp, a)
+	}
+
+	// a beam held at one end sags less with bending constraints
+	sag := func(bending bool) float64 {
+		s := m.NewParticleSystem2D(g)
+		s.Damping, s.Iterations = 2, 40
+		for i := 0; i < 8; i++ {
+			s.AddParticle(m.Vec2D{X: float64(i) * 0.25}, 0.05)
+		}
+		s.Pin(0, m.Vec2D{})
+		s.Pin(1, m.Vec2D{X: 0.25})
+		for i := 0; i+1 < 8; i++ {
+			s.Constraints = append(s.Constraints, m.NewDistanceConstraint2D(s.Particles, i, i+1, 1))
+			if bending && i+2 < 8 {
+				s.Constraints = append(s.Constraints, m.NewBendingConstraint2D(s.Particles, i, i+1, i+2, 1))
+			}
+		}
+		for i := 0; i < 300; i++ {
+			s.Step(1.0 / 60)
+		}
+
+		return -s.Particles[7].Position.Y
+	}
+	if stiff, loose := sag(true), sag(false); stiff > loose/2 {
+		t.Errorf("expected the stiff beam to hold, sags %f and %f", stiff, loose)
+	}
+
+	// wind drags a weightless particle up to its speed, friction stops a
+	// sliding one
+	s = m.NewParticleSystem2D(m.Vec2D{})
+	s.Wind, s.Drag = m.Vec2D{X: 3}, 5
+	s.AddParticle(m.Vec2D{}, 1)
+	for i := 0; i < 120; i++ {
+		s.Step(1.0 / 60)
+	}
+	if v := s.Velocity(0); math.Abs(v.X-3) > 1e-2 || v.Y != 0 {
+		t.Errorf("expected to move with the wind at 3, got %v", v)
+	}
+
+	s = m.NewParticleSystem2D(g)
+	s.Friction = 1
+	s.Colliders = append(s.Colliders, m.AABB2D{Min: m.Vec2D{X: -10, Y: -1}, Max: m.Vec2D{X: 10}})
+	s.AddParticle(m.Vec2D{}, 1)
+	s.Particles[0].Previous = m.Vec2D{X: -0.05}
+	for i := 0; i < 10; i++ {
+		s.Step(1.0 / 60)
+	}
+	if v := s.Velocity(0); math.Abs(v.X) > 1e-9 {
+		t.Errorf("expected friction to stop the particle, moving at %v", v)
+	}
+
+	if err := s.Unpin(0, 0); err != m.ErrInvalidMass {
+		t.Errorf("expected ErrInvalidMass, got %v", err)
+	}
+}
+
+// a paused frame with drag must not divide by the zero step
+func TestParticlesZeroStep(t *testing.T) {
+	s3 := m.NewParticleSystem3D(m.Vec3D{Y: -9.8})
+	s3.Drag = 0.5
+	s3.AddParticle(m.Vec3D{X: 1}, 1)
+	s3.Step(1.0 / 60)
+	p3, v3 := s3.Particles[0].Position, s3.Velocity(0)
+	s3.Step(0)
+	if s3.Particles[0].Position != p3 || s3.Velocity(0) != v3 {
+		t.Errorf("3D particle moved to %v at %v on a zero step", s3.Particles[0].Position, s3.Velocity(0))
+	}
+
+	s2 := m.NewParticleSystem2D(m.Vec2D{Y: -9.8})
+	s2.Drag = 0.5
+	s2.AddParticle(m.Vec2D{X: 1}, 1)
+	s2.Step(1.0 / 60)
+	p2, v2 := s2.Particles[0].Position, s2.Velocity(0)
+	s2.Step(0)
+	if s2.Particles[0].Position != p2 || s2.Velocity(0) != v2 {
+		t.Errorf("2D particle moved to %v at %v on a zero step", s2.Particles[0].Position, s2.Velocity(0))
+	}
+}
@@ -0,0 +1,243 @@
+package tests
+
+import (
+	m "golem"
+	"math"
+	"testing"
+)
+
+func TestCollide2D(t *testing.T) {
+	id := m.RotMat2D{}
+	id.Set(0)
+
+	// a box sunk 0.1 into a wider one touches at both bottom corners
+	box, _ := m.NewBoxShape(0.5, 0.5)
+	floor, _ := m.NewBoxShape(5, 0.5)
+	mf, ok := m.Collide2D(floor, m.Vec2D{}, id, box, m.Vec2D{Y: 0.9}, id)
+	if !ok || len(mf.Points) != 2 {
+		t.Fatalf("expected two contact points, got %v", mf)
+	}
+	if !nearVec2D(mf.Normal, m.Vec2D{Y: 1}, 1e-9) {
+		t.Errorf("expected the normal up, got %v", mf.Normal)
+	}
+	for _, p := range mf.Points {
+		if math.Abs(p.Depth-0.1) > 1e-9 || math.Abs(math.Abs(p.Point.X)-0.5) > 1e-9 || math.Abs(p.Point.Y-0.45) > 1e-9 {
+			t.Errorf("unexpected contact point %v", p)
+		}
+	}
+	if mf.Points[0].ID == mf.Points[1].ID {
+		t.Error("expected the points to have their own ids")
+	}
+
+	// swapped the normal turns around
+	if sw, _ := m.Collide2D(box, m.Vec2D{Y: 0.9}, id, floor, m.Vec2D{}, id); !nearVec2D(sw.Normal, m.Vec2D{Y: -1}, 1e-9) {
+		t.Errorf("expected the normal down, got %v", sw.Normal)
+	}
+
+	// a box standing on its corner touches at the one corner
+	rot := m.RotMat2D{}
+	rot.Set(math.Pi / 4)
+	mf, ok = m.Collide2D(floor, m.Vec2D{}, id, box, m.Vec2D{Y: 0.5 + math.Sqrt2/2 - 0.05}, rot)
+	if !ok || len(mf.Points) != 1 || math.Abs(mf.Points[0].Depth-0.05) > 1e-9 {
+		t.Errorf("expected a corner 0.05 deep, got %v", mf)
+	}
+
+	if _, ok := m.Collide2D(floor, m.Vec2D{}, id, box, m.Vec2D{Y: 1.01}, id); ok {
+		t.Error("expected no contact above the floor")
+	}
+
+	// circles against circles, faces and corners
+	ball := &m.CircleShape{Radius: 0.5}
+	mf, ok = m.Collide2D(ball, m.Vec2D{}, id, ball, m.Vec2D{X: 0.8}, id)
+	if !ok || !nearVec2D(mf.Normal, m.Vec2D{X: 1}, 1e-9) || math.Abs(mf.Points[0].Depth-0.2) > 1e-9 || !nearVec2D(mf.Points[0].Point, m.Vec2D{X: 0.4}, 1e-9) {
+		t.Errorf("unexpected circle contact %v", mf)
+	}
+
+	mf, ok = m.Collide2D(ball, m.Vec2D{X: 1, Y: 0.3}, id, floor, m.Vec2D{}, id)
+	if !ok || !nearVec2D(mf.Normal, m.Vec2D{Y: -1}, 1e-9) || math.Abs(mf.Points[0].Depth-0.7) > 1e-9 {
+		t.Errorf("unexpected circle on face contact %v", mf)
+	}
+
+	corner := m.Vec2D{X: 5.3, Y: 0.9}
+	mf, ok = m.Collide2D(box, m.Vec2D{X: 4.5}, id, ball, m.Vec2D{X: 5.3, Y: 0.9}, id)
+	if d := corner.Dist(m.Vec2D{X: 5, Y: 0.5}); !ok || math.Abs(mf.Points[0].Depth-(0.5-d)) > 1e-9 {
+		t.Errorf("unexpected circle on corner contact %v", mf)
+	}
+	if !nearVec2D(mf.Normal, m.Vec2D{X: 0.6, Y: 0.8}, 1e-9) {
+		t.Errorf("expected the normal to the corner, got %v", mf.Normal)
+	}
+
+	if _, _, err := m.NewPolygonShape([]m.Vec2D{{}, {X: 1}, {X: 2}}); err != m.ErrDegeneratePolygon {
+		t.Errorf("expected ErrDegeneratePolygon, got %v", err)
+	}
+}
+
+func addBody(w *m.World2D, kind m.BodyType2D, shape m.Shape2D, pos m.Vec2D) *m.Body2D {
+	b, _ := m.NewBody2D(kind, shape, 1)
+	b.Position = pos
+	w.AddBody(b)
+	return b
+}
+
+func TestWorld2D(t *testing.T) {
+	g := m.Vec2D{Y: -9.8}
+	ground, _ := m.NewBoxShape(10, 0.5)
+	box, _ := m.NewBoxShape(0.5, 0.5)
+
+	// a stack of boxes settles on the ground and goes to sleep, replayed it
+	// lands in the same place
+	var rest [2][]m.Vec2D
+	for run := range rest {
+		w := m.NewWorld2D(g)
+		addBody(w, m.BodyStatic, ground, m.Vec2D{Y: -0.5})
+		var stack []*m.Body2D
+		for i := 0; i < 5; i++ {
+			stack = append(stack, addBody(w, m.BodyDynamic, box, m.Vec2D{X: 0.02 * float64(i%2), Y: 0.5 + 1.05*float64(i)}))
+		}
+
+		for i := 0; i < 600; i++ {
+			w.Step(1.0 / 60)
+		}
+
+		for i, b := range stack {
+			if math.Abs(b.Position.Y-(0.5+float64(i))) > 0.05 || math.Abs(b.Angle) > 0.01 {
+				t.Errorf("box %d rests at %v turned %f", i, b.Position, b.Angle)
+			}
+			if !b.IsSleeping() {
+				t.Errorf("box %d still awake moving at %v", i, b.LinearVelocity)
+			}
+			rest[run] = append(rest[run], b.Position)
+		}
+
+		// a push wakes the box and the stack under it
+		stack[4].ApplyImpulse(m.Vec2D{X: 0.1})
+		w.Step(1.0 / 60)
+		w.Step(1.0 / 60)
+		if stack[4].IsSleeping() || stack[3].IsSleeping() {
+			t.Error("expected the push to wake the stack")
+		}
+	}
+	for i := range rest[0] {
+		if rest[0][i] != rest[1][i] {
+			t.Errorf("replay rests box %d at %v, first run at %v", i, rest[1][i], rest[0][i])
+		}
+	}
+
+	// a ball bounces back to about a quarter height at half restitution
+	w := m.NewWorld2D(g)
+	w.Iterations = 20
+	floor := addBody(w, m.BodyStatic, ground, m.Vec2D{Y: -0.5})
+	ball := addBody(w, m.BodyDynamic, &m.CircleShape{Radius: 0.25}, m.Vec2D{Y: 4.25})
+	floor.Restitution, ball.Restitution = 0.5, 0.5
+
+	top, fell := 0.0, false
+	for i := 0; i < 180; i++ {
+		w.Step(1.0 / 120)
+		if ball.LinearVelocity.Y < 0 && !fell {
+			continue
+		}
+		fell = true
+		top = math.Max(top, ball.Position.Y-0.25)
+	}
+	if math.Abs(top-1) > 0.15 {
+		t.Errorf("expected to bounce to 1, got %f", top)
+	}
+
+	// friction stops a sliding box at v^2 / 2 mu g
+	w = m.NewWorld2D(g)
+	floor = addBody(w, m.BodyStatic, ground, m.Vec2D{Y: -0.5})
+	crate := addBody(w, m.BodyDynamic, box, m.Vec2D{Y: 0.5})
+	floor.Friction, crate.Friction = 0.5, 0.5
+	crate.LinearVelocity = m.Vec2D{X: 4}
+	for i := 0; i < 240; i++ {
+		w.Step(1.0 / 120)
+	}
+	if exp := 16 / (2 * 0.5 * 9.8); math.Abs(crate.Position.X-exp) > 0.1 || math.Abs(crate.LinearVelocity.X) > 1e-6 {
+		t.Errorf("expected to stop at %f, at %v moving %v", exp, crate.Position, crate.LinearVelocity)
+	}
+
+	// a body masked out of the ground layer falls through it
+	ghost := addBody(w, m.BodyDynamic, box, m.Vec2D{X: -5, Y: 0.5})
+	floor.Category = 2
+	ghost.Mask = 1
+	for i := 0; i < 60; i++ {
+		w.Step(1.0 / 60)
+	}
+	if ghost.Position.Y > -1 {
+		t.Errorf("expected the ghost to fall through, at %v", ghost.Position)
+	}
+	if crate.Position.Y < 0.4 {
+		t.Errorf("the crate fell through to %v", crate.Position)
+	}
+
+	if err := w.RemoveBody(ghost); err != nil || len(w.Bodies()) != 2 {
+		t.Errorf("expected to remove the ghost, %v", err)
+	}
+	if err := w.RemoveBody(ghost); err != m.ErrUnknownID {
+		t.Errorf("expected ErrUnknownID, got %v", err)
+	}
+	if _, err := m.NewBody2D(m.BodyDynamic, box, 0); err != m.ErrInvalidMass {
+		t.Errorf("expected ErrInvalidMass, got %v", err)
+	}
+
+	// a triangle is moved onto its centroid, dropped with its right angle
+	// hanging over a ledge and the centroid over the ground it lands flat
+	tri, offset, err := m.NewPolygonShape([]m.Vec2D{{}, {X: 1}, {Y: 1}})
+	if err != nil || !nearVec2D(offset, m.Vec2D{X: 1.0 / 3, Y: 1.0 / 3}, 1e-12) {
+		t.Fatalf("expected the centroid as the offset, got %v %v", offset, err)
+	}
+	if mass, inertia := tri.MassData(1); math.Abs(mass-0.5) > 1e-12 || math.Abs(inertia-1.0/18) > 1e-12 {
+		t.Errorf("expected mass 1/2 and inertia 1/18 about the centroid, got %f %f", mass, inertia)
+	}
+
+	w = m.NewWorld2D(g)
+	addBody(w, m.BodyStatic, ground, m.Vec2D{X: 10.2, Y: -0.5})
+	wedge := addBody(w, m.BodyDynamic, tri, offset.AddVec(m.Vec2D{Y: 0.2}))
+	for i := 0; i < 120; i++ {
+		w.Step(1.0 / 60)
+	}
+	if math.Abs(wedge.Angle) > 1e-3 || math.Abs(wedge.AngularVelocity) > 1e-3 || !nearVec2D(wedge.Position, offset, 0.02) {
+		t.Errorf("expected the triangle to land flat, at %v turned %f", wedge.Position, wedge.Angle)
+	}
+}
+
+func TestRaycast2D(t *testing.T) {
+	w := m.NewWorld2D(m.Vec2D{})
+	box, _ := m.NewBoxShape(0.5, 0.5)
+
+	near := addBody(w, m.BodyStatic, box, m.Vec2D{X: 3})
+	far := addBody(w, m.BodyStatic, &m.CircleShape{Radius: 1}, m.Vec2D{X: 6})
+	far.Category = 2
+	near.Angle = math.Pi / 4
+
+	hit, ok := w.Raycast(m.Vec2D{}, m.Vec2D{X: 2}, 10, math.MaxUint32)
+	if !ok || hit.Body != near || math.Abs(hit.Distance-(3-math.Sqrt2/2)) > 1e-9 {
+		t.Fatalf("expected to hit the corner of the box, got %v", hit)
+	}
+	if !nearVec2D(hit.Normal, m.Vec2D{X: -math.Sqrt2 / 2, Y: -math.Sqrt2 / 2}, 1e-9) && !nearVec2D(hit.Normal, m.Vec2D{X: -math.Sqrt2 / 2, Y: math.Sqrt2 / 2}, 1e-9) {
+		t.Errorf("expected a face normal of the box, got %v", hit.Normal)
+	}
+
+	// the mask skips the box
+	hit, ok = w.Raycast(m.Vec2D{}, m.Vec2D{X: 1}, 10, 2)
+	if !ok || hit.Body != far || !nearVec2D(hit.Point, m.Vec2D{X: 5}, 1e-9) || !nearVec2D(hit.Normal, m.Vec2D{X: -1}, 1e-9) {
+		t.Errorf("expected to hit the circle at 5, got %v", hit)
+	}
+
+	if _, ok := w.Raycast(m.Vec2D{}, m.Vec2D{X: 1}, 2, math.MaxUint32); ok {
+		t.Error("expected the ray to stop short")
+	}
+	if _, ok := w.Raycast(m.Vec2D{}, m.Vec2D{Y: 1}, 10, math.MaxUint32); ok {
+		t.Error("expected the ray to miss")
+	}
+	if _, ok := w.Raycast(m.Vec2D{X: 3}, m.Vec2D{X: 1}, 10, 1); ok {
+		t.Error("expected a ray from inside to miss")
+	}
+
+	if got := w.QueryAABB(m.AABB2D{Min: m.Vec2D{X: 3.5, Y: -1}, Max: m.Vec2D{X: 5.5, Y: 1}}, math.MaxUint32); len(got) != 2 {
+		t.Errorf("expected both bodies in the box, got %d", len(got))
+	}
+	if got := w.QueryAABB(m.AABB2D{Min: m.Vec2D{X: 3.5, Y: -1}, Max: m.Vec2D{X: 5.5, Y: 1}}, 1); len(got) != 1 || got[0] != near {
+		t.Errorf("expected only the box, got %v", got)
+	}
+}